@@ -8,6 +8,7 @@ import (
 
 type Config struct {
 	Port                       string
+	ShutdownTimeoutSec         int
 	MaxConnsPerHost            int
 	MaxSetlistFMNumSearchPages int
 	MaxCreateArtists           int
@@ -15,6 +16,38 @@ type Config struct {
 	AddSetlistSleepMs          int
 	NextPageSleepMs            int
 	HttpClientTimeoutSeconds   int
+	MaxSongWorkers             int
+	MaxGlobalSongWorkers       int
+	// Playlists are created synchronously when there are no job workers
+	CreationJobWorkers      int
+	CreationJobQueueSize    int
+	CreationJobRetentionMin int
+	// Responses to requests with an Idempotency-Key are replayed during the TTL
+	IdempotencyKeyTTLMin   int
+	IdempotencyWaitSeconds int
+	// Scheduled playlists are checked periodically and refreshed once their interval elapses
+	RefreshIntervalMin      int
+	RefreshCheckIntervalSec int
+	// Scheduled refreshes are only kept in memory when empty
+	RefreshDir string
+	// Events are notified within the request when the queue size is zero
+	EventQueueSize         int
+	EventOverflowPolicy    string
+	EventObserverTimeoutMs int
+	// Comma separated topic=encoding pairs. Topics not listed use the event wrapper encoding
+	EventEncodings    string
+	CloudEventsSource string
+	// Events are stored in the outbox directory before being published, when provided
+	OutboxDir            string
+	OutboxMaxAttempts    int
+	OutboxPollIntervalMs int
+	// JSON file listing the webhooks receiving the events, if any
+	WebhooksFile        string
+	WebhookMaxAttempts  int
+	WebhookDisableAfter int
+	WebhookTimeoutMs    int
+	WebhookQueueSize    int
+	WebhookWorkers      int
 
 	SetlistfmApiKey string
 
@@ -22,13 +55,58 @@ type Config struct {
 	SpotifyClientSecret string
 	SpotifyRefreshToken string
 
-	PubsubProjectId     string
+	// Apple Music is only enabled when a team id is provided
+	AppleMusicTeamId         string
+	AppleMusicKeyId          string
+	AppleMusicPrivateKeyPath string
+	AppleMusicStorefront     string
+
+	// YouTube Music is only enabled when a client id is provided
+	YouTubeClientId     string
+	YouTubeClientSecret string
+	YouTubeRefreshToken string
+	YouTubeDailyQuota   int
+
+	// Backend publishing the events to the topics below
+	MessagingBackend string
+	// Only required by the pubsub backend
+	PubsubProjectId string
+	// Comma separated brokers, required by the kafka backend
+	KafkaBrokers          string
+	KafkaClientId         string
+	KafkaAcks             string
+	KafkaSecurityProtocol string
+	KafkaSASLMechanism    string
+	KafkaSASLUsername     string
+	KafkaSASLPassword     string
+	KafkaTLSCAFile        string
+	// Comma separated topic=path pairs, where path is the dotted path of the message field used as key
+	KafkaPartitionKeys string
+	// The file backend appends the messages of each topic to a file in the directory
+	MessagesFileDir        string
+	MessagesFileMaxSizeMB  int
+	MessagesFileMaxBackups int
+
 	CreatePlaylistTopic string
+	// Failure events are only published when a topic is provided
+	CreationFailedTopic string
+	// Update and diagnostics events are only published when a topic is provided
+	PlaylistUpdatedTopic string
+	SetlistNotFoundTopic string
+	SongNotFoundTopic    string
 }
 
+const (
+	pubsubBackend = "pubsub"
+	kafkaBackend  = "kafka"
+	fileBackend   = "file"
+	stdoutBackend = "stdout"
+)
+
 func ReadConfig() Config {
-	return Config{
+	config := Config{
 		Port:                       GetEnvWithDefaultOrFail[string]("FESTWRAP_PORT", "8080"),
+		ShutdownTimeoutSec:         GetEnvWithDefaultOrFail[int]("FESTWRAP_SHUTDOWN_TIMEOUT_S", 30),
 		MaxConnsPerHost:            GetEnvWithDefaultOrFail[int]("FESTWRAP_MAX_CONNS_PER_HOST", 10),
 		SetlistfmApiKey:            GetEnvStringOrFail("FESTWRAP_SETLISTFM_APIKEY"),
 		MaxSetlistFMNumSearchPages: GetEnvWithDefaultOrFail[int]("FESTWRAP_SETLISTFM_NUM_SEARCH_PAGES", 3),
@@ -37,12 +115,73 @@ func ReadConfig() Config {
 		AddSetlistSleepMs:          GetEnvWithDefaultOrFail[int]("FESTWRAP_ADD_SETLIST_SLEEP_MS", 550),
 		NextPageSleepMs:            GetEnvWithDefaultOrFail[int]("FESTWRAP_GET_SETLIST_NEXT_PAGE_SLEEP_MS", 550),
 		HttpClientTimeoutSeconds:   GetEnvWithDefaultOrFail[int]("FESTWRAP_HTTP_CLIENT_TIMEOUT_S", 5),
+		MaxSongWorkers:             GetEnvWithDefaultOrFail[int]("FESTWRAP_MAX_SONG_WORKERS", 5),
+		MaxGlobalSongWorkers:       GetEnvWithDefaultOrFail[int]("FESTWRAP_MAX_GLOBAL_SONG_WORKERS", 20),
+		CreationJobWorkers:         GetEnvWithDefaultOrFail[int]("FESTWRAP_CREATION_JOB_WORKERS", 0),
+		CreationJobQueueSize:       GetEnvWithDefaultOrFail[int]("FESTWRAP_CREATION_JOB_QUEUE_SIZE", 100),
+		CreationJobRetentionMin:    GetEnvWithDefaultOrFail[int]("FESTWRAP_CREATION_JOB_RETENTION_MIN", 60),
+		IdempotencyKeyTTLMin:       GetEnvWithDefaultOrFail[int]("FESTWRAP_IDEMPOTENCY_KEY_TTL_MIN", 1440),
+		IdempotencyWaitSeconds:     GetEnvWithDefaultOrFail[int]("FESTWRAP_IDEMPOTENCY_WAIT_S", 10),
+		RefreshIntervalMin:         GetEnvWithDefaultOrFail[int]("FESTWRAP_REFRESH_INTERVAL_MIN", 1440),
+		RefreshCheckIntervalSec:    GetEnvWithDefaultOrFail[int]("FESTWRAP_REFRESH_CHECK_INTERVAL_S", 60),
+		RefreshDir:                 GetEnvWithDefaultOrFail[string]("FESTWRAP_REFRESH_DIR", ""),
+		EventQueueSize:             GetEnvWithDefaultOrFail[int]("FESTWRAP_EVENT_QUEUE_SIZE", 0),
+		EventOverflowPolicy:        GetEnvWithDefaultOrFail[string]("FESTWRAP_EVENT_OVERFLOW_POLICY", "drop_oldest"),
+		EventObserverTimeoutMs:     GetEnvWithDefaultOrFail[int]("FESTWRAP_EVENT_OBSERVER_TIMEOUT_MS", 10000),
+		EventEncodings:             GetEnvWithDefaultOrFail[string]("FESTWRAP_EVENT_ENCODINGS", ""),
+		CloudEventsSource:          GetEnvWithDefaultOrFail[string]("FESTWRAP_CLOUDEVENTS_SOURCE", "/festwrap"),
+		OutboxDir:                  GetEnvWithDefaultOrFail[string]("FESTWRAP_OUTBOX_DIR", ""),
+		OutboxMaxAttempts:          GetEnvWithDefaultOrFail[int]("FESTWRAP_OUTBOX_MAX_ATTEMPTS", 10),
+		OutboxPollIntervalMs:       GetEnvWithDefaultOrFail[int]("FESTWRAP_OUTBOX_POLL_INTERVAL_MS", 1000),
+		WebhooksFile:               GetEnvWithDefaultOrFail[string]("FESTWRAP_WEBHOOKS_FILE", ""),
+		WebhookMaxAttempts:         GetEnvWithDefaultOrFail[int]("FESTWRAP_WEBHOOK_MAX_ATTEMPTS", 3),
+		WebhookDisableAfter:        GetEnvWithDefaultOrFail[int]("FESTWRAP_WEBHOOK_DISABLE_AFTER", 5),
+		WebhookTimeoutMs:           GetEnvWithDefaultOrFail[int]("FESTWRAP_WEBHOOK_TIMEOUT_MS", 5000),
+		WebhookQueueSize:           GetEnvWithDefaultOrFail[int]("FESTWRAP_WEBHOOK_QUEUE_SIZE", 100),
+		WebhookWorkers:             GetEnvWithDefaultOrFail[int]("FESTWRAP_WEBHOOK_WORKERS", 4),
 		SpotifyClientId:            GetEnvStringOrFail("SPOTIFY_CLIENT_ID"),
 		SpotifyClientSecret:        GetEnvStringOrFail("SPOTIFY_CLIENT_SECRET"),
 		SpotifyRefreshToken:        GetEnvStringOrFail("SPOTIFY_REFRESH_TOKEN"),
-		PubsubProjectId:            GetEnvStringOrFail("FESTWRAP_PUBSUB_PROJECT_ID"),
+		AppleMusicTeamId:           GetEnvWithDefaultOrFail[string]("APPLE_MUSIC_TEAM_ID", ""),
+		AppleMusicKeyId:            GetEnvWithDefaultOrFail[string]("APPLE_MUSIC_KEY_ID", ""),
+		AppleMusicPrivateKeyPath:   GetEnvWithDefaultOrFail[string]("APPLE_MUSIC_PRIVATE_KEY_PATH", ""),
+		AppleMusicStorefront:       GetEnvWithDefaultOrFail[string]("APPLE_MUSIC_STOREFRONT", "us"),
+		YouTubeClientId:            GetEnvWithDefaultOrFail[string]("YOUTUBE_CLIENT_ID", ""),
+		YouTubeClientSecret:        GetEnvWithDefaultOrFail[string]("YOUTUBE_CLIENT_SECRET", ""),
+		YouTubeRefreshToken:        GetEnvWithDefaultOrFail[string]("YOUTUBE_REFRESH_TOKEN", ""),
+		YouTubeDailyQuota:          GetEnvWithDefaultOrFail[int]("FESTWRAP_YOUTUBE_DAILY_QUOTA", 10000),
+		MessagingBackend:           GetEnvWithDefaultOrFail[string]("FESTWRAP_MESSAGING_BACKEND", pubsubBackend),
+		PubsubProjectId:            GetEnvWithDefaultOrFail[string]("FESTWRAP_PUBSUB_PROJECT_ID", ""),
+		KafkaBrokers:               GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_BROKERS", ""),
+		KafkaClientId:              GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_CLIENT_ID", "festwrap"),
+		KafkaAcks:                  GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_ACKS", "all"),
+		KafkaSecurityProtocol:      GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_SECURITY_PROTOCOL", "PLAINTEXT"),
+		KafkaSASLMechanism:         GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_SASL_MECHANISM", "PLAIN"),
+		KafkaSASLUsername:          GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_SASL_USERNAME", ""),
+		KafkaSASLPassword:          GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_SASL_PASSWORD", ""),
+		KafkaTLSCAFile:             GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_TLS_CA_FILE", ""),
+		KafkaPartitionKeys:         GetEnvWithDefaultOrFail[string]("FESTWRAP_KAFKA_PARTITION_KEYS", ""),
+		MessagesFileDir:            GetEnvWithDefaultOrFail[string]("FESTWRAP_MESSAGES_FILE_DIR", "messages"),
+		MessagesFileMaxSizeMB:      GetEnvWithDefaultOrFail[int]("FESTWRAP_MESSAGES_FILE_MAX_SIZE_MB", 10),
+		MessagesFileMaxBackups:     GetEnvWithDefaultOrFail[int]("FESTWRAP_MESSAGES_FILE_MAX_BACKUPS", 5),
 		CreatePlaylistTopic:        GetEnvStringOrFail("FESTWRAP_PUBSUB_CREATE_PLAYLIST_TOPIC"),
+		CreationFailedTopic:        GetEnvWithDefaultOrFail[string]("FESTWRAP_PUBSUB_CREATION_FAILED_TOPIC", ""),
+		PlaylistUpdatedTopic:       GetEnvWithDefaultOrFail[string]("FESTWRAP_PUBSUB_PLAYLIST_UPDATED_TOPIC", ""),
+		SetlistNotFoundTopic:       GetEnvWithDefaultOrFail[string]("FESTWRAP_PUBSUB_SETLIST_NOT_FOUND_TOPIC", ""),
+		SongNotFoundTopic:          GetEnvWithDefaultOrFail[string]("FESTWRAP_PUBSUB_SONG_NOT_FOUND_TOPIC", ""),
+	}
+
+	switch config.MessagingBackend {
+	case pubsubBackend:
+		config.PubsubProjectId = GetEnvStringOrFail("FESTWRAP_PUBSUB_PROJECT_ID")
+	case kafkaBackend:
+		config.KafkaBrokers = GetEnvStringOrFail("FESTWRAP_KAFKA_BROKERS")
+	case fileBackend, stdoutBackend:
+		// Messages are written locally, so no other service is needed
+	default:
+		log.Fatalf("Unsupported messaging backend %s", config.MessagingBackend)
 	}
+	return config
 }
 
 func GetEnvWithDefaultOrFail[T env.EnvValue](key string, defaultValue T) T {
@@ -0,0 +1,30 @@
+// Writes the JSON Schema of the published events, or checks the written ones are up to date and compatible:
+//
+//	go run ./cmd/eventschema [-dir schemas/events] [-check]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"festwrap/internal/event/schema"
+)
+
+func main() {
+	dir := flag.String("dir", "schemas/events", "directory of the event schemas")
+	check := flag.Bool("check", false, "check the schemas instead of writing them")
+	flag.Parse()
+
+	if *check {
+		if err := schema.CheckSchemas(*dir); err != nil {
+			log.Fatalf("Event schemas are not up to date: %v", err)
+		}
+		log.Printf("Event schemas in %s are up to date", *dir)
+		return
+	}
+
+	if err := schema.WriteSchemas(*dir); err != nil {
+		log.Fatalf("Could not write event schemas: %v", err)
+	}
+	log.Printf("Wrote event schemas to %s", *dir)
+}
@@ -0,0 +1,120 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	services "festwrap/cmd/services"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+
+	"github.com/gorilla/mux"
+)
+
+type AddArtistsHandler struct {
+	playlistService     services.PlaylistService
+	logger              logging.Logger
+	maxArtists          int
+	maxArtistNameLength int
+	requestDeserializer serialization.Deserializer[AddArtistsRequest]
+	responseEncoder     serialization.Encoder[CreatePlaylistResponse]
+}
+
+func NewAddArtistsHandler(
+	playlistService services.PlaylistService,
+	logger logging.Logger,
+) AddArtistsHandler {
+	requestDeserializer := serialization.NewJsonDeserializer[AddArtistsRequest]()
+	responseEncoder := serialization.NewJsonEncoder[CreatePlaylistResponse]()
+	return AddArtistsHandler{
+		playlistService:     playlistService,
+		logger:              logger,
+		maxArtists:          5,
+		maxArtistNameLength: 50,
+		requestDeserializer: &requestDeserializer,
+		responseEncoder:     &responseEncoder,
+	}
+}
+
+func (h *AddArtistsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	playlistId := mux.Vars(r)["id"]
+	if playlistId == "" {
+		h.logger.Warn("playlist id not provided when adding artists")
+		http.Error(w, "validation error: playlist id was not provided", http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("could not read add artists body from request: %v", err))
+		http.Error(w, "could not read body from request", http.StatusBadRequest)
+		return
+	}
+
+	var addArtistsRequest AddArtistsRequest
+	err = h.requestDeserializer.Deserialize(requestBody, &addArtistsRequest)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("failed to deserialize artists information: %v", err))
+		http.Error(w, "failed to read artists information", http.StatusBadRequest)
+		return
+	}
+
+	artists := addArtistsRequest.Artists
+	h.logger.Info(fmt.Sprintf("adding artists %v to playlist %s", artists, playlistId))
+	if err = validateArtists(artists, h.maxArtists, h.maxArtistNameLength); err != nil {
+		h.logger.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.playlistService.AddArtistsToPlaylist(r.Context(), playlistId, addArtistsRequest.GetArtistNames())
+	if errors.Is(err, services.ErrPlaylistNotOwned) {
+		h.logger.Warn(fmt.Sprintf("could not add artists to playlist: %v", err))
+		http.Error(w, "playlist does not belong to the current user", http.StatusForbidden)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not add artists to playlist: %v", err))
+		http.Error(w, "unexpected error, could not add artists to playlist", http.StatusInternalServerError)
+		return
+	}
+
+	statusCode := http.StatusOK
+	switch result.Status {
+	case services.Success:
+		statusCode = http.StatusOK
+	case services.PartialFailure:
+		statusCode = http.StatusMultiStatus
+	default:
+		h.logger.Warn(fmt.Sprintf("unexpected creation status %v", result.Status))
+		http.Error(w, "unexpected error, could not add artists to playlist", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(statusCode)
+
+	h.logger.Info(fmt.Sprintf("added artists %v to playlist %s", artists, playlistId))
+
+	if err = h.responseEncoder.Encode(w, NewCreatePlaylistResponse(result)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode response: %v", err))
+		http.Error(w, "unexpected error, could not encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *AddArtistsHandler) GetPlaylistService() services.PlaylistService {
+	return h.playlistService
+}
+
+func (h *AddArtistsHandler) SetPlaylistService(service services.PlaylistService) {
+	h.playlistService = service
+}
+
+func (h *AddArtistsHandler) SetMaxArtists(limit int) {
+	h.maxArtists = limit
+}
+
+func (h *AddArtistsHandler) SetMaxArtistNameLength(length int) {
+	h.maxArtistNameLength = length
+}
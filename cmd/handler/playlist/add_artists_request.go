@@ -0,0 +1,9 @@
+package playlist
+
+type AddArtistsRequest struct {
+	Artists []PlaylistArtist `json:"artists"`
+}
+
+func (r AddArtistsRequest) GetArtistNames() []string {
+	return getArtistNames(r.Artists)
+}
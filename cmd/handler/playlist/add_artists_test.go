@@ -0,0 +1,142 @@
+package playlist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	"festwrap/internal/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	addArtistsBodyString      = `{"artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}]}`
+	addEmptyArtistsBodyString = `{"artists":[]}`
+)
+
+func buildAddArtistsRequest(t *testing.T, playlistId string, requestBody []byte) *http.Request {
+	t.Helper()
+	request := httptest.NewRequest(
+		"POST",
+		fmt.Sprintf("https://example.com/playlists/%s/artists", playlistId),
+		bytes.NewBuffer(requestBody),
+	)
+	return mux.SetURLVars(request, map[string]string{"id": playlistId})
+}
+
+func buildAddArtistsServiceMock(
+	request *http.Request,
+	result services.PlaylistCreation,
+	err error,
+) *playlistmocks.PlaylistServiceMock {
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On("AddArtistsToPlaylist", request.Context(), playlistId, playlistArtists()).Return(result, err)
+	return playlistService
+}
+
+func addArtistsSetup(t *testing.T) (AddArtistsHandler, *http.Request, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	request := buildAddArtistsRequest(t, playlistId, []byte(addArtistsBodyString))
+	playlistService := buildAddArtistsServiceMock(
+		request,
+		services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success},
+		nil,
+	)
+	handler := NewAddArtistsHandler(playlistService, logging.NoopLogger{})
+	return handler, request, httptest.NewRecorder()
+}
+
+func TestAddArtistsHandlerReturnsErrorOnInvalidRequest(t *testing.T) {
+	tests := map[string]struct {
+		playlistId  string
+		requestBody string
+	}{
+		"incorrect body": {
+			playlistId:  playlistId,
+			requestBody: "`some_incorrect_body}",
+		},
+		"no artists": {
+			playlistId:  playlistId,
+			requestBody: addEmptyArtistsBodyString,
+		},
+		"missing playlist id": {
+			playlistId:  "",
+			requestBody: addArtistsBodyString,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, _, writer := addArtistsSetup(t)
+			request := buildAddArtistsRequest(t, test.playlistId, []byte(test.requestBody))
+
+			handler.ServeHTTP(writer, request)
+
+			assert.Equal(t, http.StatusBadRequest, writer.Code)
+		})
+	}
+}
+
+func TestAddArtistsHandlerCallsServiceWithExpectedArgs(t *testing.T) {
+	handler, request, writer := addArtistsSetup(t)
+
+	handler.ServeHTTP(writer, request)
+
+	playlistService := handler.GetPlaylistService().(*playlistmocks.PlaylistServiceMock)
+	playlistService.AssertExpectations(t)
+}
+
+func TestAddArtistsHandlerReturnsStatusFromResult(t *testing.T) {
+	tests := map[string]struct {
+		result         services.PlaylistCreation
+		err            error
+		expectedStatus int
+	}{
+		"success": {
+			result:         services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success},
+			expectedStatus: http.StatusOK,
+		},
+		"partial failure": {
+			result:         services.PlaylistCreation{PlaylistId: playlistId, Status: services.PartialFailure},
+			expectedStatus: http.StatusMultiStatus,
+		},
+		"playlist not owned": {
+			err:            fmt.Errorf("%w: test", services.ErrPlaylistNotOwned),
+			expectedStatus: http.StatusForbidden,
+		},
+		"service error": {
+			err:            errors.New("test service error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, request, writer := addArtistsSetup(t)
+			handler.SetPlaylistService(buildAddArtistsServiceMock(request, test.result, test.err))
+
+			handler.ServeHTTP(writer, request)
+
+			assert.Equal(t, test.expectedStatus, writer.Code)
+		})
+	}
+}
+
+func TestAddArtistsHandlerReturnsUpdatedPlaylistInfo(t *testing.T) {
+	handler, request, writer := addArtistsSetup(t)
+
+	handler.ServeHTTP(writer, request)
+
+	expectedBody := fmt.Sprintf("{\"playlist\":{\"id\":\"%s\",\"duplicatesRemoved\":0}}\n", playlistId)
+	assert.Equal(t, expectedBody, writer.Body.String())
+}
@@ -0,0 +1,20 @@
+package playlist
+
+import "fmt"
+
+func validateArtists(artists []PlaylistArtist, maxArtists int, maxArtistNameLength int) error {
+	if len(artists) == 0 || len(artists) > maxArtists {
+		return fmt.Errorf("validation error: number of artists must be between 1 and %d", maxArtists)
+	}
+
+	for _, artist := range artists {
+		if len(artist.Name) > maxArtistNameLength || len(artist.Name) == 0 {
+			return fmt.Errorf(
+				"validation error: artist name '%s' length should be in interval [1, %d]",
+				artist.Name,
+				maxArtistNameLength,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"festwrap/cmd/refresh"
+	"festwrap/internal/logging"
+
+	"github.com/gorilla/mux"
+)
+
+type CancelPlaylistRefreshHandler struct {
+	scheduler *refresh.RefreshScheduler
+	logger    logging.Logger
+}
+
+func NewCancelPlaylistRefreshHandler(
+	scheduler *refresh.RefreshScheduler,
+	logger logging.Logger,
+) CancelPlaylistRefreshHandler {
+	return CancelPlaylistRefreshHandler{scheduler: scheduler, logger: logger}
+}
+
+func (h *CancelPlaylistRefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	playlistId := mux.Vars(r)["id"]
+	err := h.scheduler.Cancel(r.Context(), playlistId)
+	if errors.Is(err, refresh.ErrRefreshNotFound) {
+		h.logger.Warn(fmt.Sprintf("could not cancel playlist refresh: %v", err))
+		http.Error(w, "playlist refresh not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not cancel playlist refresh: %v", err))
+		http.Error(w, "unexpected error, could not cancel playlist refresh", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("cancelled refresh of playlist %s", playlistId))
+	w.WriteHeader(http.StatusNoContent)
+}
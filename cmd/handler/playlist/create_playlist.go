@@ -1,22 +1,38 @@
 package playlist
 
 import (
+	"errors"
+	"festwrap/cmd/jobs"
 	services "festwrap/cmd/services"
 	"festwrap/internal/logging"
-	"festwrap/internal/playlist"
 	"festwrap/internal/serialization"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+type Provider string
+
+const (
+	SpotifyProvider      Provider = "spotify"
+	AppleMusicProvider   Provider = "apple_music"
+	YouTubeMusicProvider Provider = "youtube_music"
+)
+
 type CreatePlaylistHandler struct {
-	playlistService     services.PlaylistService
-	logger              logging.Logger
-	maxArtists          int
-	maxArtistNameLength int
-	requestDeserializer serialization.Deserializer[NewPlaylistRequest]
-	responseEncoder     serialization.Encoder[CreatePlaylistResponse]
+	playlistServices      map[Provider]services.PlaylistService
+	providerMiddlewares   providerMiddlewares
+	logger                logging.Logger
+	maxArtists            int
+	maxArtistNameLength   int
+	maxPlaylistNameLength int
+	maxDescriptionLength  int
+	requestDeserializer   serialization.Deserializer[NewPlaylistRequest]
+	responseEncoder       serialization.Encoder[CreatePlaylistResponse]
+	errorEncoder          serialization.Encoder[CreatePlaylistErrorResponse]
+	jobEncoder            serialization.Encoder[PlaylistJobResponse]
+	// Playlists are created in the background when a job queue is set
+	jobQueue *jobs.CreationJobQueue
 }
 
 func NewCreatePlaylistHandler(
@@ -25,13 +41,20 @@ func NewCreatePlaylistHandler(
 ) CreatePlaylistHandler {
 	requestDeserializer := serialization.NewJsonDeserializer[NewPlaylistRequest]()
 	responseEncoder := serialization.NewJsonEncoder[CreatePlaylistResponse]()
+	errorEncoder := serialization.NewJsonEncoder[CreatePlaylistErrorResponse]()
+	jobEncoder := serialization.NewJsonEncoder[PlaylistJobResponse]()
 	return CreatePlaylistHandler{
-		playlistService:     playlistService,
-		logger:              logger,
-		maxArtists:          5,
-		maxArtistNameLength: 50,
-		requestDeserializer: &requestDeserializer,
-		responseEncoder:     &responseEncoder,
+		playlistServices:      map[Provider]services.PlaylistService{SpotifyProvider: playlistService},
+		providerMiddlewares:   providerMiddlewares{},
+		logger:                logger,
+		maxArtists:            5,
+		maxArtistNameLength:   50,
+		maxPlaylistNameLength: 100,
+		maxDescriptionLength:  services.MaxDescriptionLength,
+		requestDeserializer:   &requestDeserializer,
+		responseEncoder:       &responseEncoder,
+		errorEncoder:          &errorEncoder,
+		jobEncoder:            &jobEncoder,
 	}
 }
 
@@ -54,33 +77,75 @@ func (h *CreatePlaylistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	artists := newPlaylistRequest.Artists
 	h.logger.Info(fmt.Sprintf("creating playlist with artists: %v", artists))
-	if len(artists) == 0 || len(artists) > h.maxArtists {
-		message := fmt.Sprintf("validation error: number of artists must be between 1 and %d", h.maxArtists)
+	err = validateNewPlaylistRequest(
+		newPlaylistRequest, h.maxArtists, h.maxArtistNameLength, h.maxPlaylistNameLength, h.maxDescriptionLength,
+	)
+	if err != nil {
+		h.logger.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	provider := newPlaylistRequest.Provider
+	if provider == "" {
+		provider = SpotifyProvider
+	}
+	playlistService, ok := h.playlistServices[provider]
+	if !ok {
+		message := fmt.Sprintf("unsupported provider %s", provider)
 		h.logger.Warn(message)
 		http.Error(w, message, http.StatusBadRequest)
 		return
 	}
 
-	for _, artist := range artists {
-		if len(artist.Name) > h.maxArtistNameLength || len(artist.Name) == 0 {
-			message := fmt.Sprintf(
-				"validation error: artist name '%s' length should be in interval [1, %d]",
-				artist,
-				h.maxArtistNameLength,
-			)
-			h.logger.Warn(message)
-			http.Error(w, message, http.StatusBadRequest)
+	// Playlists with the songs already chosen do not need to look up setlists, so they are created right away
+	inBackground := h.jobQueue != nil && !newPlaylistRequest.HasTracks()
+	if inBackground && newPlaylistRequest.RollbackOnCancel {
+		// Jobs keep running after the request is cancelled, so there is nothing to roll back on cancellation
+		message := "validation error: rollbackOnCancel is not supported when playlists are created in the background"
+		h.logger.Warn(message)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	h.providerMiddlewares.wrap(provider, func(w http.ResponseWriter, r *http.Request) {
+		if inBackground {
+			h.submitJob(w, r, playlistService, newPlaylistRequest)
 			return
 		}
-	}
+		h.createPlaylist(w, r, playlistService, newPlaylistRequest)
+	}).ServeHTTP(w, r)
+}
 
-	artistNames := newPlaylistRequest.GetArtistNames()
-	result, err := h.playlistService.CreatePlaylistWithArtists(
-		r.Context(),
-		playlist.PlaylistDetails{Name: newPlaylistRequest.Playlist.Name, Description: "", IsPublic: true},
-		artistNames,
-	)
-	if err != nil {
+func (h *CreatePlaylistHandler) createPlaylist(
+	w http.ResponseWriter,
+	r *http.Request,
+	playlistService services.PlaylistService,
+	newPlaylistRequest NewPlaylistRequest,
+) {
+	artists := newPlaylistRequest.Artists
+	var result services.PlaylistCreation
+	var err error
+	if newPlaylistRequest.HasTracks() {
+		result, err = playlistService.CreatePlaylistWithSongs(
+			r.Context(),
+			newPlaylistRequest.Playlist.GetDetails(),
+			newPlaylistRequest.GetArtistSongs(),
+			newPlaylistRequest.GetCreationOptions(),
+		)
+	} else {
+		result, err = playlistService.CreatePlaylistWithArtists(
+			r.Context(),
+			newPlaylistRequest.Playlist.GetDetails(),
+			newPlaylistRequest.GetArtistNames(),
+			newPlaylistRequest.GetCreationOptions(),
+		)
+	}
+	if creationErr, ok := services.AsCreationError(err); ok {
+		h.logger.Error(fmt.Sprintf("could not create playlist: %v", err))
+		h.writeCleanup(w, creationErr.Cleanup)
+		return
+	} else if err != nil {
 		h.logger.Error(fmt.Sprintf("could not create playlist :%v", err))
 		http.Error(w, "unexpected error, could not create playlist", http.StatusInternalServerError)
 		return
@@ -102,7 +167,7 @@ func (h *CreatePlaylistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	message := fmt.Sprintf("created playlist with id %s and artists %v", result.PlaylistId, artists)
 	h.logger.Info(message)
 
-	response := CreatePlaylistResponse{Playlist: CreatedPlaylist{Id: result.PlaylistId}}
+	response := NewCreatePlaylistResponse(result)
 	if err = h.responseEncoder.Encode(w, response); err != nil {
 		message := fmt.Sprintf("encoding error, could not encode response: %v", err)
 		h.logger.Error(message)
@@ -111,12 +176,67 @@ func (h *CreatePlaylistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (h *CreatePlaylistHandler) submitJob(
+	w http.ResponseWriter,
+	r *http.Request,
+	playlistService services.PlaylistService,
+	newPlaylistRequest NewPlaylistRequest,
+) {
+	job, err := h.jobQueue.Submit(r.Context(), jobs.CreationRequest{
+		Service:  playlistService,
+		Playlist: newPlaylistRequest.Playlist.GetDetails(),
+		Artists:  newPlaylistRequest.GetArtistNames(),
+		Options:  newPlaylistRequest.GetCreationOptions(),
+	})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		h.logger.Warn(fmt.Sprintf("could not queue playlist creation: %v", err))
+		http.Error(w, "too many playlists being created, try again later", http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not queue playlist creation: %v", err))
+		http.Error(w, "unexpected error, could not create playlist", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("queued playlist creation job %s with artists %v", job.Id, newPlaylistRequest.Artists))
+
+	w.Header().Set("Location", fmt.Sprintf("/playlists/jobs/%s", job.Id))
+	w.WriteHeader(http.StatusAccepted)
+	if err = h.jobEncoder.Encode(w, NewPlaylistJobResponse(job)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode job response: %v", err))
+	}
+}
+
+// Lets clients know whether the playlist created before failing was removed
+func (h *CreatePlaylistHandler) writeCleanup(w http.ResponseWriter, cleanup services.CleanupReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	response := NewCreatePlaylistErrorResponse("unexpected error, could not create playlist", cleanup)
+	if err := h.errorEncoder.Encode(w, response); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode cleanup response: %v", err))
+	}
+}
+
 func (h *CreatePlaylistHandler) GetPlaylistService() services.PlaylistService {
-	return h.playlistService
+	return h.playlistServices[SpotifyProvider]
 }
 
 func (h *CreatePlaylistHandler) SetPlaylistService(service services.PlaylistService) {
-	h.playlistService = service
+	h.playlistServices[SpotifyProvider] = service
+}
+
+// Sets the service used for the playlists of the given provider
+func (h *CreatePlaylistHandler) SetProviderPlaylistService(provider Provider, service services.PlaylistService) {
+	h.playlistServices[provider] = service
+}
+
+// Sets the middlewares run, in order, before creating the playlists of the given provider
+func (h *CreatePlaylistHandler) SetProviderMiddlewares(provider Provider, middlewares ...Middleware) {
+	h.providerMiddlewares[provider] = middlewares
+}
+
+func (h *CreatePlaylistHandler) SetJobQueue(queue *jobs.CreationJobQueue) {
+	h.jobQueue = queue
 }
 
 func (h *CreatePlaylistHandler) SetMaxArtists(limit int) {
@@ -1,21 +1,123 @@
 package playlist
 
+import (
+	services "festwrap/cmd/services"
+	"festwrap/internal/playlist"
+	"time"
+)
+
 type PlaylistArtist struct {
-	Name string `json:"name"`
+	Name      string     `json:"name"`
+	StartsAt  *time.Time `json:"startsAt,omitempty"`
+	Headliner bool       `json:"headliner,omitempty"`
+	// Songs chosen for the artist, such as the edited tracks of a preview, added instead of looking up its setlist
+	SetlistUrl string         `json:"setlistUrl,omitempty"`
+	Tracks     []PreviewTrack `json:"tracks,omitempty"`
+}
+
+// Tracks which were not found or were duplicated are skipped, so previews can be sent back as they are
+func (a PlaylistArtist) GetSongs() []services.AddedSong {
+	songs := []services.AddedSong{}
+	for _, track := range a.Tracks {
+		if track.Track == nil || track.Status == services.SongNotFound || track.Status == services.SongDuplicated {
+			continue
+		}
+		songs = append(songs, services.AddedSong{SetlistTitle: track.SetlistTitle, Song: *track.Track})
+	}
+	return songs
 }
 
 type NewPlaylist struct {
 	Name string `json:"name"`
+	// Generated from the artists setlists when empty
+	Description string `json:"description"`
+	// Playlists are public unless stated otherwise or collaborative, since collaborative playlists cannot be public
+	IsPublic        *bool `json:"isPublic"`
+	IsCollaborative bool  `json:"isCollaborative"`
+}
+
+func (p NewPlaylist) GetDetails() playlist.PlaylistDetails {
+	isPublic := !p.IsCollaborative
+	if p.IsPublic != nil {
+		isPublic = *p.IsPublic
+	}
+	return playlist.PlaylistDetails{
+		Name:            p.Name,
+		Description:     p.Description,
+		IsPublic:        isPublic,
+		IsCollaborative: p.IsCollaborative,
+	}
+}
+
+// Duplicated songs (same URI) are removed by default
+type PlaylistDeduplication struct {
+	Disabled bool `json:"disabled"`
+	ByIsrc   bool `json:"byIsrc"`
+	ByTitle  bool `json:"byTitle"`
+}
+
+func (d PlaylistDeduplication) GetOptions() services.DeduplicationOptions {
+	return services.DeduplicationOptions{Disabled: d.Disabled, ByIsrc: d.ByIsrc, ByTitle: d.ByTitle}
 }
 
 type NewPlaylistRequest struct {
-	Playlist NewPlaylist      `json:"playlist"`
-	Artists  []PlaylistArtist `json:"artists"`
+	Playlist      NewPlaylist           `json:"playlist"`
+	Artists       []PlaylistArtist      `json:"artists"`
+	Deduplication PlaylistDeduplication `json:"deduplication"`
+	// Songs are added artist after artist when empty
+	Ordering string `json:"ordering"`
+	// Spotify is used when empty
+	Provider Provider `json:"provider"`
+	// Removes the created playlist when the request is cancelled before finishing
+	RollbackOnCancel bool `json:"rollbackOnCancel"`
+}
+
+func (r NewPlaylistRequest) GetCreationOptions() services.CreationOptions {
+	options := services.DefaultCreationOptions()
+	options.Deduplication = r.Deduplication.GetOptions()
+	options.RollbackOnCancel = r.RollbackOnCancel
+	if r.Ordering != "" {
+		options.Ordering.Strategy = services.OrderingStrategy(r.Ordering)
+	}
+	for _, artist := range r.Artists {
+		if artist.StartsAt != nil {
+			if options.Ordering.Timetable == nil {
+				options.Ordering.Timetable = map[string]time.Time{}
+			}
+			options.Ordering.Timetable[artist.Name] = *artist.StartsAt
+		}
+		if artist.Headliner {
+			options.Ordering.Headliners = append(options.Ordering.Headliners, artist.Name)
+		}
+	}
+	return options
+}
+
+// Playlists are created with the tracks of the artists when given, instead of their latest setlists
+func (r NewPlaylistRequest) HasTracks() bool {
+	for _, artist := range r.Artists {
+		if artist.Tracks != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r NewPlaylistRequest) GetArtistSongs() []services.ArtistSongs {
+	artistSongs := make([]services.ArtistSongs, len(r.Artists))
+	for i, artist := range r.Artists {
+		artistSongs[i] = services.ArtistSongs{Name: artist.Name, SetlistUrl: artist.SetlistUrl, Songs: artist.GetSongs()}
+	}
+	return artistSongs
 }
 
 func (r NewPlaylistRequest) GetArtistNames() []string {
+	return getArtistNames(r.Artists)
+}
+
+func getArtistNames(artists []PlaylistArtist) []string {
 	var names []string
-	for _, artist := range r.Artists {
+	for _, artist := range artists {
 		names = append(names, artist.Name)
 	}
 	return names
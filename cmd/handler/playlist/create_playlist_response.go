@@ -1,9 +1,74 @@
 package playlist
 
+import (
+	services "festwrap/cmd/services"
+	"festwrap/internal/song"
+)
+
+type CreatedPlaylistTrack struct {
+	SetlistTitle string    `json:"setlistTitle"`
+	Track        song.Song `json:"track"`
+}
+
+type CreatedPlaylistArtist struct {
+	Name   string                 `json:"name"`
+	Tracks []CreatedPlaylistTrack `json:"tracks"`
+}
+
 type CreatedPlaylist struct {
-	Id string `json:"id"`
+	Id                string                  `json:"id"`
+	Artists           []CreatedPlaylistArtist `json:"artists,omitempty"`
+	DuplicatesRemoved int                     `json:"duplicatesRemoved"`
 }
 
 type CreatePlaylistResponse struct {
 	Playlist CreatedPlaylist `json:"playlist"`
 }
+
+func NewCreatePlaylistResponse(creation services.PlaylistCreation) CreatePlaylistResponse {
+	var artists []CreatedPlaylistArtist
+	for _, artist := range creation.Artists {
+		tracks := make([]CreatedPlaylistTrack, len(artist.Songs))
+		for i, addedSong := range artist.Songs {
+			tracks[i] = CreatedPlaylistTrack{SetlistTitle: addedSong.SetlistTitle, Track: addedSong.Song}
+		}
+		artists = append(artists, CreatedPlaylistArtist{Name: artist.Name, Tracks: tracks})
+	}
+	return CreatePlaylistResponse{
+		Playlist: CreatedPlaylist{
+			Id:                creation.PlaylistId,
+			Artists:           artists,
+			DuplicatesRemoved: creation.DuplicatesRemoved,
+		},
+	}
+}
+
+type PlaylistCleanup struct {
+	PlaylistId      string   `json:"playlistId"`
+	PlaylistDeleted bool     `json:"playlistDeleted"`
+	SongsRemoved    int      `json:"songsRemoved"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// Returned when the playlist was created but the creation failed afterwards, describing what was undone
+type CreatePlaylistErrorResponse struct {
+	Error   string          `json:"error"`
+	Cleanup PlaylistCleanup `json:"cleanup"`
+}
+
+func NewCreatePlaylistErrorResponse(message string, cleanup services.CleanupReport) CreatePlaylistErrorResponse {
+	return CreatePlaylistErrorResponse{Error: message, Cleanup: newPlaylistCleanup(cleanup)}
+}
+
+func newPlaylistCleanup(cleanup services.CleanupReport) PlaylistCleanup {
+	var cleanupErrors []string
+	for _, err := range cleanup.Errors {
+		cleanupErrors = append(cleanupErrors, err.Error())
+	}
+	return PlaylistCleanup{
+		PlaylistId:      cleanup.PlaylistId,
+		PlaylistDeleted: cleanup.PlaylistDeleted,
+		SongsRemoved:    cleanup.SongsRemoved,
+		Errors:          cleanupErrors,
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	services "festwrap/cmd/services"
 	playlistmocks "festwrap/cmd/services/mocks"
 	"festwrap/internal/logging"
 	"festwrap/internal/playlist"
+	"festwrap/internal/song"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
@@ -50,6 +54,7 @@ func buildPlaylistServiceMock(
 		ctx,
 		playlist.PlaylistDetails{Name: playlistName, Description: "", IsPublic: true},
 		playlistArtists(),
+		services.DefaultCreationOptions(),
 	).Return(
 		result,
 		err,
@@ -109,6 +114,25 @@ func TestCreatePlaylistHandlerReturnsErrorOnInvalidRequest(t *testing.T) {
 			maxArtists:          5,
 			maxArtistNameLength: 50,
 		},
+		"empty playlist name": {
+			requestBody:         `{"playlist": {"name": ""}, "artists":[{"name":"Comeback Kid"}]}`,
+			maxArtists:          5,
+			maxArtistNameLength: 50,
+		},
+		"description exceeds limit": {
+			requestBody: fmt.Sprintf(
+				`{"playlist": {"name": "my playlist", "description": "%s"}, "artists":[{"name":"Comeback Kid"}]}`,
+				strings.Repeat("a", 301),
+			),
+			maxArtists:          5,
+			maxArtistNameLength: 50,
+		},
+		"public collaborative playlist": {
+			requestBody: `{"playlist": {"name": "my playlist", "isPublic": true, "isCollaborative": true},` +
+				`"artists":[{"name":"Comeback Kid"}]}`,
+			maxArtists:          5,
+			maxArtistNameLength: 50,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -171,12 +195,359 @@ func TestCreatePlaylistHandlerReturnsInternalErrorOnServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
 }
 
+func TestCreatePlaylistHandlerReturnsCleanupOnCreationError(t *testing.T) {
+	handler, request, writer := setup(t)
+	creationErr := &services.CreationError{
+		Err: errors.New("test service error"),
+		Cleanup: services.CleanupReport{
+			PlaylistId: playlistId,
+			Errors:     []error{errors.New("deleting playlists is not supported")},
+		},
+	}
+	handler.SetPlaylistService(buildPlaylistServiceMock(request.Context(), services.PlaylistCreation{}, creationErr))
+
+	handler.ServeHTTP(writer, request)
+
+	expectedBody := `{
+		"error": "unexpected error, could not create playlist",
+		"cleanup": {
+			"playlistId": "someId",
+			"playlistDeleted": false,
+			"songsRemoved": 0,
+			"errors": ["deleting playlists is not supported"]
+		}
+	}`
+	assert.Equal(t, http.StatusInternalServerError, writer.Code)
+	assert.JSONEq(t, expectedBody, writer.Body.String())
+}
+
+func TestCreatePlaylistHandlerPassesRollbackOption(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}],`+
+			`"rollbackOnCancel": true}`,
+	))
+	options := services.DefaultCreationOptions()
+	options.RollbackOnCancel = true
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists", request.Context(), mock.Anything, playlistArtists(), options,
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	playlistService.AssertExpectations(t)
+}
+
 func TestCreatePlaylistHandlerReturnsCreatedPlaylistInfo(t *testing.T) {
 	handler, request, writer := setup(t)
 
 	handler.ServeHTTP(writer, request)
 
 	assert.Equal(t, http.StatusCreated, writer.Code)
-	expectedBody := fmt.Sprintf("{\"playlist\":{\"id\":\"%s\"}}\n", playlistId)
+	expectedBody := fmt.Sprintf("{\"playlist\":{\"id\":\"%s\",\"duplicatesRemoved\":0}}\n", playlistId)
+	assert.Equal(t, expectedBody, writer.Body.String())
+}
+
+func TestCreatePlaylistHandlerReturnsResolvedTracks(t *testing.T) {
+	handler, request, writer := setup(t)
+	creation := services.PlaylistCreation{
+		PlaylistId: playlistId,
+		Status:     services.Success,
+		Artists: []services.ArtistCreation{
+			{
+				Name: "Comeback Kid",
+				Songs: []services.AddedSong{
+					{
+						SetlistTitle: "Wake the Dead",
+						Song:         song.Song{Id: "someTrack", Uri: "spotify:track:someTrack", Name: "Wake the Dead"},
+					},
+				},
+			},
+		},
+	}
+	handler.SetPlaylistService(buildPlaylistServiceMock(request.Context(), creation, nil))
+
+	handler.ServeHTTP(writer, request)
+
+	expectedBody := fmt.Sprintf(
+		`{"playlist":{"id":"%s","artists":[{"name":"Comeback Kid","tracks":[{"setlistTitle":"Wake the Dead",`+
+			`"track":{"id":"someTrack","uri":"spotify:track:someTrack","name":"Wake the Dead","explicit":false}}]}],`+
+			`"duplicatesRemoved":0}}`+"\n",
+		playlistId,
+	)
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	assert.Equal(t, expectedBody, writer.Body.String())
+}
+
+func TestCreatePlaylistHandlerPassesDeduplicationOptions(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}],`+
+			`"deduplication": {"byIsrc": true, "byTitle": true}}`,
+	))
+	options := services.DefaultCreationOptions()
+	options.Deduplication = services.DeduplicationOptions{ByIsrc: true, ByTitle: true}
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists",
+		request.Context(),
+		playlist.PlaylistDetails{Name: playlistName, Description: "", IsPublic: true},
+		playlistArtists(),
+		options,
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success, DuplicatesRemoved: 2}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	playlistService.AssertExpectations(t)
+	expectedBody := fmt.Sprintf("{\"playlist\":{\"id\":\"%s\",\"duplicatesRemoved\":2}}\n", playlistId)
 	assert.Equal(t, expectedBody, writer.Body.String())
 }
+
+func TestCreatePlaylistHandlerPassesOrderingOptions(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[`+
+			`{"name":"Comeback Kid", "startsAt": "2025-06-20T22:00:00Z", "headliner": true},`+
+			`{"name":"Municipal Waste", "startsAt": "2025-06-20T20:00:00Z"}], "ordering": "timetable"}`,
+	))
+	options := services.DefaultCreationOptions()
+	options.Ordering = services.OrderingOptions{
+		Strategy: services.TimetableOrdering,
+		Timetable: map[string]time.Time{
+			"Comeback Kid":    time.Date(2025, 6, 20, 22, 0, 0, 0, time.UTC),
+			"Municipal Waste": time.Date(2025, 6, 20, 20, 0, 0, 0, time.UTC),
+		},
+		Headliners: []string{"Comeback Kid"},
+	}
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists",
+		request.Context(),
+		playlist.PlaylistDetails{Name: playlistName, Description: "", IsPublic: true},
+		playlistArtists(),
+		options,
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	playlistService.AssertExpectations(t)
+}
+
+func TestCreatePlaylistHandlerReturnsErrorOnInvalidOrdering(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}], "ordering": "alphabetical"}`,
+	))
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Contains(t, writer.Body.String(), "validation error: invalid ordering strategy alphabetical")
+}
+
+func TestCreatePlaylistHandlerCreatesPrivateCollaborativePlaylistsByDefault(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist", "isCollaborative": true},`+
+			`"artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}]}`,
+	))
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists",
+		request.Context(),
+		playlist.PlaylistDetails{Name: playlistName, IsPublic: false, IsCollaborative: true},
+		playlistArtists(),
+		services.DefaultCreationOptions(),
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	playlistService.AssertExpectations(t)
+}
+
+func TestCreatePlaylistHandlerPassesPlaylistDetails(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist", "description": "Festival warm up", "isPublic": false,`+
+			`"isCollaborative": true}, "artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}]}`,
+	))
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists",
+		request.Context(),
+		playlist.PlaylistDetails{
+			Name: playlistName, Description: "Festival warm up", IsPublic: false, IsCollaborative: true,
+		},
+		playlistArtists(),
+		services.DefaultCreationOptions(),
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	playlistService.AssertExpectations(t)
+}
+
+func TestCreatePlaylistHandlerUsesServiceOfRequestedProvider(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}],`+
+			`"provider": "apple_music"}`,
+	))
+	appleMusicService := buildPlaylistServiceMock(
+		request.Context(),
+		services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success},
+		nil,
+	)
+	handler.SetProviderPlaylistService(AppleMusicProvider, appleMusicService)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	appleMusicService.AssertExpectations(t)
+	spotifyService := handler.GetPlaylistService().(*playlistmocks.PlaylistServiceMock)
+	spotifyService.AssertNotCalled(
+		t, "CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	)
+}
+
+func TestCreatePlaylistHandlerRunsMiddlewaresOfRequestedProviderOnly(t *testing.T) {
+	tests := map[string]struct {
+		provider       string
+		expectedCalled bool
+	}{
+		"requested provider": {provider: "apple_music", expectedCalled: true},
+		"other provider":     {provider: "spotify", expectedCalled: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, _, writer := setup(t)
+			request := buildRequest(t, []byte(fmt.Sprintf(
+				`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}],`+
+					`"provider": "%s"}`,
+				test.provider,
+			)))
+			appleMusicService := &playlistmocks.PlaylistServiceMock{}
+			appleMusicService.On(
+				"CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+			handler.SetProviderPlaylistService(AppleMusicProvider, appleMusicService)
+			handler.SetPlaylistService(appleMusicService)
+			called := false
+			handler.SetProviderMiddlewares(AppleMusicProvider, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					next.ServeHTTP(w, r)
+				})
+			})
+
+			handler.ServeHTTP(writer, request)
+
+			assert.Equal(t, http.StatusCreated, writer.Code)
+			assert.Equal(t, test.expectedCalled, called)
+		})
+	}
+}
+
+func TestCreatePlaylistHandlerReturnsMiddlewareErrors(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}], "provider": "apple_music"}`,
+	))
+	appleMusicService := &playlistmocks.PlaylistServiceMock{}
+	handler.SetProviderPlaylistService(AppleMusicProvider, appleMusicService)
+	handler.SetProviderMiddlewares(AppleMusicProvider, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Unexpected error", http.StatusInternalServerError)
+		})
+	})
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusInternalServerError, writer.Code)
+	appleMusicService.AssertNotCalled(
+		t, "CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	)
+}
+
+func TestCreatePlaylistHandlerCreatesPlaylistWithPreviewTracks(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(`{
+		"playlist": {"name": "my playlist"},
+		"artists": [{
+			"name": "Comeback Kid",
+			"setlistUrl": "https://comeback_kid",
+			"tracks": [
+				{"setlistTitle": "Wake the Dead", "status": "matched", "track": {"uri": "spotify:track:1"}},
+				{"setlistTitle": "G.M. Vincent & I", "status": "not_found", "reason": "not found"},
+				{"setlistTitle": "Wake the Dead", "status": "duplicated", "track": {"uri": "spotify:track:1"}},
+				{"setlistTitle": "False Idols Fall", "status": "matched", "track": {"uri": "spotify:track:2"}}
+			]
+		}]
+	}`))
+	expectedArtists := []services.ArtistSongs{
+		{
+			Name:       "Comeback Kid",
+			SetlistUrl: "https://comeback_kid",
+			Songs: []services.AddedSong{
+				{SetlistTitle: "Wake the Dead", Song: song.NewSong("spotify:track:1")},
+				{SetlistTitle: "False Idols Fall", Song: song.NewSong("spotify:track:2")},
+			},
+		},
+	}
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithSongs",
+		request.Context(),
+		playlist.PlaylistDetails{Name: playlistName, IsPublic: true},
+		expectedArtists,
+		services.DefaultCreationOptions(),
+	).Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler.SetPlaylistService(playlistService)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	playlistService.AssertExpectations(t)
+}
+
+func TestCreatePlaylistHandlerReturnsErrorOnInvalidTracks(t *testing.T) {
+	tests := map[string]string{
+		"tracks missing for some artist": `{"playlist": {"name": "my playlist"}, "artists": [` +
+			`{"name": "Comeback Kid", "tracks": [{"status": "matched", "track": {"uri": "spotify:track:1"}}]},` +
+			`{"name": "Municipal Waste"}]}`,
+		"no tracks": `{"playlist": {"name": "my playlist"}, "artists": [{"name": "Comeback Kid", "tracks": []}]}`,
+		"track without uri": `{"playlist": {"name": "my playlist"}, "artists": [` +
+			`{"name": "Comeback Kid", "tracks": [{"status": "matched", "track": {"name": "Wake the Dead"}}]}]}`,
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, _, writer := setup(t)
+
+			handler.ServeHTTP(writer, buildRequest(t, []byte(body)))
+
+			assert.Equal(t, http.StatusBadRequest, writer.Code)
+		})
+	}
+}
+
+func TestCreatePlaylistHandlerReturnsErrorOnUnsupportedProvider(t *testing.T) {
+	handler, _, writer := setup(t)
+	request := buildRequest(t, []byte(
+		`{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}], "provider": "apple_music"}`,
+	))
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
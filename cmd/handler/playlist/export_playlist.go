@@ -0,0 +1,217 @@
+package playlist
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	services "festwrap/cmd/services"
+	"festwrap/internal/logging"
+	"festwrap/internal/playlist/export"
+	"festwrap/internal/serialization"
+	"festwrap/internal/song"
+)
+
+// Resolves the setlists like a playlist creation, but returns the playlist as a file instead of storing it
+type ExportPlaylistHandler struct {
+	playlistService       services.PlaylistService
+	repository            *export.ExportPlaylistRepository
+	logger                logging.Logger
+	maxArtists            int
+	maxArtistNameLength   int
+	maxPlaylistNameLength int
+	maxDescriptionLength  int
+	requestDeserializer   serialization.Deserializer[NewPlaylistRequest]
+}
+
+// The playlist service must store its playlists in the given repository
+func NewExportPlaylistHandler(
+	playlistService services.PlaylistService,
+	repository *export.ExportPlaylistRepository,
+	logger logging.Logger,
+) ExportPlaylistHandler {
+	requestDeserializer := serialization.NewJsonDeserializer[NewPlaylistRequest]()
+	return ExportPlaylistHandler{
+		playlistService:       playlistService,
+		repository:            repository,
+		logger:                logger,
+		maxArtists:            5,
+		maxArtistNameLength:   50,
+		maxPlaylistNameLength: 100,
+		maxDescriptionLength:  services.MaxDescriptionLength,
+		requestDeserializer:   &requestDeserializer,
+	}
+}
+
+func (h *ExportPlaylistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := export.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = export.M3U8Format
+	}
+	if !export.IsValidFormat(format) {
+		message := fmt.Sprintf("unsupported export format %s", format)
+		h.logger.Warn(message)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("could not read export playlist body from request: %v", err))
+		http.Error(w, "could not read body from request", http.StatusBadRequest)
+		return
+	}
+
+	var newPlaylistRequest NewPlaylistRequest
+	err = h.requestDeserializer.Deserialize(requestBody, &newPlaylistRequest)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("failed to deserialize playlist information: %v", err))
+		http.Error(w, "failed to read playlist information", http.StatusBadRequest)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("exporting playlist with artists: %v", newPlaylistRequest.Artists))
+	err = validateNewPlaylistRequest(
+		newPlaylistRequest, h.maxArtists, h.maxArtistNameLength, h.maxPlaylistNameLength, h.maxDescriptionLength,
+	)
+	if err != nil {
+		h.logger.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Exported songs are always resolved in Spotify
+	if provider := newPlaylistRequest.Provider; provider != "" && provider != SpotifyProvider {
+		message := fmt.Sprintf("unsupported provider %s for exports", provider)
+		h.logger.Warn(message)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.playlistService.CreatePlaylistWithArtists(
+		r.Context(),
+		newPlaylistRequest.Playlist.GetDetails(),
+		newPlaylistRequest.GetArtistNames(),
+		newPlaylistRequest.GetCreationOptions(),
+	)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("could not export playlist: %v", err))
+		http.Error(w, "unexpected error, could not export playlist", http.StatusInternalServerError)
+		return
+	}
+	defer h.repository.DeletePlaylist(r.Context(), result.PlaylistId)
+
+	exported, err := h.buildExportedPlaylist(r, result)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("could not read exported playlist %s: %v", result.PlaylistId, err))
+		http.Error(w, "unexpected error, could not export playlist", http.StatusInternalServerError)
+		return
+	}
+
+	var file bytes.Buffer
+	if err = export.Write(&file, format, exported); err != nil {
+		h.logger.Error(fmt.Sprintf("could not write playlist %s as %s: %v", result.PlaylistId, format, err))
+		http.Error(w, "unexpected error, could not export playlist", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("exported playlist %s as %s", result.PlaylistId, format))
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s.%s"`, exportFileName(exported.Name), format.Extension()),
+	)
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(file.Bytes()); err != nil {
+		h.logger.Error(fmt.Sprintf("could not write exported playlist %s: %v", result.PlaylistId, err))
+	}
+}
+
+func (h *ExportPlaylistHandler) buildExportedPlaylist(
+	r *http.Request,
+	result services.PlaylistCreation,
+) (export.ExportedPlaylist, error) {
+	exportedPlaylist, err := h.repository.GetPlaylist(r.Context(), result.PlaylistId)
+	if err != nil {
+		return export.ExportedPlaylist{}, err
+	}
+
+	songs, err := h.repository.GetSongs(r.Context(), result.PlaylistId)
+	if err != nil {
+		return export.ExportedPlaylist{}, err
+	}
+
+	return export.ExportedPlaylist{
+		Name:        exportedPlaylist.Name,
+		Description: exportedPlaylist.Description,
+		Entries:     buildExportEntries(songs, result.Artists),
+	}, nil
+}
+
+// Entries follow the playlist order, followed by the setlist songs that could not be resolved
+func buildExportEntries(songs []song.Song, artists []services.ArtistCreation) []export.Entry {
+	resolvedEntries := map[string]export.Entry{}
+	for _, artist := range artists {
+		for _, addedSong := range artist.Songs {
+			resolvedEntries[addedSong.Song.Uri] = export.Entry{
+				Artist:     artist.Name,
+				Title:      addedSong.SetlistTitle,
+				SetlistUrl: artist.SetlistUrl,
+				Uri:        addedSong.Song.Uri,
+				DurationMs: addedSong.Song.DurationMs,
+			}
+		}
+	}
+
+	entries := []export.Entry{}
+	for _, playlistSong := range songs {
+		if entry, ok := resolvedEntries[playlistSong.Uri]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	for _, artist := range artists {
+		for _, unmatchedSong := range artist.UnmatchedSongs {
+			entries = append(
+				entries,
+				export.Entry{Artist: artist.Name, Title: unmatchedSong.SetlistTitle, SetlistUrl: artist.SetlistUrl},
+			)
+		}
+	}
+	return entries
+}
+
+// Keeps file names portable by replacing anything other than ASCII letters, digits, dashes and underscores
+func exportFileName(name string) string {
+	fileName := strings.Map(func(r rune) rune {
+		isAlphanumeric := r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		if isAlphanumeric || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if fileName == "" {
+		return "playlist"
+	}
+	return fileName
+}
+
+func (h *ExportPlaylistHandler) GetPlaylistService() services.PlaylistService {
+	return h.playlistService
+}
+
+func (h *ExportPlaylistHandler) SetPlaylistService(service services.PlaylistService) {
+	h.playlistService = service
+}
+
+func (h *ExportPlaylistHandler) SetMaxArtists(limit int) {
+	h.maxArtists = limit
+}
+
+func (h *ExportPlaylistHandler) SetMaxArtistNameLength(length int) {
+	h.maxArtistNameLength = length
+}
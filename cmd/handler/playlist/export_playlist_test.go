@@ -0,0 +1,183 @@
+package playlist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	"festwrap/internal/logging"
+	"festwrap/internal/playlist"
+	"festwrap/internal/playlist/export"
+	"festwrap/internal/song"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func buildExportRequest(format string) *http.Request {
+	return httptest.NewRequest(
+		http.MethodPost, "https://example.com/playlists/export?format="+format, bytes.NewBufferString(requestBodyString),
+	)
+}
+
+func exportedSongs() []song.Song {
+	return []song.Song{
+		{Uri: "spotify:track:2", DurationMs: 180000},
+		{Uri: "spotify:track:1", DurationMs: 201000},
+	}
+}
+
+func exportedArtistCreations() []services.ArtistCreation {
+	songs := exportedSongs()
+	return []services.ArtistCreation{
+		{
+			Name:       "Comeback Kid",
+			SetlistUrl: "https://comeback_kid",
+			Songs:      []services.AddedSong{{SetlistTitle: "Wake the Dead", Song: songs[1]}},
+			UnmatchedSongs: []services.UnmatchedSong{
+				{SetlistTitle: "G.M. Vincent & I", Err: errors.New("song not found")},
+			},
+		},
+		{
+			Name:       "Municipal Waste",
+			SetlistUrl: "https://municipal_waste",
+			Songs:      []services.AddedSong{{SetlistTitle: "Born to Party", Song: songs[0]}},
+		},
+	}
+}
+
+// Mimics the playlist service by storing the songs in the repository
+func setupExport(t *testing.T) (ExportPlaylistHandler, *export.ExportPlaylistRepository, string) {
+	t.Helper()
+
+	repository := export.NewExportPlaylistRepository()
+	details := playlist.PlaylistDetails{Name: playlistName, Description: "Some description", IsPublic: true}
+	exportedPlaylistId, _ := repository.CreatePlaylist(context.Background(), details)
+	_, _ = repository.AddSongs(context.Background(), exportedPlaylistId, exportedSongs())
+
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists", mock.Anything, mock.Anything, playlistArtists(), services.DefaultCreationOptions(),
+	).Return(
+		services.PlaylistCreation{
+			PlaylistId: exportedPlaylistId,
+			Status:     services.PartialFailure,
+			Artists:    exportedArtistCreations(),
+		},
+		nil,
+	)
+
+	handler := NewExportPlaylistHandler(playlistService, repository, logging.NoopLogger{})
+	return handler, repository, exportedPlaylistId
+}
+
+func TestExportPlaylistHandlerWritesPlaylistInOrder(t *testing.T) {
+	handler, _, _ := setupExport(t)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildExportRequest("csv"))
+
+	expected := "artist,title,setlist_url,spotify_uri\n" +
+		"Municipal Waste,Born to Party,https://municipal_waste,spotify:track:2\n" +
+		"Comeback Kid,Wake the Dead,https://comeback_kid,spotify:track:1\n" +
+		"Comeback Kid,G.M. Vincent & I,https://comeback_kid,\n"
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, expected, writer.Body.String())
+}
+
+func TestExportPlaylistHandlerSetsFileHeaders(t *testing.T) {
+	tests := map[string]struct {
+		format              string
+		expectedContentType string
+		expectedFileName    string
+	}{
+		"default format": {
+			format:              "",
+			expectedContentType: "audio/x-mpegurl; charset=utf-8",
+			expectedFileName:    `attachment; filename="my_playlist.m3u8"`,
+		},
+		"xspf": {
+			format:              "xspf",
+			expectedContentType: "application/xspf+xml; charset=utf-8",
+			expectedFileName:    `attachment; filename="my_playlist.xspf"`,
+		},
+		"jspf": {
+			format:              "jspf",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedFileName:    `attachment; filename="my_playlist.jspf"`,
+		},
+		"csv": {
+			format:              "csv",
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedFileName:    `attachment; filename="my_playlist.csv"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, _, _ := setupExport(t)
+			writer := httptest.NewRecorder()
+
+			handler.ServeHTTP(writer, buildExportRequest(test.format))
+
+			assert.Equal(t, http.StatusOK, writer.Code)
+			assert.Equal(t, test.expectedContentType, writer.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedFileName, writer.Header().Get("Content-Disposition"))
+		})
+	}
+}
+
+func TestExportPlaylistHandlerReleasesExportedPlaylist(t *testing.T) {
+	handler, repository, exportedPlaylistId := setupExport(t)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildExportRequest("m3u8"))
+
+	_, err := repository.GetPlaylist(context.Background(), exportedPlaylistId)
+	assert.NotNil(t, err)
+}
+
+func TestExportPlaylistHandlerReturnsBadRequestOnUnsupportedFormat(t *testing.T) {
+	handler, _, _ := setupExport(t)
+	playlistService := handler.GetPlaylistService().(*playlistmocks.PlaylistServiceMock)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildExportRequest("pls"))
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	playlistService.AssertNotCalled(
+		t, "CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	)
+}
+
+func TestExportPlaylistHandlerReturnsBadRequestOnUnsupportedProvider(t *testing.T) {
+	handler, _, _ := setupExport(t)
+	writer := httptest.NewRecorder()
+	body := `{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}], "provider": "apple_music"}`
+	request := httptest.NewRequest(
+		http.MethodPost, "https://example.com/playlists/export?format=csv", bytes.NewBufferString(body),
+	)
+
+	handler.ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestExportPlaylistHandlerReturnsErrorOnServiceError(t *testing.T) {
+	handler, _, _ := setupExport(t)
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	).Return(services.PlaylistCreation{}, errors.New("test error"))
+	handler.SetPlaylistService(playlistService)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildExportRequest("csv"))
+
+	assert.Equal(t, http.StatusInternalServerError, writer.Code)
+}
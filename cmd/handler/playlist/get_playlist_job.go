@@ -0,0 +1,49 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"festwrap/cmd/jobs"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+
+	"github.com/gorilla/mux"
+)
+
+type GetPlaylistJobHandler struct {
+	jobQueue        *jobs.CreationJobQueue
+	logger          logging.Logger
+	responseEncoder serialization.Encoder[PlaylistJobResponse]
+}
+
+func NewGetPlaylistJobHandler(jobQueue *jobs.CreationJobQueue, logger logging.Logger) GetPlaylistJobHandler {
+	responseEncoder := serialization.NewJsonEncoder[PlaylistJobResponse]()
+	return GetPlaylistJobHandler{jobQueue: jobQueue, logger: logger, responseEncoder: &responseEncoder}
+}
+
+func (h *GetPlaylistJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jobId := mux.Vars(r)["id"]
+	if jobId == "" {
+		h.logger.Warn("job id not provided when getting playlist job")
+		http.Error(w, "validation error: job id was not provided", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobQueue.GetJob(r.Context(), jobId)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		h.logger.Warn(fmt.Sprintf("could not get playlist job: %v", err))
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not get playlist job: %v", err))
+		http.Error(w, "unexpected error, could not get job", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err = h.responseEncoder.Encode(w, NewPlaylistJobResponse(job)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode job %s: %v", jobId, err))
+	}
+}
@@ -0,0 +1,46 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"festwrap/cmd/refresh"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+
+	"github.com/gorilla/mux"
+)
+
+type GetPlaylistRefreshHandler struct {
+	scheduler       *refresh.RefreshScheduler
+	logger          logging.Logger
+	responseEncoder serialization.Encoder[PlaylistRefreshResponse]
+}
+
+func NewGetPlaylistRefreshHandler(
+	scheduler *refresh.RefreshScheduler,
+	logger logging.Logger,
+) GetPlaylistRefreshHandler {
+	responseEncoder := serialization.NewJsonEncoder[PlaylistRefreshResponse]()
+	return GetPlaylistRefreshHandler{scheduler: scheduler, logger: logger, responseEncoder: &responseEncoder}
+}
+
+func (h *GetPlaylistRefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	playlistId := mux.Vars(r)["id"]
+	scheduled, err := h.scheduler.GetRefresh(r.Context(), playlistId)
+	if errors.Is(err, refresh.ErrRefreshNotFound) {
+		h.logger.Warn(fmt.Sprintf("could not get playlist refresh: %v", err))
+		http.Error(w, "playlist refresh not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not get playlist refresh: %v", err))
+		http.Error(w, "unexpected error, could not get playlist refresh", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err = h.responseEncoder.Encode(w, NewPlaylistRefreshResponse(scheduled)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode refresh response: %v", err))
+	}
+}
@@ -0,0 +1,95 @@
+package playlist
+
+import (
+	"time"
+
+	"festwrap/cmd/jobs"
+	services "festwrap/cmd/services"
+)
+
+type PlaylistJobArtist struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	SetlistUrl   string `json:"setlistUrl,omitempty"`
+	TotalSongs   int    `json:"totalSongs"`
+	MatchedSongs int    `json:"matchedSongs"`
+	Error        string `json:"error,omitempty"`
+}
+
+type PlaylistJobResponse struct {
+	Id         string              `json:"id"`
+	Status     string              `json:"status"`
+	Artists    []PlaylistJobArtist `json:"artists"`
+	Playlist   *CreatedPlaylist    `json:"playlist,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	Cleanup    *PlaylistCleanup    `json:"cleanup,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	FinishedAt *time.Time          `json:"finishedAt,omitempty"`
+}
+
+func NewPlaylistJobResponse(job jobs.CreationJob) PlaylistJobResponse {
+	artists := make([]PlaylistJobArtist, len(job.Artists))
+	for i, artist := range job.Artists {
+		artists[i] = PlaylistJobArtist{
+			Name:         artist.Name,
+			Status:       string(artist.Status),
+			SetlistUrl:   artist.SetlistUrl,
+			TotalSongs:   artist.TotalSongs,
+			MatchedSongs: artist.MatchedSongs,
+		}
+		if artist.Err != nil {
+			artists[i].Error = artist.Err.Error()
+		}
+	}
+
+	response := PlaylistJobResponse{
+		Id:        job.Id,
+		Status:    string(job.Status),
+		Artists:   artists,
+		CreatedAt: job.CreatedAt,
+	}
+	if !job.IsFinished() {
+		return response
+	}
+
+	finishedAt := job.FinishedAt
+	response.FinishedAt = &finishedAt
+	if creationErr, ok := services.AsCreationError(job.Err); ok {
+		response.Error = "unexpected error, could not create playlist"
+		cleanup := newPlaylistCleanup(creationErr.Cleanup)
+		response.Cleanup = &cleanup
+	} else if job.Err != nil {
+		response.Error = "unexpected error, could not create playlist"
+	} else {
+		createdPlaylist := NewCreatePlaylistResponse(job.Result).Playlist
+		response.Playlist = &createdPlaylist
+	}
+	return response
+}
+
+type ProgressEventResponse struct {
+	ArtistIndex  int    `json:"artistIndex"`
+	Artist       string `json:"artist"`
+	SetlistUrl   string `json:"setlistUrl,omitempty"`
+	TotalSongs   int    `json:"totalSongs"`
+	MatchedSongs int    `json:"matchedSongs"`
+	SongTitle    string `json:"songTitle,omitempty"`
+	SongStatus   string `json:"songStatus,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func NewProgressEventResponse(progress services.ProgressEvent) ProgressEventResponse {
+	response := ProgressEventResponse{
+		ArtistIndex:  progress.ArtistIndex,
+		Artist:       progress.Artist,
+		SetlistUrl:   progress.SetlistUrl,
+		TotalSongs:   progress.TotalSongs,
+		MatchedSongs: progress.MatchedSongs,
+		SongTitle:    progress.SongTitle,
+		SongStatus:   string(progress.SongStatus),
+	}
+	if progress.Err != nil {
+		response.Error = progress.Err.Error()
+	}
+	return response
+}
@@ -0,0 +1,189 @@
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"festwrap/cmd/jobs"
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const jobUserId = "some_user"
+
+func withUserId(request *http.Request, userId string) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), types.ContextKey("user_id"), userId))
+}
+
+func buildGetJobRequest(jobId string, userId string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/playlists/jobs/"+jobId, nil)
+	return withUserId(mux.SetURLVars(request, map[string]string{"id": jobId}), userId)
+}
+
+func asyncSetup(t *testing.T, result services.PlaylistCreation, err error) (CreatePlaylistHandler, *jobs.CreationJobQueue) {
+	t.Helper()
+
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"CreatePlaylistWithArtists", mock.Anything, mock.Anything, playlistArtists(), mock.Anything,
+	).Return(result, err)
+	queue := jobs.NewCreationJobQueue(logging.NoopLogger{})
+	handler := NewCreatePlaylistHandler(playlistService, logging.NoopLogger{})
+	handler.SetJobQueue(queue)
+	return handler, queue
+}
+
+func submitJob(t *testing.T, handler CreatePlaylistHandler) PlaylistJobResponse {
+	t.Helper()
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, withUserId(buildRequest(t, []byte(requestBodyString)), jobUserId))
+
+	var response PlaylistJobResponse
+	assert.Equal(t, http.StatusAccepted, writer.Code)
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	return response
+}
+
+func getJob(handler GetPlaylistJobHandler, jobId string, userId string) *httptest.ResponseRecorder {
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, buildGetJobRequest(jobId, userId))
+	return writer
+}
+
+func waitForJobResponse(t *testing.T, handler GetPlaylistJobHandler, jobId string) PlaylistJobResponse {
+	t.Helper()
+
+	var response PlaylistJobResponse
+	assert.Eventually(t, func() bool {
+		writer := getJob(handler, jobId, jobUserId)
+		response = PlaylistJobResponse{}
+		_ = json.Unmarshal(writer.Body.Bytes(), &response)
+		return response.FinishedAt != nil
+	}, time.Second, time.Millisecond)
+	return response
+}
+
+func TestCreatePlaylistHandlerQueuesJobWhenQueueIsSet(t *testing.T) {
+	handler, _ := asyncSetup(t, services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, withUserId(buildRequest(t, []byte(requestBodyString)), jobUserId))
+
+	var response PlaylistJobResponse
+	assert.Equal(t, http.StatusAccepted, writer.Code)
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	assert.Equal(t, "/playlists/jobs/"+response.Id, writer.Header().Get("Location"))
+	assert.Equal(t, "queued", response.Status)
+	expectedArtists := []PlaylistJobArtist{
+		{Name: "Comeback Kid", Status: "pending"},
+		{Name: "Municipal Waste", Status: "pending"},
+	}
+	assert.Equal(t, expectedArtists, response.Artists)
+}
+
+func TestCreatePlaylistHandlerReturnsUnavailableWhenQueueIsFull(t *testing.T) {
+	handler, queue := asyncSetup(t, services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	queue.SetCapacity(1)
+	submitJob(t, handler)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, withUserId(buildRequest(t, []byte(requestBodyString)), jobUserId))
+
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code)
+}
+
+func TestCreatePlaylistHandlerRejectsRollbackOnCancelWhenQueueIsSet(t *testing.T) {
+	handler, queue := asyncSetup(t, services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	queue.SetCapacity(1)
+	writer := httptest.NewRecorder()
+	body := `{"playlist": {"name": "my playlist"}, "artists":[{"name":"Comeback Kid"}], "rollbackOnCancel": true}`
+
+	handler.ServeHTTP(writer, withUserId(buildRequest(t, []byte(body)), jobUserId))
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Contains(t, writer.Body.String(), "rollbackOnCancel is not supported")
+	// Nothing was queued, so there is still room for another job
+	submitJob(t, handler)
+}
+
+func TestGetPlaylistJobHandlerReturnsCreatedPlaylist(t *testing.T) {
+	creation := services.PlaylistCreation{
+		PlaylistId: playlistId,
+		Status:     services.PartialFailure,
+		Artists:    []services.ArtistCreation{{Name: "Comeback Kid", Songs: []services.AddedSong{}}},
+	}
+	createHandler, queue := asyncSetup(t, creation, nil)
+	queue.Start()
+	defer queue.Stop()
+	handler := NewGetPlaylistJobHandler(queue, logging.NoopLogger{})
+
+	job := submitJob(t, createHandler)
+	actual := waitForJobResponse(t, handler, job.Id)
+
+	expectedPlaylist := &CreatedPlaylist{
+		Id:      playlistId,
+		Artists: []CreatedPlaylistArtist{{Name: "Comeback Kid", Tracks: []CreatedPlaylistTrack{}}},
+	}
+	assert.Equal(t, "partially_succeeded", actual.Status)
+	assert.Equal(t, expectedPlaylist, actual.Playlist)
+	assert.Empty(t, actual.Error)
+}
+
+func TestGetPlaylistJobHandlerReturnsCleanupOfFailedJob(t *testing.T) {
+	creationErr := &services.CreationError{
+		Err:     errors.New("test error"),
+		Cleanup: services.CleanupReport{PlaylistId: playlistId, PlaylistDeleted: true},
+	}
+	createHandler, queue := asyncSetup(t, services.PlaylistCreation{}, creationErr)
+	queue.Start()
+	defer queue.Stop()
+	handler := NewGetPlaylistJobHandler(queue, logging.NoopLogger{})
+
+	job := submitJob(t, createHandler)
+	actual := waitForJobResponse(t, handler, job.Id)
+
+	assert.Equal(t, "failed", actual.Status)
+	assert.Nil(t, actual.Playlist)
+	assert.Equal(t, "unexpected error, could not create playlist", actual.Error)
+	assert.Equal(t, &PlaylistCleanup{PlaylistId: playlistId, PlaylistDeleted: true}, actual.Cleanup)
+}
+
+func TestGetPlaylistJobHandlerReturnsNotFound(t *testing.T) {
+	createHandler, queue := asyncSetup(t, services.PlaylistCreation{PlaylistId: playlistId, Status: services.Success}, nil)
+	handler := NewGetPlaylistJobHandler(queue, logging.NoopLogger{})
+	job := submitJob(t, createHandler)
+
+	tests := map[string]struct {
+		jobId  string
+		userId string
+	}{
+		"unknown job": {
+			jobId:  "unknown",
+			userId: jobUserId,
+		},
+		"job of another user": {
+			jobId:  job.Id,
+			userId: "another_user",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := getJob(handler, test.jobId, test.userId)
+
+			assert.Equal(t, http.StatusNotFound, writer.Code)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package playlist
+
+import services "festwrap/cmd/services"
+
+type PlaylistRefreshRequest struct {
+	Artists            []PlaylistArtist `json:"artists"`
+	RemoveDroppedSongs bool             `json:"removeDroppedSongs"`
+}
+
+func (r PlaylistRefreshRequest) GetArtistNames() []string {
+	return getArtistNames(r.Artists)
+}
+
+func (r PlaylistRefreshRequest) GetOptions() services.RefreshOptions {
+	return services.RefreshOptions{RemoveDroppedSongs: r.RemoveDroppedSongs}
+}
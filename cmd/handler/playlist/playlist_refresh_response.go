@@ -0,0 +1,46 @@
+package playlist
+
+import (
+	"time"
+
+	"festwrap/cmd/refresh"
+)
+
+type LastPlaylistRefresh struct {
+	RefreshedAt   time.Time `json:"refreshedAt"`
+	AddedSongs    int       `json:"addedSongs"`
+	RemovedSongs  int       `json:"removedSongs"`
+	FailedArtists int       `json:"failedArtists"`
+	Error         string    `json:"error,omitempty"`
+}
+
+type PlaylistRefreshResponse struct {
+	PlaylistId         string               `json:"playlistId"`
+	Artists            []string             `json:"artists"`
+	RemoveDroppedSongs bool                 `json:"removeDroppedSongs"`
+	NextRefreshAt      time.Time            `json:"nextRefreshAt"`
+	LastRefresh        *LastPlaylistRefresh `json:"lastRefresh,omitempty"`
+}
+
+func NewPlaylistRefreshResponse(scheduled refresh.ScheduledRefresh) PlaylistRefreshResponse {
+	response := PlaylistRefreshResponse{
+		PlaylistId:         scheduled.PlaylistId,
+		Artists:            scheduled.Artists,
+		RemoveDroppedSongs: scheduled.Options.RemoveDroppedSongs,
+		NextRefreshAt:      scheduled.NextRefreshAt,
+	}
+	if scheduled.LastRefreshedAt.IsZero() {
+		return response
+	}
+
+	response.LastRefresh = &LastPlaylistRefresh{
+		RefreshedAt:   scheduled.LastRefreshedAt,
+		AddedSongs:    len(scheduled.LastRefresh.AddedSongs),
+		RemovedSongs:  len(scheduled.LastRefresh.RemovedSongs),
+		FailedArtists: scheduled.LastRefresh.FailedArtists,
+	}
+	if scheduled.LastErr != nil {
+		response.LastRefresh.Error = scheduled.LastErr.Error()
+	}
+	return response
+}
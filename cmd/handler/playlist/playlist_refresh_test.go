@@ -0,0 +1,157 @@
+package playlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"festwrap/cmd/middleware/auth"
+	"festwrap/cmd/refresh"
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	"festwrap/internal/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func buildRefreshRequest(method string, body string, userId string) *http.Request {
+	request := httptest.NewRequest(
+		method, "https://example.com/playlists/"+playlistId+"/refresh", bytes.NewBufferString(body),
+	)
+	return withUserId(mux.SetURLVars(request, map[string]string{"id": playlistId}), userId)
+}
+
+func newTestRefreshScheduler() *refresh.RefreshScheduler {
+	return newTestRefreshSchedulerWithOwnerErr(nil)
+}
+
+func newTestRefreshSchedulerWithOwnerErr(ownerErr error) *refresh.RefreshScheduler {
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On("CheckPlaylistOwner", mock.Anything, playlistId).Return(ownerErr)
+	return refresh.NewRefreshScheduler(playlistService, &auth.AuthClientMock{}, logging.NoopLogger{})
+}
+
+func scheduleTestRefresh(t *testing.T, scheduler *refresh.RefreshScheduler) {
+	t.Helper()
+
+	ctx := buildRefreshRequest(http.MethodPut, "", jobUserId).Context()
+	_, err := scheduler.Schedule(ctx, playlistId, playlistArtists(), services.RefreshOptions{})
+	assert.Nil(t, err)
+}
+
+func TestSchedulePlaylistRefreshHandlerSchedulesRefresh(t *testing.T) {
+	scheduler := newTestRefreshScheduler()
+	handler := NewSchedulePlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	body := `{"artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}], "removeDroppedSongs": true}`
+	writer := httptest.NewRecorder()
+	request := buildRefreshRequest(http.MethodPut, body, jobUserId)
+
+	handler.ServeHTTP(writer, request)
+
+	var response PlaylistRefreshResponse
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	assert.Equal(t, playlistId, response.PlaylistId)
+	assert.Equal(t, playlistArtists(), response.Artists)
+	assert.True(t, response.RemoveDroppedSongs)
+	assert.Nil(t, response.LastRefresh)
+	scheduled, err := scheduler.GetRefresh(request.Context(), playlistId)
+	assert.Nil(t, err)
+	assert.Equal(t, services.RefreshOptions{RemoveDroppedSongs: true}, scheduled.Options)
+}
+
+func TestSchedulePlaylistRefreshHandlerReturnsForbiddenWhenPlaylistNotOwned(t *testing.T) {
+	scheduler := newTestRefreshSchedulerWithOwnerErr(services.ErrPlaylistNotOwned)
+	handler := NewSchedulePlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	body := `{"artists":[{"name":"Comeback Kid"}]}`
+	writer := httptest.NewRecorder()
+	request := buildRefreshRequest(http.MethodPut, body, jobUserId)
+
+	handler.ServeHTTP(writer, request)
+
+	_, err := scheduler.GetRefresh(request.Context(), playlistId)
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+	assert.ErrorIs(t, err, refresh.ErrRefreshNotFound)
+}
+
+func TestSchedulePlaylistRefreshHandlerReturnsBadRequestOnInvalidRequest(t *testing.T) {
+	tests := map[string]struct {
+		requestBody string
+	}{
+		"incorrect body": {
+			requestBody: "`some_incorrect_body}",
+		},
+		"no artists": {
+			requestBody: `{"artists":[]}`,
+		},
+		"empty artist name": {
+			requestBody: `{"artists":[{"name":""}]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheduler := newTestRefreshScheduler()
+			handler := NewSchedulePlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+			writer := httptest.NewRecorder()
+
+			handler.ServeHTTP(writer, buildRefreshRequest(http.MethodPut, test.requestBody, jobUserId))
+
+			assert.Equal(t, http.StatusBadRequest, writer.Code)
+		})
+	}
+}
+
+func TestGetPlaylistRefreshHandlerReturnsScheduledRefresh(t *testing.T) {
+	scheduler := newTestRefreshScheduler()
+	scheduleTestRefresh(t, scheduler)
+	handler := NewGetPlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildRefreshRequest(http.MethodGet, "", jobUserId))
+
+	var response PlaylistRefreshResponse
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	assert.Equal(t, playlistId, response.PlaylistId)
+	assert.Equal(t, playlistArtists(), response.Artists)
+}
+
+func TestGetPlaylistRefreshHandlerReturnsNotFoundForOtherUsers(t *testing.T) {
+	scheduler := newTestRefreshScheduler()
+	scheduleTestRefresh(t, scheduler)
+	handler := NewGetPlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildRefreshRequest(http.MethodGet, "", "other_user"))
+
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+func TestCancelPlaylistRefreshHandlerRemovesRefresh(t *testing.T) {
+	scheduler := newTestRefreshScheduler()
+	scheduleTestRefresh(t, scheduler)
+	handler := NewCancelPlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	writer := httptest.NewRecorder()
+
+	request := buildRefreshRequest(http.MethodDelete, "", jobUserId)
+	handler.ServeHTTP(writer, request)
+
+	_, err := scheduler.GetRefresh(request.Context(), playlistId)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.ErrorIs(t, err, refresh.ErrRefreshNotFound)
+}
+
+func TestCancelPlaylistRefreshHandlerReturnsNotFoundWhenNotScheduled(t *testing.T) {
+	scheduler := newTestRefreshScheduler()
+	handler := NewCancelPlaylistRefreshHandler(scheduler, logging.NoopLogger{})
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildRefreshRequest(http.MethodDelete, "", jobUserId))
+
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
@@ -0,0 +1,76 @@
+package playlist
+
+import (
+	"errors"
+	services "festwrap/cmd/services"
+	"festwrap/internal/playlist"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Spotify adds at most 100 songs per request, so this keeps playlists with chosen tracks to a few requests
+const maxTracks = 500
+
+func validatePlaylistDetails(details playlist.PlaylistDetails, maxNameLength int, maxDescriptionLength int) error {
+	nameLength := utf8.RuneCountInString(details.Name)
+	if nameLength == 0 || nameLength > maxNameLength {
+		return fmt.Errorf("validation error: playlist name length should be in interval [1, %d]", maxNameLength)
+	}
+
+	if utf8.RuneCountInString(details.Description) > maxDescriptionLength {
+		return fmt.Errorf("validation error: playlist description cannot exceed %d characters", maxDescriptionLength)
+	}
+
+	if details.IsCollaborative && details.IsPublic {
+		return errors.New("validation error: collaborative playlists cannot be public")
+	}
+	return nil
+}
+
+// Validates the playlist, artists and options of a request for a new playlist
+func validateNewPlaylistRequest(
+	request NewPlaylistRequest,
+	maxArtists int,
+	maxArtistNameLength int,
+	maxPlaylistNameLength int,
+	maxDescriptionLength int,
+) error {
+	err := validatePlaylistDetails(request.Playlist.GetDetails(), maxPlaylistNameLength, maxDescriptionLength)
+	if err != nil {
+		return err
+	}
+
+	if err = validateArtists(request.Artists, maxArtists, maxArtistNameLength); err != nil {
+		return err
+	}
+
+	if !services.IsValidOrderingStrategy(request.GetCreationOptions().Ordering.Strategy) {
+		return fmt.Errorf("validation error: invalid ordering strategy %s", request.Ordering)
+	}
+
+	if request.HasTracks() {
+		return validateArtistTracks(request.Artists)
+	}
+	return nil
+}
+
+// Tracks are given for all the artists or for none of them, so setlists are not looked up for just some
+func validateArtistTracks(artists []PlaylistArtist) error {
+	numTracks := 0
+	for _, artist := range artists {
+		if artist.Tracks == nil {
+			return fmt.Errorf("validation error: tracks missing for artist '%s'", artist.Name)
+		}
+		for _, addedSong := range artist.GetSongs() {
+			if addedSong.Song.Uri == "" {
+				return fmt.Errorf("validation error: track without uri for artist '%s'", artist.Name)
+			}
+			numTracks += 1
+		}
+	}
+
+	if numTracks == 0 || numTracks > maxTracks {
+		return fmt.Errorf("validation error: number of tracks must be between 1 and %d", maxTracks)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package playlist
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	services "festwrap/cmd/services"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+)
+
+// Shows the songs a new playlist would have, so users can review the matches before creating it
+type PreviewPlaylistHandler struct {
+	playlistServices    map[Provider]services.PlaylistService
+	providerMiddlewares providerMiddlewares
+	logger              logging.Logger
+	maxArtists          int
+	maxArtistNameLength int
+	requestDeserializer serialization.Deserializer[PreviewPlaylistRequest]
+	responseEncoder     serialization.Encoder[PreviewPlaylistResponse]
+}
+
+func NewPreviewPlaylistHandler(
+	playlistService services.PlaylistService,
+	logger logging.Logger,
+) PreviewPlaylistHandler {
+	requestDeserializer := serialization.NewJsonDeserializer[PreviewPlaylistRequest]()
+	responseEncoder := serialization.NewJsonEncoder[PreviewPlaylistResponse]()
+	return PreviewPlaylistHandler{
+		playlistServices:    map[Provider]services.PlaylistService{SpotifyProvider: playlistService},
+		providerMiddlewares: providerMiddlewares{},
+		logger:              logger,
+		maxArtists:          5,
+		maxArtistNameLength: 50,
+		requestDeserializer: &requestDeserializer,
+		responseEncoder:     &responseEncoder,
+	}
+}
+
+func (h *PreviewPlaylistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("could not read preview playlist body from request: %v", err))
+		http.Error(w, "could not read body from request", http.StatusBadRequest)
+		return
+	}
+
+	var previewRequest PreviewPlaylistRequest
+	err = h.requestDeserializer.Deserialize(requestBody, &previewRequest)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("failed to deserialize preview information: %v", err))
+		http.Error(w, "failed to read preview information", http.StatusBadRequest)
+		return
+	}
+
+	artists := previewRequest.Artists
+	h.logger.Info(fmt.Sprintf("previewing playlist with artists: %v", artists))
+	if err = validateArtists(artists, h.maxArtists, h.maxArtistNameLength); err != nil {
+		h.logger.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	provider := previewRequest.Provider
+	if provider == "" {
+		provider = SpotifyProvider
+	}
+	playlistService, ok := h.playlistServices[provider]
+	if !ok {
+		message := fmt.Sprintf("unsupported provider %s", provider)
+		h.logger.Warn(message)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	h.providerMiddlewares.wrap(provider, func(w http.ResponseWriter, r *http.Request) {
+		h.previewPlaylist(w, r, playlistService, previewRequest)
+	}).ServeHTTP(w, r)
+}
+
+func (h *PreviewPlaylistHandler) previewPlaylist(
+	w http.ResponseWriter,
+	r *http.Request,
+	playlistService services.PlaylistService,
+	previewRequest PreviewPlaylistRequest,
+) {
+	artists := previewRequest.Artists
+	preview, err := playlistService.PreviewPlaylistWithArtists(
+		r.Context(),
+		previewRequest.GetArtistNames(),
+		previewRequest.Deduplication.GetOptions(),
+	)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("could not preview playlist: %v", err))
+		http.Error(w, "unexpected error, could not preview playlist", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("previewed playlist with artists %v", artists))
+
+	w.WriteHeader(http.StatusOK)
+	if err = h.responseEncoder.Encode(w, NewPreviewPlaylistResponse(preview)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode response: %v", err))
+		http.Error(w, "unexpected error, could not encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *PreviewPlaylistHandler) GetPlaylistService() services.PlaylistService {
+	return h.playlistServices[SpotifyProvider]
+}
+
+func (h *PreviewPlaylistHandler) SetPlaylistService(service services.PlaylistService) {
+	h.playlistServices[SpotifyProvider] = service
+}
+
+// Sets the service used to preview the playlists of the given provider
+func (h *PreviewPlaylistHandler) SetProviderPlaylistService(provider Provider, service services.PlaylistService) {
+	h.playlistServices[provider] = service
+}
+
+// Sets the middlewares run, in order, before previewing the playlists of the given provider
+func (h *PreviewPlaylistHandler) SetProviderMiddlewares(provider Provider, middlewares ...Middleware) {
+	h.providerMiddlewares[provider] = middlewares
+}
+
+func (h *PreviewPlaylistHandler) SetMaxArtists(limit int) {
+	h.maxArtists = limit
+}
+
+func (h *PreviewPlaylistHandler) SetMaxArtistNameLength(length int) {
+	h.maxArtistNameLength = length
+}
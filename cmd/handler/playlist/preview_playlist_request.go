@@ -0,0 +1,12 @@
+package playlist
+
+type PreviewPlaylistRequest struct {
+	Artists       []PlaylistArtist      `json:"artists"`
+	Deduplication PlaylistDeduplication `json:"deduplication"`
+	// Spotify is used when empty
+	Provider Provider `json:"provider"`
+}
+
+func (r PreviewPlaylistRequest) GetArtistNames() []string {
+	return getArtistNames(r.Artists)
+}
@@ -0,0 +1,64 @@
+package playlist
+
+import (
+	services "festwrap/cmd/services"
+	"festwrap/internal/song"
+)
+
+type PreviewTrack struct {
+	SetlistTitle string                   `json:"setlistTitle"`
+	Status       services.SongMatchStatus `json:"status"`
+	Track        *song.Song               `json:"track,omitempty"`
+	Reason       string                   `json:"reason,omitempty"`
+}
+
+type PreviewArtist struct {
+	Name       string         `json:"name"`
+	SetlistUrl string         `json:"setlistUrl,omitempty"`
+	Tracks     []PreviewTrack `json:"tracks"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type PreviewTotals struct {
+	Artists           int `json:"artists"`
+	FailedArtists     int `json:"failedArtists"`
+	MatchedSongs      int `json:"matchedSongs"`
+	UnmatchedSongs    int `json:"unmatchedSongs"`
+	DuplicatesRemoved int `json:"duplicatesRemoved"`
+}
+
+type PreviewPlaylistResponse struct {
+	Artists []PreviewArtist `json:"artists"`
+	Totals  PreviewTotals   `json:"totals"`
+}
+
+func NewPreviewPlaylistResponse(preview services.PlaylistPreview) PreviewPlaylistResponse {
+	artists := make([]PreviewArtist, len(preview.Artists))
+	for i, artist := range preview.Artists {
+		tracks := make([]PreviewTrack, len(artist.Songs))
+		for j, match := range artist.Songs {
+			tracks[j] = PreviewTrack{SetlistTitle: match.SetlistTitle, Status: match.Status}
+			if match.Status != services.SongNotFound {
+				track := match.Song
+				tracks[j].Track = &track
+			}
+			if match.Err != nil {
+				tracks[j].Reason = match.Err.Error()
+			}
+		}
+		artists[i] = PreviewArtist{Name: artist.Name, SetlistUrl: artist.SetlistUrl, Tracks: tracks}
+		if artist.Err != nil {
+			artists[i].Error = artist.Err.Error()
+		}
+	}
+	return PreviewPlaylistResponse{
+		Artists: artists,
+		Totals: PreviewTotals{
+			Artists:           len(preview.Artists),
+			FailedArtists:     preview.FailedArtists,
+			MatchedSongs:      preview.MatchedSongs,
+			UnmatchedSongs:    preview.UnmatchedSongs,
+			DuplicatesRemoved: preview.DuplicatesRemoved,
+		},
+	}
+}
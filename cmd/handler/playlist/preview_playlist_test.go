@@ -0,0 +1,173 @@
+package playlist
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	"festwrap/internal/logging"
+	"festwrap/internal/song"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func buildPreviewRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "https://example.com/playlists/preview", bytes.NewBufferString(body))
+}
+
+func testPreview() services.PlaylistPreview {
+	return services.PlaylistPreview{
+		Artists: []services.ArtistPreview{
+			{
+				Name:       "Comeback Kid",
+				SetlistUrl: "https://comeback_kid",
+				Songs: []services.SongMatch{
+					{SetlistTitle: "Wake the Dead", Status: services.SongMatched, Song: song.NewSong("spotify:track:1")},
+					{SetlistTitle: "G.M. Vincent & I", Status: services.SongNotFound, Err: errors.New("not found")},
+					{SetlistTitle: "Wake the Dead", Status: services.SongDuplicated, Song: song.NewSong("spotify:track:1")},
+				},
+			},
+			{Name: "Municipal Waste", Err: errors.New("setlist not found")},
+		},
+		FailedArtists:     1,
+		MatchedSongs:      1,
+		UnmatchedSongs:    1,
+		DuplicatesRemoved: 1,
+	}
+}
+
+func setupPreview(preview services.PlaylistPreview, err error) (PreviewPlaylistHandler, *playlistmocks.PlaylistServiceMock) {
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"PreviewPlaylistWithArtists", mock.Anything, playlistArtists(), services.DeduplicationOptions{},
+	).Return(preview, err)
+	return NewPreviewPlaylistHandler(playlistService, logging.NoopLogger{}), playlistService
+}
+
+func TestPreviewPlaylistHandlerReturnsPreview(t *testing.T) {
+	handler, _ := setupPreview(testPreview(), nil)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildPreviewRequest(requestBodyString))
+
+	expected := `{
+		"artists": [
+			{
+				"name": "Comeback Kid",
+				"setlistUrl": "https://comeback_kid",
+				"tracks": [
+					{"setlistTitle": "Wake the Dead", "status": "matched", "track": {"uri": "spotify:track:1", "explicit": false}},
+					{"setlistTitle": "G.M. Vincent & I", "status": "not_found", "reason": "not found"},
+					{"setlistTitle": "Wake the Dead", "status": "duplicated", "track": {"uri": "spotify:track:1", "explicit": false}}
+				]
+			},
+			{"name": "Municipal Waste", "tracks": [], "error": "setlist not found"}
+		],
+		"totals": {"artists": 2, "failedArtists": 1, "matchedSongs": 1, "unmatchedSongs": 1, "duplicatesRemoved": 1}
+	}`
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, expected, writer.Body.String())
+}
+
+func TestPreviewPlaylistHandlerPassesDeduplicationOptions(t *testing.T) {
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On(
+		"PreviewPlaylistWithArtists", mock.Anything, playlistArtists(), services.DeduplicationOptions{ByIsrc: true},
+	).Return(testPreview(), nil)
+	handler := NewPreviewPlaylistHandler(playlistService, logging.NoopLogger{})
+	body := `{"artists":[{"name":"Comeback Kid"}, {"name":"Municipal Waste"}], "deduplication": {"byIsrc": true}}`
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildPreviewRequest(body))
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	playlistService.AssertExpectations(t)
+}
+
+func TestPreviewPlaylistHandlerUsesProviderService(t *testing.T) {
+	handler, spotifyService := setupPreview(testPreview(), nil)
+	youTubeService := &playlistmocks.PlaylistServiceMock{}
+	youTubeService.On(
+		"PreviewPlaylistWithArtists", mock.Anything, []string{"Comeback Kid"}, services.DeduplicationOptions{},
+	).Return(services.PlaylistPreview{}, nil)
+	handler.SetProviderPlaylistService(YouTubeMusicProvider, youTubeService)
+	body := `{"artists":[{"name":"Comeback Kid"}], "provider": "youtube_music"}`
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildPreviewRequest(body))
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	youTubeService.AssertExpectations(t)
+	spotifyService.AssertNotCalled(t, "PreviewPlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreviewPlaylistHandlerRunsMiddlewaresOfRequestedProvider(t *testing.T) {
+	handler, _ := setupPreview(testPreview(), nil)
+	youTubeService := &playlistmocks.PlaylistServiceMock{}
+	youTubeService.On(
+		"PreviewPlaylistWithArtists", mock.Anything, []string{"Comeback Kid"}, services.DeduplicationOptions{},
+	).Return(services.PlaylistPreview{}, nil)
+	handler.SetProviderPlaylistService(YouTubeMusicProvider, youTubeService)
+	calls := []string{}
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	handler.SetProviderMiddlewares(YouTubeMusicProvider, middleware("first"), middleware("second"))
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildPreviewRequest(`{"artists":[{"name":"Comeback Kid"}], "provider": "youtube_music"}`))
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, []string{"first", "second"}, calls)
+	youTubeService.AssertExpectations(t)
+}
+
+func TestPreviewPlaylistHandlerReturnsBadRequestOnInvalidRequest(t *testing.T) {
+	tests := map[string]struct {
+		requestBody string
+	}{
+		"incorrect body": {
+			requestBody: "`some_incorrect_body}",
+		},
+		"no artists": {
+			requestBody: `{"artists":[]}`,
+		},
+		"empty artist name": {
+			requestBody: `{"artists":[{"name":""}]}`,
+		},
+		"unsupported provider": {
+			requestBody: `{"artists":[{"name":"Comeback Kid"}], "provider": "tidal"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, playlistService := setupPreview(testPreview(), nil)
+			writer := httptest.NewRecorder()
+
+			handler.ServeHTTP(writer, buildPreviewRequest(test.requestBody))
+
+			assert.Equal(t, http.StatusBadRequest, writer.Code)
+			playlistService.AssertNotCalled(t, "PreviewPlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPreviewPlaylistHandlerReturnsErrorOnServiceError(t *testing.T) {
+	handler, _ := setupPreview(services.PlaylistPreview{}, errors.New("test error"))
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildPreviewRequest(requestBodyString))
+
+	assert.Equal(t, http.StatusInternalServerError, writer.Code)
+}
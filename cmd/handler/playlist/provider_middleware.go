@@ -0,0 +1,18 @@
+package playlist
+
+import "net/http"
+
+type Middleware func(http.Handler) http.Handler
+
+// Middlewares run only on the requests for a provider, such as the ones obtaining the tokens of its API. The
+// provider is read from the request body, so they cannot be attached to the routes
+type providerMiddlewares map[Provider][]Middleware
+
+func (m providerMiddlewares) wrap(provider Provider, handler http.HandlerFunc) http.Handler {
+	var wrapped http.Handler = handler
+	middlewares := m[provider]
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		wrapped = middlewares[i](wrapped)
+	}
+	return wrapped
+}
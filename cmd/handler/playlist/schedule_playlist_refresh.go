@@ -0,0 +1,98 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"festwrap/cmd/refresh"
+	services "festwrap/cmd/services"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+
+	"github.com/gorilla/mux"
+)
+
+type SchedulePlaylistRefreshHandler struct {
+	scheduler           *refresh.RefreshScheduler
+	logger              logging.Logger
+	maxArtists          int
+	maxArtistNameLength int
+	requestDeserializer serialization.Deserializer[PlaylistRefreshRequest]
+	responseEncoder     serialization.Encoder[PlaylistRefreshResponse]
+}
+
+func NewSchedulePlaylistRefreshHandler(
+	scheduler *refresh.RefreshScheduler,
+	logger logging.Logger,
+) SchedulePlaylistRefreshHandler {
+	requestDeserializer := serialization.NewJsonDeserializer[PlaylistRefreshRequest]()
+	responseEncoder := serialization.NewJsonEncoder[PlaylistRefreshResponse]()
+	return SchedulePlaylistRefreshHandler{
+		scheduler:           scheduler,
+		logger:              logger,
+		maxArtists:          5,
+		maxArtistNameLength: 50,
+		requestDeserializer: &requestDeserializer,
+		responseEncoder:     &responseEncoder,
+	}
+}
+
+func (h *SchedulePlaylistRefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	playlistId := mux.Vars(r)["id"]
+	if playlistId == "" {
+		h.logger.Warn("playlist id not provided when scheduling refresh")
+		http.Error(w, "validation error: playlist id was not provided", http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("could not read refresh body from request: %v", err))
+		http.Error(w, "could not read body from request", http.StatusBadRequest)
+		return
+	}
+
+	var refreshRequest PlaylistRefreshRequest
+	if err = h.requestDeserializer.Deserialize(requestBody, &refreshRequest); err != nil {
+		h.logger.Error(fmt.Sprintf("failed to deserialize refresh information: %v", err))
+		http.Error(w, "failed to read refresh information", http.StatusBadRequest)
+		return
+	}
+
+	if err = validateArtists(refreshRequest.Artists, h.maxArtists, h.maxArtistNameLength); err != nil {
+		h.logger.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scheduled, err := h.scheduler.Schedule(
+		r.Context(), playlistId, refreshRequest.GetArtistNames(), refreshRequest.GetOptions(),
+	)
+	if errors.Is(err, services.ErrPlaylistNotOwned) {
+		h.logger.Warn(fmt.Sprintf("could not schedule refresh of playlist %s: %v", playlistId, err))
+		http.Error(w, "playlist does not belong to the current user", http.StatusForbidden)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not schedule refresh of playlist %s: %v", playlistId, err))
+		http.Error(w, "unexpected error, could not schedule refresh", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info(fmt.Sprintf("scheduled refresh of playlist %s with artists %v", playlistId, scheduled.Artists))
+
+	w.WriteHeader(http.StatusOK)
+	if err = h.responseEncoder.Encode(w, NewPlaylistRefreshResponse(scheduled)); err != nil {
+		h.logger.Error(fmt.Sprintf("encoding error, could not encode refresh response: %v", err))
+	}
+}
+
+func (h *SchedulePlaylistRefreshHandler) SetMaxArtists(limit int) {
+	h.maxArtists = limit
+}
+
+func (h *SchedulePlaylistRefreshHandler) SetMaxArtistNameLength(length int) {
+	h.maxArtistNameLength = length
+}
@@ -0,0 +1,142 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"festwrap/cmd/jobs"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+
+	"github.com/gorilla/mux"
+)
+
+const completedEventType = "completed"
+
+// Streams the progress of a playlist job as Server-Sent Events, ending with the finished job.
+// Clients reconnecting with the Last-Event-ID header only receive the events they missed
+type StreamPlaylistJobHandler struct {
+	jobQueue           *jobs.CreationJobQueue
+	logger             logging.Logger
+	keepAliveInterval  time.Duration
+	progressSerializer serialization.Serializer[ProgressEventResponse]
+	jobSerializer      serialization.Serializer[PlaylistJobResponse]
+}
+
+func NewStreamPlaylistJobHandler(jobQueue *jobs.CreationJobQueue, logger logging.Logger) StreamPlaylistJobHandler {
+	progressSerializer := serialization.NewJsonSerializer[ProgressEventResponse]()
+	jobSerializer := serialization.NewJsonSerializer[PlaylistJobResponse]()
+	return StreamPlaylistJobHandler{
+		jobQueue:           jobQueue,
+		logger:             logger,
+		keepAliveInterval:  15 * time.Second,
+		progressSerializer: &progressSerializer,
+		jobSerializer:      &jobSerializer,
+	}
+}
+
+func (h *StreamPlaylistJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jobId := mux.Vars(r)["id"]
+	if jobId == "" {
+		h.logger.Warn("job id not provided when streaming playlist job")
+		http.Error(w, "validation error: job id was not provided", http.StatusBadRequest)
+		return
+	}
+
+	lastEventId := 0
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		parsed, err := strconv.Atoi(header)
+		if err != nil || parsed < 0 {
+			h.logger.Warn(fmt.Sprintf("invalid last event id %s when streaming job %s", header, jobId))
+			http.Error(w, "validation error: invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+		lastEventId = parsed
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logger.Error("response writer does not support streaming")
+		http.Error(w, "unexpected error, could not stream job", http.StatusInternalServerError)
+		return
+	}
+
+	jobEvents, err := h.jobQueue.GetJobEvents(r.Context(), jobId, lastEventId)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		h.logger.Warn(fmt.Sprintf("could not stream playlist job: %v", err))
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error(fmt.Sprintf("could not stream playlist job: %v", err))
+		http.Error(w, "unexpected error, could not stream job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Comments keep proxies from closing the connection while artists are being resolved
+	keepAlive := time.NewTicker(h.keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		for _, jobEvent := range jobEvents.Events {
+			if err = h.writeEvent(w, jobEvent); err != nil {
+				h.logger.Error(fmt.Sprintf("could not write event %d of job %s: %v", jobEvent.Id, jobId, err))
+				return
+			}
+			lastEventId = jobEvent.Id
+		}
+		flusher.Flush()
+
+		if jobEvents.Finished {
+			return
+		}
+
+		select {
+		case <-jobEvents.Updated:
+		case <-keepAlive.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+
+		jobEvents, err = h.jobQueue.GetJobEvents(r.Context(), jobId, lastEventId)
+		if err != nil {
+			// The job may have expired while streaming it
+			h.logger.Warn(fmt.Sprintf("stopped streaming playlist job: %v", err))
+			return
+		}
+	}
+}
+
+func (h *StreamPlaylistJobHandler) writeEvent(w http.ResponseWriter, jobEvent jobs.JobEvent) error {
+	eventType := string(jobEvent.Progress.Type)
+	var data []byte
+	var err error
+	if jobEvent.Completion != nil {
+		eventType = completedEventType
+		data, err = h.jobSerializer.Serialize(NewPlaylistJobResponse(*jobEvent.Completion))
+	} else {
+		data, err = h.progressSerializer.Serialize(NewProgressEventResponse(jobEvent.Progress))
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", jobEvent.Id, eventType, data)
+	return err
+}
+
+// Sets how often a comment is sent while no events are available
+func (h *StreamPlaylistJobHandler) SetKeepAliveInterval(interval time.Duration) {
+	h.keepAliveInterval = interval
+}
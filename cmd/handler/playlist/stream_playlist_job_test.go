@@ -0,0 +1,226 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"festwrap/cmd/jobs"
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	"festwrap/internal/logging"
+	"festwrap/internal/playlist"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func buildStreamJobRequest(jobId string, userId string, lastEventId string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/playlists/jobs/"+jobId+"/events", nil)
+	if lastEventId != "" {
+		request.Header.Set("Last-Event-ID", lastEventId)
+	}
+	return withUserId(mux.SetURLVars(request, map[string]string{"id": jobId}), userId)
+}
+
+func streamSetup(t *testing.T) (StreamPlaylistJobHandler, *jobs.CreationJobQueue, string) {
+	t.Helper()
+
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On("CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			progress := args.Get(3).(services.CreationOptions).Progress
+			progress(services.ProgressEvent{
+				Type: services.SetlistFound, ArtistIndex: 0, Artist: "Comeback Kid", SetlistUrl: "https://comeback_kid", TotalSongs: 2,
+			})
+			progress(services.ProgressEvent{
+				Type:        services.SongResolved,
+				ArtistIndex: 0,
+				Artist:      "Comeback Kid",
+				SetlistUrl:  "https://comeback_kid",
+				SongTitle:   "Wake the Dead",
+				SongStatus:  services.SongMatched,
+			})
+			progress(services.ProgressEvent{
+				Type: services.ArtistFailed, ArtistIndex: 1, Artist: "Municipal Waste", Err: errors.New("setlist not found"),
+			})
+		}).
+		Return(services.PlaylistCreation{PlaylistId: playlistId, Status: services.PartialFailure}, nil)
+
+	queue := jobs.NewCreationJobQueue(logging.NoopLogger{})
+	job, err := queue.Submit(
+		withUserId(buildRequest(t, nil), jobUserId).Context(),
+		jobs.CreationRequest{
+			Service:  playlistService,
+			Playlist: playlist.PlaylistDetails{Name: playlistName},
+			Artists:  playlistArtists(),
+		},
+	)
+	assert.Nil(t, err)
+	return NewStreamPlaylistJobHandler(queue, logging.NoopLogger{}), queue, job.Id
+}
+
+func waitUntilFinished(t *testing.T, queue *jobs.CreationJobQueue, jobId string) {
+	t.Helper()
+
+	ctx := withUserId(buildRequest(t, nil), jobUserId).Context()
+	assert.Eventually(t, func() bool {
+		job, _ := queue.GetJob(ctx, jobId)
+		return job.IsFinished()
+	}, time.Second, time.Millisecond)
+}
+
+func streamEventHeaders(body string) []string {
+	var headers []string
+	for _, event := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		lines := strings.Split(event, "\n")
+		headers = append(headers, lines[0]+" "+lines[1])
+	}
+	return headers
+}
+
+func TestStreamPlaylistJobHandlerStreamsEventsUntilCompletion(t *testing.T) {
+	handler, queue, jobId := streamSetup(t)
+	queue.Start()
+	defer queue.Stop()
+	waitUntilFinished(t, queue, jobId)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildStreamJobRequest(jobId, jobUserId, ""))
+
+	expectedHeaders := []string{
+		"id: 1 event: setlist_found",
+		"id: 2 event: song_resolved",
+		"id: 3 event: artist_failed",
+		"id: 4 event: completed",
+	}
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "text/event-stream", writer.Header().Get("Content-Type"))
+	assert.Equal(t, expectedHeaders, streamEventHeaders(writer.Body.String()))
+}
+
+func TestStreamPlaylistJobHandlerWritesEventData(t *testing.T) {
+	handler, queue, jobId := streamSetup(t)
+	queue.Start()
+	defer queue.Stop()
+	waitUntilFinished(t, queue, jobId)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildStreamJobRequest(jobId, jobUserId, "1"))
+
+	events := strings.Split(strings.TrimSpace(writer.Body.String()), "\n\n")
+	songData := strings.TrimPrefix(strings.Split(events[0], "\n")[2], "data: ")
+	failureData := strings.TrimPrefix(strings.Split(events[1], "\n")[2], "data: ")
+	expectedSongData := `{
+		"artistIndex": 0,
+		"artist": "Comeback Kid",
+		"setlistUrl": "https://comeback_kid",
+		"totalSongs": 0,
+		"matchedSongs": 0,
+		"songTitle": "Wake the Dead",
+		"songStatus": "matched"
+	}`
+	expectedFailureData := `{
+		"artistIndex": 1,
+		"artist": "Municipal Waste",
+		"totalSongs": 0,
+		"matchedSongs": 0,
+		"error": "setlist not found"
+	}`
+	assert.JSONEq(t, expectedSongData, songData)
+	assert.JSONEq(t, expectedFailureData, failureData)
+	assert.Contains(t, events[2], `"status":"partially_succeeded"`)
+}
+
+func TestStreamPlaylistJobHandlerResumesAfterLastEventId(t *testing.T) {
+	handler, queue, jobId := streamSetup(t)
+	queue.Start()
+	defer queue.Stop()
+	waitUntilFinished(t, queue, jobId)
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, buildStreamJobRequest(jobId, jobUserId, "2"))
+
+	expectedHeaders := []string{"id: 3 event: artist_failed", "id: 4 event: completed"}
+	assert.Equal(t, expectedHeaders, streamEventHeaders(writer.Body.String()))
+}
+
+func TestStreamPlaylistJobHandlerStreamsEventsAsTheyHappen(t *testing.T) {
+	handler, queue, jobId := streamSetup(t)
+	handler.SetKeepAliveInterval(time.Millisecond)
+	writer := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(writer, buildStreamJobRequest(jobId, jobUserId, ""))
+	}()
+	queue.Start()
+	defer queue.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not finish")
+	}
+	assert.Contains(t, writer.Body.String(), "event: completed")
+}
+
+func TestStreamPlaylistJobHandlerStopsWhenClientDisconnects(t *testing.T) {
+	handler, _, jobId := streamSetup(t)
+	request := buildStreamJobRequest(jobId, jobUserId, "")
+	ctx, cancel := context.WithCancel(request.Context())
+	request = request.WithContext(ctx)
+	writer := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(writer, request)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop")
+	}
+	assert.NotContains(t, writer.Body.String(), "event: completed")
+}
+
+func TestStreamPlaylistJobHandlerReturnsErrorOnInvalidRequest(t *testing.T) {
+	handler, _, jobId := streamSetup(t)
+
+	tests := map[string]struct {
+		request      *http.Request
+		expectedCode int
+	}{
+		"invalid last event id": {
+			request:      buildStreamJobRequest(jobId, jobUserId, "abc"),
+			expectedCode: http.StatusBadRequest,
+		},
+		"job of another user": {
+			request:      buildStreamJobRequest(jobId, "another_user", ""),
+			expectedCode: http.StatusNotFound,
+		},
+		"unknown job": {
+			request:      buildStreamJobRequest("unknown", jobUserId, ""),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+
+			handler.ServeHTTP(writer, test.request)
+
+			assert.Equal(t, test.expectedCode, writer.Code)
+		})
+	}
+}
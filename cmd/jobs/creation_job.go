@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"slices"
+	"time"
+
+	services "festwrap/cmd/services"
+	"festwrap/internal/playlist"
+)
+
+type JobStatus string
+
+const (
+	JobQueued             JobStatus = "queued"
+	JobRunning            JobStatus = "running"
+	JobSucceeded          JobStatus = "succeeded"
+	JobPartiallySucceeded JobStatus = "partially_succeeded"
+	JobFailed             JobStatus = "failed"
+)
+
+type ArtistStatus string
+
+const (
+	ArtistPending      ArtistStatus = "pending"
+	ArtistSetlistFound ArtistStatus = "setlist_found"
+	ArtistResolved     ArtistStatus = "resolved"
+	ArtistFailed       ArtistStatus = "failed"
+)
+
+type ArtistProgress struct {
+	Name         string
+	Status       ArtistStatus
+	SetlistUrl   string
+	TotalSongs   int
+	MatchedSongs int
+	Err          error
+}
+
+// Everything needed to create a playlist once a worker picks the job up
+type CreationRequest struct {
+	Service  services.PlaylistService
+	Playlist playlist.PlaylistDetails
+	Artists  []string
+	Options  services.CreationOptions
+}
+
+type CreationJob struct {
+	Id         string
+	UserId     string
+	Status     JobStatus
+	Artists    []ArtistProgress
+	Result     services.PlaylistCreation
+	Err        error
+	CreatedAt  time.Time
+	FinishedAt time.Time
+	events     []JobEvent
+	updated    chan struct{}
+}
+
+// Progress of a job as it happened, kept so clients can replay it after reconnecting
+type JobEvent struct {
+	// Sequential, starting at 1
+	Id       int
+	Progress services.ProgressEvent
+	// Only set on the last event, with the job as it finished
+	Completion *CreationJob
+}
+
+type JobEvents struct {
+	Events   []JobEvent
+	Finished bool
+	// Closed as soon as a new event is available
+	Updated <-chan struct{}
+}
+
+func newCreationJob(id string, userId string, artists []string, createdAt time.Time) *CreationJob {
+	progress := make([]ArtistProgress, len(artists))
+	for i, artist := range artists {
+		progress[i] = ArtistProgress{Name: artist, Status: ArtistPending}
+	}
+	return &CreationJob{
+		Id:        id,
+		UserId:    userId,
+		Status:    JobQueued,
+		Artists:   progress,
+		CreatedAt: createdAt,
+		updated:   make(chan struct{}),
+	}
+}
+
+func (j *CreationJob) IsFinished() bool {
+	return j.Status == JobSucceeded || j.Status == JobPartiallySucceeded || j.Status == JobFailed
+}
+
+func (j *CreationJob) updateProgress(event services.ProgressEvent) {
+	if event.ArtistIndex < 0 || event.ArtistIndex >= len(j.Artists) {
+		return
+	}
+
+	artist := &j.Artists[event.ArtistIndex]
+	switch event.Type {
+	case services.SetlistFound:
+		artist.Status = ArtistSetlistFound
+		artist.SetlistUrl = event.SetlistUrl
+		artist.TotalSongs = event.TotalSongs
+	case services.ArtistResolved:
+		artist.Status = ArtistResolved
+		artist.SetlistUrl = event.SetlistUrl
+		artist.TotalSongs = event.TotalSongs
+		artist.MatchedSongs = event.MatchedSongs
+	case services.ArtistFailed:
+		artist.Status = ArtistFailed
+		artist.SetlistUrl = event.SetlistUrl
+		artist.Err = event.Err
+	}
+	j.addEvent(JobEvent{Progress: event})
+}
+
+func (j *CreationJob) finish(result services.PlaylistCreation, err error, finishedAt time.Time) {
+	j.Result = result
+	j.Err = err
+	j.FinishedAt = finishedAt
+	if err != nil {
+		j.Status = JobFailed
+	} else if result.Status == services.PartialFailure {
+		j.Status = JobPartiallySucceeded
+	} else {
+		j.Status = JobSucceeded
+	}
+
+	completion := j.snapshot()
+	j.addEvent(JobEvent{Completion: &completion})
+}
+
+func (j *CreationJob) addEvent(event JobEvent) {
+	event.Id = len(j.events) + 1
+	j.events = append(j.events, event)
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+func (j *CreationJob) eventsAfter(eventId int) JobEvents {
+	eventId = min(max(eventId, 0), len(j.events))
+	return JobEvents{
+		Events:   slices.Clone(j.events[eventId:]),
+		Finished: j.IsFinished(),
+		Updated:  j.updated,
+	}
+}
+
+// Copies the job so it can be read while the worker keeps updating it
+func (j *CreationJob) snapshot() CreationJob {
+	snapshot := *j
+	snapshot.Artists = slices.Clone(j.Artists)
+	snapshot.events = nil
+	snapshot.updated = nil
+	return snapshot
+}
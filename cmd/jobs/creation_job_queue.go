@@ -0,0 +1,213 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	services "festwrap/cmd/services"
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrQueueFull    = errors.New("job queue is full")
+	ErrQueueStopped = errors.New("job queue is stopped")
+)
+
+type queuedJob struct {
+	ctx     context.Context
+	job     *CreationJob
+	request CreationRequest
+}
+
+// Creates playlists in the background with a fixed number of workers.
+// Finished jobs are kept for the retention period so their result can be polled
+type CreationJobQueue struct {
+	mutex     sync.Mutex
+	jobs      map[string]*CreationJob
+	queue     chan queuedJob
+	workers   int
+	retention time.Duration
+	userIdKey types.ContextKey
+	stopped   bool
+	waitGroup sync.WaitGroup
+	now       func() time.Time
+	logger    logging.Logger
+}
+
+func NewCreationJobQueue(logger logging.Logger) *CreationJobQueue {
+	return &CreationJobQueue{
+		jobs:      map[string]*CreationJob{},
+		queue:     make(chan queuedJob, 100),
+		workers:   4,
+		retention: time.Hour,
+		userIdKey: "user_id",
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+func (q *CreationJobQueue) Start() {
+	for range q.workers {
+		q.waitGroup.Add(1)
+		go func() {
+			defer q.waitGroup.Done()
+			for queued := range q.queue {
+				q.run(queued)
+			}
+		}()
+	}
+}
+
+// Stops accepting jobs and waits for the queued ones to finish
+func (q *CreationJobQueue) Stop() {
+	q.mutex.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.queue)
+	}
+	q.mutex.Unlock()
+	q.waitGroup.Wait()
+}
+
+// Queues the creation on behalf of the user in the context.
+// The job keeps the context values, such as tokens, but is not cancelled with it
+func (q *CreationJobQueue) Submit(ctx context.Context, request CreationRequest) (CreationJob, error) {
+	userId, ok := ctx.Value(q.userIdKey).(string)
+	if !ok {
+		return CreationJob{}, errors.New("could not retrieve user id from context when submitting job")
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.stopped {
+		return CreationJob{}, ErrQueueStopped
+	}
+
+	q.removeExpiredJobs()
+	job := newCreationJob(uuid.NewString(), userId, request.Artists, q.now())
+	select {
+	case q.queue <- queuedJob{ctx: context.WithoutCancel(ctx), job: job, request: request}:
+	default:
+		return CreationJob{}, fmt.Errorf("%w: %d jobs pending", ErrQueueFull, len(q.queue))
+	}
+	q.jobs[job.Id] = job
+	return job.snapshot(), nil
+}
+
+// Returns the job if it belongs to the user in the context
+func (q *CreationJobQueue) GetJob(ctx context.Context, jobId string) (CreationJob, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	job, err := q.findUserJob(ctx, jobId)
+	if err != nil {
+		return CreationJob{}, err
+	}
+	return job.snapshot(), nil
+}
+
+// Returns the events of the job after the given event id, if it belongs to the user in the context
+func (q *CreationJobQueue) GetJobEvents(ctx context.Context, jobId string, afterEventId int) (JobEvents, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	job, err := q.findUserJob(ctx, jobId)
+	if err != nil {
+		return JobEvents{}, err
+	}
+	return job.eventsAfter(afterEventId), nil
+}
+
+func (q *CreationJobQueue) SetWorkers(workers int) {
+	q.workers = max(workers, 1)
+}
+
+// Sets the maximum number of jobs waiting for a worker
+func (q *CreationJobQueue) SetCapacity(capacity int) {
+	q.queue = make(chan queuedJob, max(capacity, 1))
+}
+
+// Sets how long finished jobs are kept
+func (q *CreationJobQueue) SetRetention(retention time.Duration) {
+	q.retention = retention
+}
+
+func (q *CreationJobQueue) SetUserIdKey(key types.ContextKey) {
+	q.userIdKey = key
+}
+
+func (q *CreationJobQueue) run(queued queuedJob) {
+	job := queued.job
+	request := queued.request
+
+	q.mutex.Lock()
+	job.Status = JobRunning
+	q.mutex.Unlock()
+
+	result, err := q.createPlaylist(queued.ctx, job, request)
+
+	q.mutex.Lock()
+	job.finish(result, err, q.now())
+	q.mutex.Unlock()
+
+	if err != nil {
+		q.logger.Error(fmt.Sprintf("playlist creation job %s failed: %v", job.Id, err))
+	} else {
+		q.logger.Info(fmt.Sprintf("playlist creation job %s created playlist %s", job.Id, result.PlaylistId))
+	}
+}
+
+func (q *CreationJobQueue) createPlaylist(
+	ctx context.Context,
+	job *CreationJob,
+	request CreationRequest,
+) (result services.PlaylistCreation, err error) {
+	// A failing job must not bring down the workers
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result, err = services.PlaylistCreation{}, fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	options := request.Options
+	requestProgress := options.Progress
+	options.Progress = func(event services.ProgressEvent) {
+		q.mutex.Lock()
+		job.updateProgress(event)
+		q.mutex.Unlock()
+		if requestProgress != nil {
+			requestProgress(event)
+		}
+	}
+	return request.Service.CreatePlaylistWithArtists(ctx, request.Playlist, request.Artists, options)
+}
+
+func (q *CreationJobQueue) findUserJob(ctx context.Context, jobId string) (*CreationJob, error) {
+	userId, ok := ctx.Value(q.userIdKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve user id from context when getting job")
+	}
+
+	q.removeExpiredJobs()
+	job, ok := q.jobs[jobId]
+	if !ok || job.UserId != userId {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobId)
+	}
+	return job, nil
+}
+
+func (q *CreationJobQueue) removeExpiredJobs() {
+	for jobId, job := range q.jobs {
+		if job.IsFinished() && q.now().Sub(job.FinishedAt) > q.retention {
+			delete(q.jobs, jobId)
+		}
+	}
+}
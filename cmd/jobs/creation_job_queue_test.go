@@ -0,0 +1,315 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+	"festwrap/internal/playlist"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const userId = "some_user"
+
+func testContext(user string) context.Context {
+	return context.WithValue(context.Background(), types.ContextKey("user_id"), user)
+}
+
+func testArtists() []string {
+	return []string{"Comeback Kid", "Municipal Waste"}
+}
+
+func testCreation() services.PlaylistCreation {
+	return services.PlaylistCreation{
+		PlaylistId: "some_playlist",
+		Status:     services.Success,
+		Artists:    []services.ArtistCreation{{Name: "Comeback Kid"}, {Name: "Municipal Waste"}},
+	}
+}
+
+func testRequest(service services.PlaylistService) CreationRequest {
+	return CreationRequest{
+		Service:  service,
+		Playlist: playlist.PlaylistDetails{Name: "My playlist"},
+		Artists:  testArtists(),
+		Options:  services.DefaultCreationOptions(),
+	}
+}
+
+func newServiceMock(creation services.PlaylistCreation, err error) *playlistmocks.PlaylistServiceMock {
+	service := &playlistmocks.PlaylistServiceMock{}
+	service.On(
+		"CreatePlaylistWithArtists", mock.Anything, playlist.PlaylistDetails{Name: "My playlist"}, testArtists(), mock.Anything,
+	).Return(creation, err)
+	return service
+}
+
+func waitForJob(t *testing.T, queue *CreationJobQueue, jobId string) CreationJob {
+	t.Helper()
+
+	var job CreationJob
+	assert.Eventually(t, func() bool {
+		job, _ = queue.GetJob(testContext(userId), jobId)
+		return job.IsFinished()
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func TestSubmitReturnsQueuedJob(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+
+	job, err := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, job.Id)
+	assert.Equal(t, userId, job.UserId)
+	assert.Equal(t, JobQueued, job.Status)
+	expectedArtists := []ArtistProgress{
+		{Name: "Comeback Kid", Status: ArtistPending},
+		{Name: "Municipal Waste", Status: ArtistPending},
+	}
+	assert.Equal(t, expectedArtists, job.Artists)
+}
+
+func TestJobStatusFromCreationResult(t *testing.T) {
+	partialCreation := testCreation()
+	partialCreation.Status = services.PartialFailure
+
+	tests := map[string]struct {
+		creation       services.PlaylistCreation
+		err            error
+		expectedStatus JobStatus
+	}{
+		"success": {
+			creation:       testCreation(),
+			expectedStatus: JobSucceeded,
+		},
+		"partial failure": {
+			creation:       partialCreation,
+			expectedStatus: JobPartiallySucceeded,
+		},
+		"error": {
+			err:            errors.New("test error"),
+			expectedStatus: JobFailed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queue := NewCreationJobQueue(logging.NoopLogger{})
+			queue.Start()
+			defer queue.Stop()
+
+			job, _ := queue.Submit(testContext(userId), testRequest(newServiceMock(test.creation, test.err)))
+			actual := waitForJob(t, queue, job.Id)
+
+			assert.Equal(t, test.expectedStatus, actual.Status)
+			assert.Equal(t, test.creation, actual.Result)
+			assert.Equal(t, test.err, actual.Err)
+			assert.False(t, actual.FinishedAt.IsZero())
+		})
+	}
+}
+
+func TestJobReportsArtistProgress(t *testing.T) {
+	setlistErr := errors.New("setlist not found")
+	service := &playlistmocks.PlaylistServiceMock{}
+	service.On("CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			progress := args.Get(3).(services.CreationOptions).Progress
+			progress(services.ProgressEvent{
+				Type: services.SetlistFound, ArtistIndex: 0, SetlistUrl: "https://comeback_kid", TotalSongs: 17,
+			})
+			progress(services.ProgressEvent{
+				Type: services.ArtistResolved, ArtistIndex: 0, SetlistUrl: "https://comeback_kid", TotalSongs: 17, MatchedSongs: 14,
+			})
+			progress(services.ProgressEvent{Type: services.ArtistFailed, ArtistIndex: 1, Err: setlistErr})
+		}).
+		Return(testCreation(), nil)
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.Start()
+	defer queue.Stop()
+
+	job, _ := queue.Submit(testContext(userId), testRequest(service))
+	actual := waitForJob(t, queue, job.Id)
+
+	expected := []ArtistProgress{
+		{
+			Name:         "Comeback Kid",
+			Status:       ArtistResolved,
+			SetlistUrl:   "https://comeback_kid",
+			TotalSongs:   17,
+			MatchedSongs: 14,
+		},
+		{Name: "Municipal Waste", Status: ArtistFailed, Err: setlistErr},
+	}
+	assert.Equal(t, expected, actual.Artists)
+}
+
+func TestJobIsNotCancelledWithRequestContext(t *testing.T) {
+	service := newServiceMock(testCreation(), nil)
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	ctx, cancel := context.WithCancel(testContext(userId))
+
+	job, _ := queue.Submit(ctx, testRequest(service))
+	cancel()
+	queue.Start()
+	defer queue.Stop()
+	waitForJob(t, queue, job.Id)
+
+	jobCtx := service.Calls[0].Arguments.Get(0).(context.Context)
+	assert.Nil(t, jobCtx.Err())
+	assert.Equal(t, userId, jobCtx.Value(types.ContextKey("user_id")))
+}
+
+func TestGetJobOnlyReturnsJobsOfUser(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	job, _ := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+
+	_, err := queue.GetJob(testContext("another_user"), job.Id)
+
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestGetJobReturnsErrorForUnknownJob(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+
+	_, err := queue.GetJob(testContext(userId), "unknown")
+
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestSubmitReturnsErrorWhenUserIdMissing(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+
+	_, err := queue.Submit(context.Background(), testRequest(newServiceMock(testCreation(), nil)))
+
+	assert.NotNil(t, err)
+}
+
+func TestSubmitReturnsErrorWhenQueueIsFull(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.SetCapacity(1)
+	service := newServiceMock(testCreation(), nil)
+
+	_, firstErr := queue.Submit(testContext(userId), testRequest(service))
+	_, secondErr := queue.Submit(testContext(userId), testRequest(service))
+
+	assert.Nil(t, firstErr)
+	assert.ErrorIs(t, secondErr, ErrQueueFull)
+}
+
+func TestSubmitReturnsErrorWhenQueueIsStopped(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.Start()
+	queue.Stop()
+
+	_, err := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+
+	assert.ErrorIs(t, err, ErrQueueStopped)
+}
+
+func TestFinishedJobsAreRemovedAfterRetention(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.SetRetention(time.Minute)
+	queue.now = func() time.Time { return now }
+	queue.Start()
+	defer queue.Stop()
+
+	job, _ := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+	waitForJob(t, queue, job.Id)
+	_, keptErr := queue.GetJob(testContext(userId), job.Id)
+	now = now.Add(2 * time.Minute)
+	_, removedErr := queue.GetJob(testContext(userId), job.Id)
+
+	assert.Nil(t, keptErr)
+	assert.ErrorIs(t, removedErr, ErrJobNotFound)
+}
+
+func progressServiceMock(events ...services.ProgressEvent) *playlistmocks.PlaylistServiceMock {
+	service := &playlistmocks.PlaylistServiceMock{}
+	service.On("CreatePlaylistWithArtists", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			progress := args.Get(3).(services.CreationOptions).Progress
+			for _, event := range events {
+				progress(event)
+			}
+		}).
+		Return(testCreation(), nil)
+	return service
+}
+
+func TestJobEventsEndWithCompletion(t *testing.T) {
+	setlistFound := services.ProgressEvent{Type: services.SetlistFound, ArtistIndex: 0, TotalSongs: 17}
+	artistFailed := services.ProgressEvent{Type: services.ArtistFailed, ArtistIndex: 1, Err: errors.New("test error")}
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.Start()
+	defer queue.Stop()
+
+	job, _ := queue.Submit(testContext(userId), testRequest(progressServiceMock(setlistFound, artistFailed)))
+	finishedJob := waitForJob(t, queue, job.Id)
+	actual, err := queue.GetJobEvents(testContext(userId), job.Id, 0)
+
+	expected := []JobEvent{
+		{Id: 1, Progress: setlistFound},
+		{Id: 2, Progress: artistFailed},
+		{Id: 3, Completion: &finishedJob},
+	}
+	assert.Nil(t, err)
+	assert.True(t, actual.Finished)
+	assert.Equal(t, expected, actual.Events)
+}
+
+func TestJobEventsAfterGivenEvent(t *testing.T) {
+	setlistFound := services.ProgressEvent{Type: services.SetlistFound, ArtistIndex: 0, TotalSongs: 17}
+	artistResolved := services.ProgressEvent{Type: services.ArtistResolved, ArtistIndex: 0, MatchedSongs: 14}
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	queue.Start()
+	defer queue.Stop()
+
+	job, _ := queue.Submit(testContext(userId), testRequest(progressServiceMock(setlistFound, artistResolved)))
+	waitForJob(t, queue, job.Id)
+	actual, err := queue.GetJobEvents(testContext(userId), job.Id, 1)
+	afterLast, afterLastErr := queue.GetJobEvents(testContext(userId), job.Id, 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, []int{actual.Events[0].Id, actual.Events[1].Id})
+	assert.Nil(t, afterLastErr)
+	assert.Empty(t, afterLast.Events)
+	assert.True(t, afterLast.Finished)
+}
+
+func TestJobEventsNotifyNewEvents(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	job, _ := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+
+	pending, err := queue.GetJobEvents(testContext(userId), job.Id, 0)
+	queue.Start()
+	defer queue.Stop()
+
+	assert.Nil(t, err)
+	assert.Empty(t, pending.Events)
+	assert.False(t, pending.Finished)
+	select {
+	case <-pending.Updated:
+	case <-time.After(time.Second):
+		t.Error("no new events were notified")
+	}
+}
+
+func TestGetJobEventsOnlyReturnsJobsOfUser(t *testing.T) {
+	queue := NewCreationJobQueue(logging.NoopLogger{})
+	job, _ := queue.Submit(testContext(userId), testRequest(newServiceMock(testCreation(), nil)))
+
+	_, err := queue.GetJobEvents(testContext("another_user"), job.Id, 0)
+
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
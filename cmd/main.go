@@ -2,27 +2,45 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	playlisthandler "festwrap/cmd/handler/playlist"
 	"festwrap/cmd/handler/search"
+	"festwrap/cmd/jobs"
 	"festwrap/cmd/middleware"
 	auth "festwrap/cmd/middleware/auth"
+	applemusicauth "festwrap/cmd/middleware/auth/applemusic"
 	spotifyauth "festwrap/cmd/middleware/auth/spotify"
+	youtubeauth "festwrap/cmd/middleware/auth/youtube"
+	"festwrap/cmd/refresh"
 	services "festwrap/cmd/services"
+	applemusicartists "festwrap/internal/artist/applemusic"
 	spotifyArtists "festwrap/internal/artist/spotify"
 	"festwrap/internal/event"
 	httpclient "festwrap/internal/http/client"
 	httpsender "festwrap/internal/http/sender"
 	"festwrap/internal/logging"
 	"festwrap/internal/messaging"
+	"festwrap/internal/playlist"
+	applemusicplaylists "festwrap/internal/playlist/applemusic"
+	"festwrap/internal/playlist/export"
 	spotifyplaylists "festwrap/internal/playlist/spotify"
+	youtubeplaylists "festwrap/internal/playlist/youtube"
+	"festwrap/internal/quota"
+	"festwrap/internal/setlist"
 	"festwrap/internal/setlist/setlistfm"
+	"festwrap/internal/song"
+	applemusicsongs "festwrap/internal/song/applemusic"
 	spotifysongs "festwrap/internal/song/spotify"
+	youtubesongs "festwrap/internal/song/youtube"
 	spotifyusers "festwrap/internal/user/spotify"
 
 	"cloud.google.com/go/pubsub"
@@ -44,6 +62,311 @@ func setupHTTPSender(config Config) httpsender.HTTPRequestSender {
 	return &sender
 }
 
+// Connects to the messaging backend. The returned function closes the connection
+func setupPublisher(config Config, logger logging.Logger) (messaging.Publisher, func()) {
+	switch config.MessagingBackend {
+	case kafkaBackend:
+		partitionKeys, err := messaging.ParsePartitionKeys(config.KafkaPartitionKeys)
+		if err != nil {
+			logger.Error(fmt.Sprintf("could not read Kafka partition keys: %v", err))
+			os.Exit(1)
+		}
+		kafkaPublisher, err := messaging.NewKafkaPublisher(
+			messaging.KafkaConfig{
+				Brokers:          messaging.ParseBrokers(config.KafkaBrokers),
+				ClientId:         config.KafkaClientId,
+				Acks:             messaging.KafkaAcks(config.KafkaAcks),
+				SecurityProtocol: config.KafkaSecurityProtocol,
+				SASLMechanism:    config.KafkaSASLMechanism,
+				SASLUsername:     config.KafkaSASLUsername,
+				SASLPassword:     config.KafkaSASLPassword,
+				TLSCAFile:        config.KafkaTLSCAFile,
+				PartitionKeys:    partitionKeys,
+			},
+			logger,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to initialize Kafka publisher: %s", err))
+			os.Exit(1)
+		}
+		expvar.Publish("kafka", expvar.Func(func() any { return kafkaPublisher.Metrics() }))
+		return kafkaPublisher, kafkaPublisher.Close
+	case fileBackend:
+		filePublisher, err := messaging.NewFilePublisher(config.MessagesFileDir)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to initialize file publisher: %s", err))
+			os.Exit(1)
+		}
+		filePublisher.SetMaxSize(int64(config.MessagesFileMaxSizeMB) * 1024 * 1024)
+		filePublisher.SetMaxBackups(config.MessagesFileMaxBackups)
+		return filePublisher, filePublisher.Close
+	case stdoutBackend:
+		// Logs are written to the standard output, so messages go to the standard error to be told apart
+		return messaging.NewWriterPublisher(os.Stderr), func() {}
+	default:
+		pubsubClient, err := pubsub.NewClient(context.Background(), config.PubsubProjectId)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to initialize pubsub client: %s", err))
+			os.Exit(1)
+		}
+		return messaging.NewPubsubPublisher(pubsubClient, logger), func() { pubsubClient.Close() }
+	}
+}
+
+// Notifiers shared by the playlist services of all providers
+type playlistNotifiers struct {
+	created event.Notifier[event.PlaylistCreatedEvent]
+	failed  event.Notifier[event.PlaylistCreationFailedEvent]
+	updated event.Notifier[event.PlaylistUpdatedEvent]
+	// Diagnostics of the setlists and songs that could not be found
+	setlistNotFound event.Notifier[event.SetlistNotFoundEvent]
+	songNotFound    event.Notifier[event.SongNotFoundEvent]
+	// Delivers the events still queued in the background
+	stop    func()
+	metrics func() map[event.EventType]event.NotifierMetrics
+	// Nil when there are no webhooks
+	webhooks *event.WebhookDispatcher
+}
+
+type metricsNotifier[T event.Event] interface {
+	event.Notifier[T]
+	Metrics() event.NotifierMetrics
+}
+
+// Reads the webhooks receiving the events and starts delivering them, or returns nil when there is none
+func setupWebhooks(config Config, logger logging.Logger) *event.WebhookDispatcher {
+	if config.WebhooksFile == "" {
+		return nil
+	}
+
+	endpoints, err := event.ReadWebhookEndpoints(config.WebhooksFile)
+	if err != nil {
+		logger.Error(fmt.Sprintf("could not read webhooks: %v", err))
+		os.Exit(1)
+	}
+	baseHttpClient := httpclient.NewBaseHTTPClient(&http.Client{})
+	webhooks := event.NewWebhookDispatcher(&baseHttpClient, endpoints, logger)
+	webhooks.SetMaxAttempts(config.WebhookMaxAttempts)
+	webhooks.SetDisableAfter(config.WebhookDisableAfter)
+	webhooks.SetTimeout(time.Duration(config.WebhookTimeoutMs) * time.Millisecond)
+	webhooks.SetQueue(config.WebhookQueueSize, config.WebhookWorkers)
+	webhooks.Start()
+	return webhooks
+}
+
+// Publishes the events to the topic and webhooks, if any, in the background unless the event queue is disabled
+func setupNotifier[T event.Event](
+	config Config,
+	publisher messaging.Publisher,
+	topic string,
+	encodings map[string]event.Encoding,
+	webhooks *event.WebhookDispatcher,
+	logger logging.Logger,
+) (metricsNotifier[T], func()) {
+	notifier := event.NewBaseNotifier[T]()
+	notifier.SetObserverTimeout(time.Duration(config.EventObserverTimeoutMs) * time.Millisecond)
+	notifier.SetLogger(logger)
+	if topic != "" {
+		publishObserver := event.NewPublishEventObserver[T](publisher, topic)
+		// Storing events in the outbox is quick, and lets them be stored before the observer returns
+		if config.EventQueueSize > 0 || config.OutboxDir != "" {
+			publishObserver.WithSynchronousPublish()
+		} else {
+			// Events are published in the background, so their errors are reported to the notifier afterwards
+			publishObserver.WithErrorHandler(notifier.ReportObserverError)
+		}
+		if encoding, ok := encodings[topic]; ok {
+			publishObserver.WithEncoding(encoding, config.CloudEventsSource)
+		}
+		notifier.AddObserver(publishObserver)
+	}
+	// Webhooks are delivered in the background, so their retries do not hold the rest of the events
+	if webhooks != nil {
+		notifier.AddObserver(event.NewWebhookObserver[T](webhooks))
+	}
+
+	// The outbox already stores events quickly and publishes them in the background, so the queue is not needed
+	if config.EventQueueSize <= 0 || config.OutboxDir != "" {
+		return notifier, func() {}
+	}
+	asyncNotifier := event.NewAsyncNotifier(
+		notifier, config.EventQueueSize, event.OverflowPolicy(config.EventOverflowPolicy),
+	)
+	asyncNotifier.SetLogger(logger)
+	asyncNotifier.Start()
+	return asyncNotifier, asyncNotifier.Stop
+}
+
+func setupPlaylistNotifiers(config Config, publisher messaging.Publisher, logger logging.Logger) playlistNotifiers {
+	if !event.IsValidOverflowPolicy(event.OverflowPolicy(config.EventOverflowPolicy)) {
+		logger.Error(fmt.Sprintf("unsupported event overflow policy %s", config.EventOverflowPolicy))
+		os.Exit(1)
+	}
+	encodings, err := event.ParseTopicEncodings(config.EventEncodings)
+	if err != nil {
+		logger.Error(fmt.Sprintf("could not read event encodings: %v", err))
+		os.Exit(1)
+	}
+	webhooks := setupWebhooks(config, logger)
+
+	createNotifier, stopCreated := setupNotifier[event.PlaylistCreatedEvent](
+		config, publisher, config.CreatePlaylistTopic, encodings, webhooks, logger,
+	)
+	// Failure, update and diagnostics events are only published when a topic is provided
+	failureNotifier, stopFailed := setupNotifier[event.PlaylistCreationFailedEvent](
+		config, publisher, config.CreationFailedTopic, encodings, webhooks, logger,
+	)
+	updateNotifier, stopUpdated := setupNotifier[event.PlaylistUpdatedEvent](
+		config, publisher, config.PlaylistUpdatedTopic, encodings, webhooks, logger,
+	)
+	setlistNotFoundNotifier, stopSetlistNotFound := setupNotifier[event.SetlistNotFoundEvent](
+		config, publisher, config.SetlistNotFoundTopic, encodings, webhooks, logger,
+	)
+	songNotFoundNotifier, stopSongNotFound := setupNotifier[event.SongNotFoundEvent](
+		config, publisher, config.SongNotFoundTopic, encodings, webhooks, logger,
+	)
+	return playlistNotifiers{
+		created:         createNotifier,
+		failed:          failureNotifier,
+		updated:         updateNotifier,
+		setlistNotFound: setlistNotFoundNotifier,
+		songNotFound:    songNotFoundNotifier,
+		stop: func() {
+			stopCreated()
+			stopFailed()
+			stopUpdated()
+			stopSetlistNotFound()
+			stopSongNotFound()
+			// Notifiers may still queue webhooks while stopping, so the webhooks are stopped last
+			if webhooks != nil {
+				webhooks.Stop()
+			}
+		},
+		metrics: func() map[event.EventType]event.NotifierMetrics {
+			return map[event.EventType]event.NotifierMetrics{
+				event.PlaylistCreated:        createNotifier.Metrics(),
+				event.PlaylistCreationFailed: failureNotifier.Metrics(),
+				event.PlaylistUpdated:        updateNotifier.Metrics(),
+				event.SetlistNotFound:        setlistNotFoundNotifier.Metrics(),
+				event.SongNotFound:           songNotFoundNotifier.Metrics(),
+			}
+		},
+		webhooks: webhooks,
+	}
+}
+
+func setupPlaylistService(
+	config Config,
+	playlistRepository playlist.PlaylistRepository,
+	setlistRepository setlist.SetlistRepository,
+	songRepository song.SongRepository,
+	notifiers playlistNotifiers,
+	logger logging.Logger,
+) services.BasePlaylistService {
+	playlistService := services.NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logger)
+	playlistService.SetAddSetlistSleep(config.AddSetlistSleepMs)
+	playlistService.SetMaxSongWorkers(config.MaxSongWorkers)
+	playlistService.SetMaxGlobalSongWorkers(config.MaxGlobalSongWorkers)
+	playlistService.SetPlaylistCreateNotifier(notifiers.created)
+	playlistService.SetPlaylistFailureNotifier(notifiers.failed)
+	playlistService.SetPlaylistUpdateNotifier(notifiers.updated)
+	playlistService.SetSetlistNotFoundNotifier(notifiers.setlistNotFound)
+	playlistService.SetSongNotFoundNotifier(notifiers.songNotFound)
+	return playlistService
+}
+
+// Enables Apple Music as a provider for artist searches, playlist creation and previews
+func setupAppleMusic(
+	config Config,
+	router *mux.Router,
+	createPlaylistHandler *playlisthandler.CreatePlaylistHandler,
+	previewPlaylistHandler *playlisthandler.PreviewPlaylistHandler,
+	httpSender httpsender.HTTPRequestSender,
+	setlistRepository setlist.SetlistRepository,
+	notifiers playlistNotifiers,
+	logger logging.Logger,
+) {
+	privateKey, err := os.ReadFile(config.AppleMusicPrivateKeyPath)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to read Apple Music private key: %s", err))
+		os.Exit(1)
+	}
+	tokenGenerator, err := applemusicauth.NewAppleMusicTokenGenerator(
+		config.AppleMusicTeamId, config.AppleMusicKeyId, privateKey,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to initialize Apple Music token generator: %s", err))
+		os.Exit(1)
+	}
+	// Tokens are only obtained for the requests going to Apple Music
+	developerTokenExtractor := auth.NewAuthTokenExtractor(&tokenGenerator, logger)
+	developerTokenExtractor.SetTokenKey("apple_music_token")
+	userTokenExtractor := middleware.NewMusicUserTokenExtractor()
+
+	// Artist searches go to Apple Music when requested through the provider query parameter
+	artistRepository := applemusicartists.NewAppleMusicArtistRepository(httpSender)
+	artistRepository.SetStorefront(config.AppleMusicStorefront)
+	artistSearcher := search.NewFunctionSearcher(artistRepository.SearchArtist)
+	searchArtistsHandler := search.NewSearchHandler(&artistSearcher, "artists", logger)
+	searchArtistsHandler.SetMaxNameLength(config.MaxArtistNameLength)
+	router.Handle("/artists/search", developerTokenExtractor.Middleware(http.HandlerFunc(searchArtistsHandler.ServeHTTP))).
+		Queries("provider", string(playlisthandler.AppleMusicProvider)).
+		Methods(http.MethodGet)
+
+	playlistRepository := applemusicplaylists.NewAppleMusicPlaylistRepository(httpSender)
+	songRepository := applemusicsongs.NewAppleMusicSongRepository(httpSender)
+	songRepository.SetStorefront(config.AppleMusicStorefront)
+	playlistService := setupPlaylistService(
+		config, &playlistRepository, setlistRepository, songRepository, notifiers, logger,
+	)
+	playlistService.SetPlaylistType(event.PLAYLIST_TYPE_APPLE_MUSIC)
+	createPlaylistHandler.SetProviderPlaylistService(playlisthandler.AppleMusicProvider, &playlistService)
+	createPlaylistHandler.SetProviderMiddlewares(
+		playlisthandler.AppleMusicProvider, developerTokenExtractor.Middleware, userTokenExtractor.Middleware,
+	)
+	previewPlaylistHandler.SetProviderPlaylistService(playlisthandler.AppleMusicProvider, &playlistService)
+	previewPlaylistHandler.SetProviderMiddlewares(
+		playlisthandler.AppleMusicProvider, developerTokenExtractor.Middleware, userTokenExtractor.Middleware,
+	)
+}
+
+// Enables YouTube Music as a provider for playlist creation and previews
+func setupYouTubeMusic(
+	config Config,
+	createPlaylistHandler *playlisthandler.CreatePlaylistHandler,
+	previewPlaylistHandler *playlisthandler.PreviewPlaylistHandler,
+	httpSender httpsender.HTTPRequestSender,
+	setlistRepository setlist.SetlistRepository,
+	notifiers playlistNotifiers,
+	logger logging.Logger,
+) {
+	authClient := youtubeauth.NewYouTubeAuthClient(
+		httpSender, config.YouTubeRefreshToken, config.YouTubeClientId, config.YouTubeClientSecret,
+	)
+	// Tokens are only obtained for the requests going to YouTube, so Google failures do not affect the rest
+	tokenExtractor := auth.NewAuthTokenExtractor(&authClient, logger)
+	tokenExtractor.SetTokenKey("youtube_token")
+
+	// YouTube quota is shared by all requests and resets at midnight Pacific Time
+	quotaTracker := quota.NewUnitsTracker(config.YouTubeDailyQuota)
+	if location, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		quotaTracker.SetLocation(location)
+	} else {
+		logger.Warn(fmt.Sprintf("could not load Pacific Time location, resetting YouTube quota in UTC: %v", err))
+	}
+
+	playlistRepository := youtubeplaylists.NewYouTubePlaylistRepository(httpSender, quotaTracker)
+	songRepository := youtubesongs.NewYouTubeSongRepository(httpSender, quotaTracker)
+	playlistService := setupPlaylistService(
+		config, &playlistRepository, setlistRepository, songRepository, notifiers, logger,
+	)
+	playlistService.SetPlaylistType(event.PLAYLIST_TYPE_YOUTUBE_MUSIC)
+	createPlaylistHandler.SetProviderPlaylistService(playlisthandler.YouTubeMusicProvider, &playlistService)
+	createPlaylistHandler.SetProviderMiddlewares(playlisthandler.YouTubeMusicProvider, tokenExtractor.Middleware)
+	previewPlaylistHandler.SetProviderPlaylistService(playlisthandler.YouTubeMusicProvider, &playlistService)
+	previewPlaylistHandler.SetProviderMiddlewares(playlisthandler.YouTubeMusicProvider, tokenExtractor.Middleware)
+}
+
 func main() {
 	config := ReadConfig()
 	logger := setupLogger()
@@ -55,22 +378,33 @@ func main() {
 	)
 	mux.Use(auth.NewAuthTokenExtractor(&spotifyAuthClient, logger).Middleware)
 
-	// Configure pubsub client
-	ctx := context.Background()
-	pubsubClient, err := pubsub.NewClient(ctx, config.PubsubProjectId)
-	if err != nil {
-		logger.Error(fmt.Sprintf("failed to initialize pubsub client: %s", err))
-		os.Exit(1)
-	}
-	defer pubsubClient.Close()
-	publisher := messaging.NewPubsubPublisher(pubsubClient, logger)
+	// Configure the messaging backend publishing the events
+	backendPublisher, closePublisher := setupPublisher(config, logger)
+	defer closePublisher()
+	publisher := backendPublisher
 
-	// Set search artist endpoint
-	artistRepository := spotifyArtists.NewSpotifyArtistRepository(httpSender)
-	artistSearcher := search.NewFunctionSearcher(artistRepository.SearchArtist)
-	searchArtistsHandler := search.NewSearchHandler(&artistSearcher, "artists", logger)
-	searchArtistsHandler.SetMaxNameLength(config.MaxArtistNameLength)
-	mux.HandleFunc("/artists/search", searchArtistsHandler.ServeHTTP).Methods(http.MethodGet)
+	// Store events in the outbox so they are published even if the backend is down or the app restarts
+	if config.OutboxDir != "" {
+		outboxStore, err := messaging.NewFileOutboxStore(config.OutboxDir)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to initialize event outbox: %s", err))
+			os.Exit(1)
+		}
+		outboxRelay := messaging.NewOutboxRelay(outboxStore, backendPublisher, logger)
+		outboxRelay.SetMaxAttempts(config.OutboxMaxAttempts)
+		outboxRelay.SetPollInterval(time.Duration(config.OutboxPollIntervalMs) * time.Millisecond)
+		outboxRelay.Start()
+		defer outboxRelay.Stop()
+		publisher = messaging.NewOutboxPublisher(outboxStore)
+		expvar.Publish("outbox", expvar.Func(func() any {
+			metrics, err := outboxRelay.Metrics()
+			if err != nil {
+				logger.Warn(fmt.Sprintf("could not read outbox metrics: %v", err))
+				return nil
+			}
+			return metrics
+		}))
+	}
 
 	// Initialize playlist service
 	playlistRepository := spotifyplaylists.NewSpotifyPlaylistRepository(httpSender)
@@ -78,19 +412,14 @@ func main() {
 	setlistRepository.SetMaxPages(config.MaxSetlistFMNumSearchPages)
 	setlistRepository.SetNextPageSleep(config.NextPageSleepMs)
 	songRepository := spotifysongs.NewSpotifySongRepository(httpSender)
-	playlistService := services.NewBasePlaylistService(
-		&playlistRepository,
-		setlistRepository,
-		songRepository,
-		logger,
-	)
-	playlistService.SetAddSetlistSleep(config.AddSetlistSleepMs)
 
 	// Configure service to publish creation events
-	createNotifier := event.NewBaseNotifier[event.PlaylistCreatedEvent]()
-	publishObserver := event.NewPublishEventObserver[event.PlaylistCreatedEvent](publisher, config.CreatePlaylistTopic)
-	createNotifier.AddObserver(publishObserver)
-	playlistService.SetPlaylistCreateNotifier(createNotifier)
+	notifiers := setupPlaylistNotifiers(config, publisher, logger)
+	defer notifiers.stop()
+
+	playlistService := setupPlaylistService(
+		config, &playlistRepository, setlistRepository, songRepository, notifiers, logger,
+	)
 
 	// Set create new playlist endpoint
 	newPlaylistUpdateHandler := playlisthandler.NewCreatePlaylistHandler(&playlistService, logger)
@@ -98,18 +427,147 @@ func main() {
 	newPlaylistUpdateHandler.SetMaxArtistNameLength(config.MaxArtistNameLength)
 	userRepository := spotifyusers.NewSpotifyUserRepository(httpSender)
 	userIdExtractor := middleware.NewUserIdExtractor(userRepository, logger)
+	idempotencyKeys := middleware.NewIdempotencyKeys(logger)
+	idempotencyKeys.SetTTL(time.Duration(config.IdempotencyKeyTTLMin) * time.Minute)
+	idempotencyKeys.SetWaitTimeout(time.Duration(config.IdempotencyWaitSeconds) * time.Second)
 	mux.Handle(
 		"/playlists",
-		userIdExtractor.Middleware(http.HandlerFunc(newPlaylistUpdateHandler.ServeHTTP))).Methods(http.MethodPost)
+		userIdExtractor.Middleware(idempotencyKeys.Middleware(http.HandlerFunc(newPlaylistUpdateHandler.ServeHTTP))),
+	).Methods(http.MethodPost)
+
+	// Create playlists in the background and set the endpoint to poll them
+	if config.CreationJobWorkers > 0 {
+		jobQueue := jobs.NewCreationJobQueue(logger)
+		jobQueue.SetWorkers(config.CreationJobWorkers)
+		jobQueue.SetCapacity(config.CreationJobQueueSize)
+		jobQueue.SetRetention(time.Duration(config.CreationJobRetentionMin) * time.Minute)
+		jobQueue.Start()
+		defer jobQueue.Stop()
+		newPlaylistUpdateHandler.SetJobQueue(jobQueue)
+
+		getPlaylistJobHandler := playlisthandler.NewGetPlaylistJobHandler(jobQueue, logger)
+		mux.Handle(
+			"/playlists/jobs/{id}",
+			userIdExtractor.Middleware(http.HandlerFunc(getPlaylistJobHandler.ServeHTTP))).Methods(http.MethodGet)
+
+		streamPlaylistJobHandler := playlisthandler.NewStreamPlaylistJobHandler(jobQueue, logger)
+		mux.Handle(
+			"/playlists/jobs/{id}/events",
+			userIdExtractor.Middleware(http.HandlerFunc(streamPlaylistJobHandler.ServeHTTP))).Methods(http.MethodGet)
+	}
+
+	// Set preview playlist endpoint
+	previewPlaylistHandler := playlisthandler.NewPreviewPlaylistHandler(&playlistService, logger)
+	previewPlaylistHandler.SetMaxArtists(config.MaxCreateArtists)
+	previewPlaylistHandler.SetMaxArtistNameLength(config.MaxArtistNameLength)
+	mux.HandleFunc("/playlists/preview", previewPlaylistHandler.ServeHTTP).Methods(http.MethodPost)
+
+	if config.AppleMusicTeamId != "" {
+		setupAppleMusic(
+			config, mux, &newPlaylistUpdateHandler, &previewPlaylistHandler,
+			httpSender, setlistRepository, notifiers, logger,
+		)
+	}
+
+	if config.YouTubeClientId != "" {
+		setupYouTubeMusic(
+			config, &newPlaylistUpdateHandler, &previewPlaylistHandler,
+			httpSender, setlistRepository, notifiers, logger,
+		)
+	}
+
+	// Set search artist endpoint, after the provider specific ones so they take precedence
+	artistRepository := spotifyArtists.NewSpotifyArtistRepository(httpSender)
+	artistSearcher := search.NewFunctionSearcher(artistRepository.SearchArtist)
+	searchArtistsHandler := search.NewSearchHandler(&artistSearcher, "artists", logger)
+	searchArtistsHandler.SetMaxNameLength(config.MaxArtistNameLength)
+	mux.HandleFunc("/artists/search", searchArtistsHandler.ServeHTTP).Methods(http.MethodGet)
+
+	// Set export playlist endpoint, which resolves songs in Spotify without storing the playlist nor publishing events
+	exportRepository := export.NewExportPlaylistRepository()
+	exportService := services.NewBasePlaylistService(exportRepository, setlistRepository, songRepository, logger)
+	exportService.SetAddSetlistSleep(config.AddSetlistSleepMs)
+	exportService.SetMaxSongWorkers(config.MaxSongWorkers)
+	exportService.SetMaxGlobalSongWorkers(config.MaxGlobalSongWorkers)
+	exportPlaylistHandler := playlisthandler.NewExportPlaylistHandler(&exportService, exportRepository, logger)
+	exportPlaylistHandler.SetMaxArtists(config.MaxCreateArtists)
+	exportPlaylistHandler.SetMaxArtistNameLength(config.MaxArtistNameLength)
+	mux.HandleFunc("/playlists/export", exportPlaylistHandler.ServeHTTP).Methods(http.MethodPost)
+
+	// Set add artists to existing playlist endpoint
+	addArtistsHandler := playlisthandler.NewAddArtistsHandler(&playlistService, logger)
+	addArtistsHandler.SetMaxArtists(config.MaxCreateArtists)
+	addArtistsHandler.SetMaxArtistNameLength(config.MaxArtistNameLength)
+	mux.Handle(
+		"/playlists/{id}/artists",
+		userIdExtractor.Middleware(http.HandlerFunc(addArtistsHandler.ServeHTTP))).Methods(http.MethodPost)
+
+	// Refresh scheduled playlists in the background with the latest setlists and set the endpoints to manage them
+	refreshScheduler := refresh.NewRefreshScheduler(&playlistService, &spotifyAuthClient, logger)
+	refreshScheduler.SetInterval(time.Duration(config.RefreshIntervalMin) * time.Minute)
+	refreshScheduler.SetCheckInterval(time.Duration(config.RefreshCheckIntervalSec) * time.Second)
+	if config.RefreshDir != "" {
+		refreshStore, err := refresh.NewFileRefreshStore(config.RefreshDir)
+		if err == nil {
+			err = refreshScheduler.SetStore(refreshStore)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to initialize refresh store: %s", err))
+			os.Exit(1)
+		}
+	}
+	refreshScheduler.Start()
+	defer refreshScheduler.Stop()
+
+	schedulePlaylistRefreshHandler := playlisthandler.NewSchedulePlaylistRefreshHandler(refreshScheduler, logger)
+	schedulePlaylistRefreshHandler.SetMaxArtists(config.MaxCreateArtists)
+	schedulePlaylistRefreshHandler.SetMaxArtistNameLength(config.MaxArtistNameLength)
+	getPlaylistRefreshHandler := playlisthandler.NewGetPlaylistRefreshHandler(refreshScheduler, logger)
+	cancelPlaylistRefreshHandler := playlisthandler.NewCancelPlaylistRefreshHandler(refreshScheduler, logger)
+	mux.Handle(
+		"/playlists/{id}/refresh",
+		userIdExtractor.Middleware(http.HandlerFunc(schedulePlaylistRefreshHandler.ServeHTTP))).Methods(http.MethodPut)
+	mux.Handle(
+		"/playlists/{id}/refresh",
+		userIdExtractor.Middleware(http.HandlerFunc(getPlaylistRefreshHandler.ServeHTTP))).Methods(http.MethodGet)
+	mux.Handle(
+		"/playlists/{id}/refresh",
+		userIdExtractor.Middleware(http.HandlerFunc(cancelPlaylistRefreshHandler.ServeHTTP))).Methods(http.MethodDelete)
+
+	// Metrics are served apart from the router, so reading them does not need Spotify tokens
+	expvar.Publish("notifiers", expvar.Func(func() any { return notifiers.metrics() }))
+	if notifiers.webhooks != nil {
+		expvar.Publish("webhooks", expvar.Func(func() any { return notifiers.webhooks.Metrics() }))
+	}
+	handler := http.NewServeMux()
+	handler.Handle("/metrics", expvar.Handler())
+	handler.Handle("/", mux)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", config.Port),
-		Handler: mux,
+		Handler: handler,
 	}
 
-	logger.Info(fmt.Sprintf("Starting server at port %s", config.Port))
-	err = server.ListenAndServe()
-	if err != nil {
+	// Stop on termination signals, letting the requests in progress and the deferred stops finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("Starting server at port %s", config.Port))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		logger.Error(fmt.Sprintf("could not start server %v", err))
+	case <-ctx.Done():
+		logger.Info("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(
+			context.Background(), time.Duration(config.ShutdownTimeoutSec)*time.Second,
+		)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(fmt.Sprintf("could not shut down server gracefully: %v", err))
+		}
 	}
 }
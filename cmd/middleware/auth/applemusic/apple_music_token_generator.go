@@ -0,0 +1,128 @@
+package applemusic
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Apple does not accept developer tokens lasting more than 6 months
+const maxTokenDuration = 180 * 24 * time.Hour
+
+type developerTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+type developerTokenClaims struct {
+	Issuer         string `json:"iss"`
+	IssuedAt       int64  `json:"iat"`
+	ExpirationTime int64  `json:"exp"`
+}
+
+// Generates the developer tokens used to authenticate against the Apple Music API.
+// Tokens are JWTs signed with the MusicKit private key and are reused until they are about to expire
+type AppleMusicTokenGenerator struct {
+	teamId         string
+	keyId          string
+	privateKey     *ecdsa.PrivateKey
+	tokenDuration  time.Duration
+	expirationTime time.Time
+	latestToken    string
+}
+
+func NewAppleMusicTokenGenerator(teamId string, keyId string, privateKeyPem []byte) (AppleMusicTokenGenerator, error) {
+	privateKey, err := parsePrivateKey(privateKeyPem)
+	if err != nil {
+		return AppleMusicTokenGenerator{}, err
+	}
+	return AppleMusicTokenGenerator{
+		teamId:         teamId,
+		keyId:          keyId,
+		privateKey:     privateKey,
+		tokenDuration:  12 * time.Hour,
+		expirationTime: time.Now(),
+	}, nil
+}
+
+func (g *AppleMusicTokenGenerator) GetAccessToken() (string, error) {
+	now := time.Now()
+	// Leave some margin so the token does not expire while being used
+	if now.Add(time.Minute).Before(g.expirationTime) {
+		return g.latestToken, nil
+	}
+
+	expirationTime := now.Add(g.tokenDuration)
+	token, err := g.signToken(now, expirationTime)
+	if err != nil {
+		return "", fmt.Errorf("could not sign developer token: %v", err)
+	}
+
+	g.latestToken = token
+	g.expirationTime = expirationTime
+	return g.latestToken, nil
+}
+
+func (g *AppleMusicTokenGenerator) SetTokenDuration(duration time.Duration) {
+	g.tokenDuration = min(duration, maxTokenDuration)
+}
+
+func (g *AppleMusicTokenGenerator) signToken(issuedAt time.Time, expirationTime time.Time) (string, error) {
+	header, err := encodeTokenSegment(developerTokenHeader{Algorithm: "ES256", KeyId: g.keyId})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := encodeTokenSegment(
+		developerTokenClaims{Issuer: g.teamId, IssuedAt: issuedAt.Unix(), ExpirationTime: expirationTime.Unix()},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := header + "." + claims
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, g.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS expects the signature as the concatenation of both integers, each padded to 32 bytes
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeTokenSegment(value any) (string, error) {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(serialized), nil
+}
+
+func parsePrivateKey(privateKeyPem []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPem)
+	if block == nil {
+		return nil, errors.New("could not decode PEM private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %v", err)
+	}
+
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an ECDSA key")
+	}
+	return ecdsaKey, nil
+}
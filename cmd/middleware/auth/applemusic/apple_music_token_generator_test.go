@@ -0,0 +1,106 @@
+package applemusic
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	teamId = "someTeam"
+	keyId  = "someKey"
+)
+
+func generatePrivateKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate private key: %v", err)
+	}
+	encoded, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("could not encode private key: %v", err)
+	}
+	return privateKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encoded})
+}
+
+func decodeSegment[T any](t *testing.T, segment string) T {
+	t.Helper()
+	var result T
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		t.Fatalf("could not decode token segment: %v", err)
+	}
+	if err = json.Unmarshal(decoded, &result); err != nil {
+		t.Fatalf("could not deserialize token segment: %v", err)
+	}
+	return result
+}
+
+func tokenGenerator(t *testing.T) (AppleMusicTokenGenerator, *ecdsa.PrivateKey) {
+	t.Helper()
+	privateKey, privateKeyPem := generatePrivateKey(t)
+	generator, err := NewAppleMusicTokenGenerator(teamId, keyId, privateKeyPem)
+	if err != nil {
+		t.Fatalf("could not create token generator: %v", err)
+	}
+	return generator, privateKey
+}
+
+func TestNewAppleMusicTokenGeneratorReturnsErrorOnInvalidKey(t *testing.T) {
+	_, err := NewAppleMusicTokenGenerator(teamId, keyId, []byte("some invalid key"))
+
+	assert.NotNil(t, err)
+}
+
+func TestGetAccessTokenReturnsSignedDeveloperToken(t *testing.T) {
+	generator, privateKey := tokenGenerator(t)
+
+	token, err := generator.GetAccessToken()
+
+	assert.Nil(t, err)
+	segments := strings.Split(token, ".")
+	assert.Len(t, segments, 3)
+	header := decodeSegment[developerTokenHeader](t, segments[0])
+	assert.Equal(t, developerTokenHeader{Algorithm: "ES256", KeyId: keyId}, header)
+	claims := decodeSegment[developerTokenClaims](t, segments[1])
+	assert.Equal(t, teamId, claims.Issuer)
+	assert.Equal(t, int64(12*time.Hour/time.Second), claims.ExpirationTime-claims.IssuedAt)
+	signature, _ := base64.RawURLEncoding.DecodeString(segments[2])
+	digest := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&privateKey.PublicKey, digest[:], r, s))
+}
+
+func TestGetAccessTokenReturnsCachedTokenWhenNotExpired(t *testing.T) {
+	generator, _ := tokenGenerator(t)
+	previousToken, _ := generator.GetAccessToken()
+
+	nextToken, err := generator.GetAccessToken()
+
+	assert.Nil(t, err)
+	assert.Equal(t, previousToken, nextToken)
+}
+
+func TestGetAccessTokenReturnsNewTokenWhenAboutToExpire(t *testing.T) {
+	generator, _ := tokenGenerator(t)
+	generator.SetTokenDuration(30 * time.Second)
+	previousToken, _ := generator.GetAccessToken()
+
+	nextToken, err := generator.GetAccessToken()
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, previousToken, nextToken)
+}
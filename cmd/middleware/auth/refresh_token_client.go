@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/serialization"
+)
+
+// Where the client credentials are sent when requesting access tokens
+type CredentialsPlacement int
+
+const (
+	// Client id in the query and both credentials in a basic authorization header
+	BasicAuthCredentials CredentialsPlacement = iota
+	// Both credentials in the form body, next to the refresh token
+	FormCredentials
+)
+
+type AccessTokenInfo struct {
+	AccessToken           string `json:"access_token"`
+	ExpirationTimeSeconds int    `json:"expires_in"`
+}
+
+// Obtains OAuth access tokens from the refresh token of an account, reusing them until they expire
+type RefreshTokenClient struct {
+	sender                  httpsender.HTTPRequestSender
+	accessTokenDeserializer serialization.Deserializer[AccessTokenInfo]
+	clientId                string
+	clientSecret            string
+	host                    string
+	credentialsPlacement    CredentialsPlacement
+	refreshToken            string
+	expirationTime          time.Time
+	latestAccessToken       string
+}
+
+// The host includes the path of the token endpoint, such as accounts.spotify.com/api/token
+func NewRefreshTokenClient(
+	sender httpsender.HTTPRequestSender,
+	host string,
+	refreshToken string,
+	clientId string,
+	clientSecret string,
+) RefreshTokenClient {
+	deserializer := serialization.NewJsonDeserializer[AccessTokenInfo]()
+	return RefreshTokenClient{
+		sender:                  sender,
+		accessTokenDeserializer: &deserializer,
+		clientId:                clientId,
+		clientSecret:            clientSecret,
+		refreshToken:            refreshToken,
+		expirationTime:          time.Now(),
+		host:                    host,
+		credentialsPlacement:    BasicAuthCredentials,
+	}
+}
+
+func (c *RefreshTokenClient) GetAccessToken() (string, error) {
+	now := time.Now()
+	if now.Before(c.expirationTime) {
+		return c.latestAccessToken, nil
+	}
+
+	tokenInfo, err := c.requestNewAccessToken()
+	if err != nil {
+		return "", fmt.Errorf("could not request new access token: %v", err)
+	}
+
+	c.latestAccessToken = tokenInfo.AccessToken
+	newExpirationTime := time.Now().Add(time.Second * time.Duration(tokenInfo.ExpirationTimeSeconds))
+	c.expirationTime = newExpirationTime
+	return c.latestAccessToken, nil
+}
+
+func (c *RefreshTokenClient) SetCredentialsPlacement(placement CredentialsPlacement) {
+	c.credentialsPlacement = placement
+}
+
+func (c RefreshTokenClient) requestNewAccessToken() (AccessTokenInfo, error) {
+	var accessTokenInfo AccessTokenInfo
+	responseBody, err := c.sender.Send(c.buildAccessTokenOpts())
+	if err != nil {
+		return accessTokenInfo, fmt.Errorf("error requesting access token: %v", err)
+	}
+
+	err = c.accessTokenDeserializer.Deserialize(*responseBody, &accessTokenInfo)
+	if err != nil {
+		return accessTokenInfo, fmt.Errorf("could not deserialize access token response")
+	}
+
+	return accessTokenInfo, nil
+}
+
+func (c RefreshTokenClient) buildAccessTokenOpts() httpsender.HTTPRequestOptions {
+	params := url.Values{}
+	params.Set("grant_type", "refresh_token")
+	params.Set("refresh_token", c.refreshToken)
+	params.Set("client_id", c.clientId)
+	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+
+	if c.credentialsPlacement == FormCredentials {
+		params.Set("client_secret", c.clientSecret)
+		accessTokenOpts := httpsender.NewHTTPRequestOptions(fmt.Sprintf("https://%s", c.host), httpsender.POST, http.StatusOK)
+		accessTokenOpts.SetHeaders(headers)
+		accessTokenOpts.SetBody([]byte(params.Encode()))
+		return accessTokenOpts
+	}
+
+	url := fmt.Sprintf("https://%s?%s", c.host, params.Encode())
+	accessTokenOpts := httpsender.NewHTTPRequestOptions(url, httpsender.POST, http.StatusOK)
+	authCode := base64.StdEncoding.EncodeToString([]byte(c.clientId + ":" + c.clientSecret))
+	headers["Authorization"] = "Basic " + authCode
+	accessTokenOpts.SetHeaders(headers)
+	return accessTokenOpts
+}
@@ -0,0 +1,19 @@
+package youtube
+
+import (
+	"festwrap/cmd/middleware/auth"
+	httpsender "festwrap/internal/http/sender"
+)
+
+// Obtains OAuth access tokens from Google by using the refresh token of the account owning the playlists. Google
+// expects the client credentials in the form body
+func NewYouTubeAuthClient(
+	sender httpsender.HTTPRequestSender,
+	refreshToken string,
+	clientId string,
+	clientSecret string,
+) auth.RefreshTokenClient {
+	client := auth.NewRefreshTokenClient(sender, "oauth2.googleapis.com/token", refreshToken, clientId, clientSecret)
+	client.SetCredentialsPlacement(auth.FormCredentials)
+	return client
+}
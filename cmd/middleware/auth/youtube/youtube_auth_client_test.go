@@ -0,0 +1,78 @@
+package youtube
+
+import (
+	"net/http"
+	"testing"
+
+	httpsender "festwrap/internal/http/sender"
+	httpsendermocks "festwrap/internal/http/sender/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	refreshToken        = "cached_token"
+	clientId            = "some_client_id"
+	clientSecret        = "some_client_secret"
+	authResponse        = `{"access_token": "new_token", "expires_in": 3599}`
+	authExpiredResponse = `{"access_token": "another_token", "expires_in": 0}`
+)
+
+func expectedSenderArgs() httpsender.HTTPRequestOptions {
+	opts := httpsender.NewHTTPRequestOptions("https://oauth2.googleapis.com/token", httpsender.POST, http.StatusOK)
+	opts.SetHeaders(map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	opts.SetBody([]byte(
+		"client_id=some_client_id&client_secret=some_client_secret&grant_type=refresh_token&refresh_token=cached_token",
+	))
+	return opts
+}
+
+func createSender(response string) *httpsendermocks.HTTPSenderMock {
+	sender := httpsendermocks.HTTPSenderMock{}
+	responseBytes := []byte(response)
+	sender.On("Send", expectedSenderArgs()).Return(&responseBytes, nil)
+	return &sender
+}
+
+func TestAccessTokenReturnedFromSender(t *testing.T) {
+	sender := createSender(authResponse)
+	client := NewYouTubeAuthClient(sender, refreshToken, clientId, clientSecret)
+
+	token, err := client.GetAccessToken()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "new_token", token)
+	sender.AssertExpectations(t)
+}
+
+func TestAccessTokenReturnedFromCacheWhenPreviousNotExpired(t *testing.T) {
+	sender := createSender(authResponse)
+	client := NewYouTubeAuthClient(sender, refreshToken, clientId, clientSecret)
+	previousToken, _ := client.GetAccessToken()
+
+	nextToken, err := client.GetAccessToken()
+
+	assert.Nil(t, err)
+	assert.Equal(t, previousToken, nextToken)
+	sender.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestAccessTokenReturnedFromSenderWhenPreviousExpired(t *testing.T) {
+	sender := createSender(authExpiredResponse)
+	client := NewYouTubeAuthClient(sender, refreshToken, clientId, clientSecret)
+	client.GetAccessToken()
+
+	_, err := client.GetAccessToken()
+
+	assert.Nil(t, err)
+	sender.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestAccessTokenReturnsErrorOnInvalidResponse(t *testing.T) {
+	sender := createSender("{some_invalid_json}")
+	client := NewYouTubeAuthClient(sender, refreshToken, clientId, clientSecret)
+
+	_, err := client.GetAccessToken()
+
+	assert.NotNil(t, err)
+}
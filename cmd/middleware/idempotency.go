@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type storedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+type idempotencyRecord struct {
+	fingerprint string
+	expiresAt   time.Time
+	// Closed once the original request finishes
+	done     chan struct{}
+	response *storedResponse
+}
+
+// Replays the response of a request when it is retried with the same Idempotency-Key header.
+// Keys are kept per user for a while, and reusing them with a different request is rejected.
+// Requests without the header are left untouched
+type IdempotencyKeys struct {
+	mutex       sync.Mutex
+	records     map[string]*idempotencyRecord
+	header      string
+	userIdKey   types.ContextKey
+	ttl         time.Duration
+	waitTimeout time.Duration
+	now         func() time.Time
+	logger      logging.Logger
+}
+
+func NewIdempotencyKeys(logger logging.Logger) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		records:     map[string]*idempotencyRecord{},
+		header:      "Idempotency-Key",
+		userIdKey:   types.ContextKey("user_id"),
+		ttl:         24 * time.Hour,
+		waitTimeout: 10 * time.Second,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+func (m *IdempotencyKeys) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(m.header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "validation error: idempotency key is too long", http.StatusBadRequest)
+			return
+		}
+
+		userId, ok := r.Context().Value(m.userIdKey).(string)
+		if !ok {
+			m.logger.Error("could not retrieve user id from context when checking idempotency key")
+			http.Error(w, "Unexpected error", http.StatusInternalServerError)
+			return
+		}
+
+		requestBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			m.logger.Warn(fmt.Sprintf("could not read body of idempotent request: %v", err))
+			http.Error(w, "could not read body from request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+		m.serveIdempotent(w, r, next, userId+":"+key, requestFingerprint(r, requestBody))
+	})
+}
+
+func (m *IdempotencyKeys) serveIdempotent(
+	w http.ResponseWriter,
+	r *http.Request,
+	next http.Handler,
+	recordKey string,
+	fingerprint string,
+) {
+	waitDeadline := time.After(m.waitTimeout)
+	for {
+		m.mutex.Lock()
+		m.removeExpiredRecords()
+		record, ok := m.records[recordKey]
+		if !ok {
+			record = &idempotencyRecord{fingerprint: fingerprint, done: make(chan struct{})}
+			m.records[recordKey] = record
+			m.mutex.Unlock()
+			m.serveOriginal(w, r, next, recordKey, record)
+			return
+		}
+		response := record.response
+		m.mutex.Unlock()
+
+		if record.fingerprint != fingerprint {
+			m.logger.Warn("idempotency key reused with a different request")
+			http.Error(w, "idempotency key was already used with a different request", http.StatusConflict)
+			return
+		}
+
+		if response != nil {
+			writeStoredResponse(w, response)
+			return
+		}
+
+		// The original request is still running, so wait for it to replay its response
+		select {
+		case <-record.done:
+		case <-waitDeadline:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "a request with this idempotency key is still in progress", http.StatusConflict)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (m *IdempotencyKeys) serveOriginal(
+	w http.ResponseWriter,
+	r *http.Request,
+	next http.Handler,
+	recordKey string,
+	record *idempotencyRecord,
+) {
+	recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	completed := false
+	defer func() {
+		m.mutex.Lock()
+		// Server errors are not stored, so the request can be retried with the same key
+		if completed && recorder.statusCode < http.StatusInternalServerError {
+			record.response = &storedResponse{
+				statusCode: recorder.statusCode,
+				header:     w.Header().Clone(),
+				body:       recorder.body.Bytes(),
+			}
+			record.expiresAt = m.now().Add(m.ttl)
+		} else {
+			delete(m.records, recordKey)
+		}
+		close(record.done)
+		m.mutex.Unlock()
+	}()
+
+	next.ServeHTTP(recorder, r)
+	completed = true
+}
+
+func (m *IdempotencyKeys) removeExpiredRecords() {
+	for key, record := range m.records {
+		if record.response != nil && m.now().After(record.expiresAt) {
+			delete(m.records, key)
+		}
+	}
+}
+
+// Sets how long responses are kept after the original request finishes
+func (m *IdempotencyKeys) SetTTL(ttl time.Duration) {
+	m.ttl = ttl
+}
+
+// Sets how long retries wait for the original request before reporting it as in progress
+func (m *IdempotencyKeys) SetWaitTimeout(timeout time.Duration) {
+	m.waitTimeout = timeout
+}
+
+func (m *IdempotencyKeys) SetUserIdKey(key types.ContextKey) {
+	m.userIdKey = key
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writeStoredResponse(w http.ResponseWriter, response *storedResponse) {
+	for name, values := range response.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(response.statusCode)
+	_, _ = w.Write(response.body)
+}
+
+// Keeps a copy of the response while writing it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	idempotencyKey  = "some_key"
+	idempotencyUser = "some_user"
+	idempotencyBody = `{"artists":[{"name":"Comeback Kid"}]}`
+)
+
+// Counts the calls, so each response is different from the previous ones
+type CountingHandler struct {
+	calls      atomic.Int32
+	statusCode int
+	release    chan struct{}
+}
+
+func (h *CountingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	calls := h.calls.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Location", fmt.Sprintf("/playlists/jobs/%d", calls))
+	w.WriteHeader(h.statusCode)
+	fmt.Fprintf(w, "call %d: %s", calls, body)
+}
+
+func buildIdempotentRequest(key string, userId string, body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "http://example.com/playlists", bytes.NewBufferString(body))
+	if key != "" {
+		request.Header.Set("Idempotency-Key", key)
+	}
+	ctx := context.WithValue(request.Context(), types.ContextKey("user_id"), userId)
+	return request.WithContext(ctx)
+}
+
+func serveIdempotent(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	return writer
+}
+
+func TestIdempotencyReplaysOriginalResponse(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+	original := serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	replayed := serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+
+	assert.Equal(t, int32(1), next.calls.Load())
+	assert.Equal(t, http.StatusAccepted, replayed.Code)
+	assert.Equal(t, original.Body.String(), replayed.Body.String())
+	assert.Equal(t, "/playlists/jobs/1", replayed.Header().Get("Location"))
+	assert.Equal(t, "true", replayed.Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, original.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyRunsRequestsWithDifferentKeysOrUsers(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	serveIdempotent(handler, buildIdempotentRequest("another_key", idempotencyUser, idempotencyBody))
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, "another_user", idempotencyBody))
+
+	assert.Equal(t, int32(3), next.calls.Load())
+}
+
+func TestIdempotencyIgnoresRequestsWithoutKey(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+	serveIdempotent(handler, buildIdempotentRequest("", idempotencyUser, idempotencyBody))
+	writer := serveIdempotent(handler, buildIdempotentRequest("", idempotencyUser, idempotencyBody))
+
+	assert.Equal(t, int32(2), next.calls.Load())
+	assert.Equal(t, "call 2: "+idempotencyBody, writer.Body.String())
+}
+
+func TestIdempotencyRejectsKeyReusedWithDifferentBody(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	writer := serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, `{"artists":[]}`))
+
+	assert.Equal(t, http.StatusConflict, writer.Code)
+	assert.Equal(t, int32(1), next.calls.Load())
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusInternalServerError}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+
+	assert.Equal(t, int32(2), next.calls.Load())
+}
+
+func TestIdempotencyRunsRequestAgainAfterTTL(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	next := &CountingHandler{statusCode: http.StatusAccepted}
+	idempotencyKeys := NewIdempotencyKeys(logging.NoopLogger{})
+	idempotencyKeys.SetTTL(time.Hour)
+	idempotencyKeys.now = func() time.Time { return now }
+	handler := idempotencyKeys.Middleware(next)
+
+	serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	now = now.Add(2 * time.Hour)
+	writer := serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+
+	assert.Equal(t, int32(2), next.calls.Load())
+	assert.Empty(t, writer.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyRetryWaitsForOriginalRequest(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted, release: make(chan struct{})}
+	handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+	originalDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		originalDone <- serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	}()
+	assert.Eventually(t, func() bool { return next.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	retryDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		retryDone <- serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	}()
+	close(next.release)
+	original := <-originalDone
+	retry := <-retryDone
+
+	assert.Equal(t, int32(1), next.calls.Load())
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Equal(t, original.Body.String(), retry.Body.String())
+}
+
+func TestIdempotencyReportsRequestInProgressAfterWaiting(t *testing.T) {
+	next := &CountingHandler{statusCode: http.StatusAccepted, release: make(chan struct{})}
+	idempotencyKeys := NewIdempotencyKeys(logging.NoopLogger{})
+	idempotencyKeys.SetWaitTimeout(time.Millisecond)
+	handler := idempotencyKeys.Middleware(next)
+	originalDone := make(chan struct{})
+	go func() {
+		defer close(originalDone)
+		serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	}()
+	assert.Eventually(t, func() bool { return next.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	writer := serveIdempotent(handler, buildIdempotentRequest(idempotencyKey, idempotencyUser, idempotencyBody))
+	close(next.release)
+	<-originalDone
+
+	assert.Equal(t, http.StatusConflict, writer.Code)
+	assert.Equal(t, "1", writer.Header().Get("Retry-After"))
+}
+
+func TestIdempotencyReturnsErrorOnInvalidRequest(t *testing.T) {
+	tests := map[string]struct {
+		request      *http.Request
+		expectedCode int
+	}{
+		"key too long": {
+			request:      buildIdempotentRequest(strings.Repeat("k", 256), idempotencyUser, idempotencyBody),
+			expectedCode: http.StatusBadRequest,
+		},
+		"missing user id": {
+			request: func() *http.Request {
+				request := httptest.NewRequest(http.MethodPost, "http://example.com/playlists", nil)
+				request.Header.Set("Idempotency-Key", idempotencyKey)
+				return request
+			}(),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := &CountingHandler{statusCode: http.StatusAccepted}
+			handler := NewIdempotencyKeys(logging.NoopLogger{}).Middleware(next)
+
+			writer := serveIdempotent(handler, test.request)
+
+			assert.Equal(t, test.expectedCode, writer.Code)
+			assert.Equal(t, int32(0), next.calls.Load())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	types "festwrap/internal"
+)
+
+// Places the Apple Music user token sent by the client into the context, so library requests can be
+// performed on behalf of the user. Requests without the header are left untouched
+type MusicUserTokenExtractor struct {
+	header   string
+	tokenKey types.ContextKey
+}
+
+func NewMusicUserTokenExtractor() MusicUserTokenExtractor {
+	return MusicUserTokenExtractor{header: "Music-User-Token", tokenKey: types.ContextKey("music_user_token")}
+}
+
+func (m MusicUserTokenExtractor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(m.header)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctxWithToken := context.WithValue(r.Context(), m.tokenKey, token)
+		next.ServeHTTP(w, r.WithContext(ctxWithToken))
+	})
+}
+
+func (m *MusicUserTokenExtractor) SetTokenKey(key types.ContextKey) {
+	m.tokenKey = key
+}
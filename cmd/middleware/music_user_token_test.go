@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	types "festwrap/internal"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const musicUserTokenKey = types.ContextKey("user_token")
+
+type GetMusicUserTokenHandler struct{}
+
+func (h GetMusicUserTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := r.Context().Value(musicUserTokenKey).(string)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprint(w, token)
+}
+
+func musicUserTokenExtractorTestSetup() (MusicUserTokenExtractor, *http.Request, *httptest.ResponseRecorder) {
+	middleware := NewMusicUserTokenExtractor()
+	middleware.SetTokenKey(musicUserTokenKey)
+	request := httptest.NewRequest("GET", "http://example.com", nil)
+	writer := httptest.NewRecorder()
+	return middleware, request, writer
+}
+
+func TestMusicUserTokenIsPlacedInContext(t *testing.T) {
+	extractor, request, writer := musicUserTokenExtractorTestSetup()
+	request.Header.Set("Music-User-Token", "some_token")
+
+	extractor.Middleware(GetMusicUserTokenHandler{}).ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusAccepted, writer.Code)
+	assert.Equal(t, "some_token", writer.Body.String())
+}
+
+func TestMusicUserTokenMissingKeepsRequestUntouched(t *testing.T) {
+	extractor, request, writer := musicUserTokenExtractorTestSetup()
+
+	extractor.Middleware(GetMusicUserTokenHandler{}).ServeHTTP(writer, request)
+
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+}
@@ -0,0 +1,278 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"festwrap/cmd/middleware/auth"
+	services "festwrap/cmd/services"
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+)
+
+var ErrRefreshNotFound = errors.New("playlist refresh not found")
+
+type ScheduledRefresh struct {
+	PlaylistId      string
+	UserId          string
+	Artists         []string
+	Options         services.RefreshOptions
+	NextRefreshAt   time.Time
+	LastRefreshedAt time.Time
+	LastRefresh     services.PlaylistRefresh
+	LastErr         error
+}
+
+// Periodically updates the playlists marked for refresh with the latest setlists of their artists.
+// Refreshes run outside of any request, so the scheduler obtains its own access token
+type RefreshScheduler struct {
+	mutex           sync.Mutex
+	refreshes       map[string]*ScheduledRefresh
+	store           RefreshStore
+	playlistService services.PlaylistService
+	authClient      auth.AuthClient
+	interval        time.Duration
+	checkInterval   time.Duration
+	tokenKey        types.ContextKey
+	userIdKey       types.ContextKey
+	now             func() time.Time
+	stop            chan struct{}
+	done            chan struct{}
+	logger          logging.Logger
+}
+
+func NewRefreshScheduler(
+	playlistService services.PlaylistService,
+	authClient auth.AuthClient,
+	logger logging.Logger,
+) *RefreshScheduler {
+	return &RefreshScheduler{
+		refreshes:       map[string]*ScheduledRefresh{},
+		playlistService: playlistService,
+		authClient:      authClient,
+		interval:        24 * time.Hour,
+		checkInterval:   time.Minute,
+		tokenKey:        "token",
+		userIdKey:       "user_id",
+		now:             time.Now,
+		logger:          logger,
+	}
+}
+
+func (s *RefreshScheduler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refreshDue()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stops checking for refreshes, waiting for the ones in progress
+func (s *RefreshScheduler) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// Marks the playlist of the user in the context to be refreshed periodically, replacing any previous schedule
+func (s *RefreshScheduler) Schedule(
+	ctx context.Context,
+	playlistId string,
+	artists []string,
+	options services.RefreshOptions,
+) (ScheduledRefresh, error) {
+	userId, ok := ctx.Value(s.userIdKey).(string)
+	if !ok {
+		return ScheduledRefresh{}, errors.New("could not retrieve user id from context when scheduling refresh")
+	}
+
+	if err := s.playlistService.CheckPlaylistOwner(ctx, playlistId); err != nil {
+		return ScheduledRefresh{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Songs added by earlier refreshes are kept, so they can still be removed once dropped from the setlists
+	key := refreshKey(userId, playlistId)
+	if previous, ok := s.refreshes[key]; ok {
+		options.AddedSongUris = map[string][]string{}
+		for _, artist := range artists {
+			if uris, ok := previous.Options.AddedSongUris[artist]; ok {
+				options.AddedSongUris[artist] = uris
+			}
+		}
+	}
+
+	scheduled := &ScheduledRefresh{
+		PlaylistId:    playlistId,
+		UserId:        userId,
+		Artists:       slices.Clone(artists),
+		Options:       options,
+		NextRefreshAt: s.now().Add(s.interval),
+	}
+	if s.store != nil {
+		if err := s.store.Save(*scheduled); err != nil {
+			return ScheduledRefresh{}, err
+		}
+	}
+	s.refreshes[key] = scheduled
+	return scheduled.snapshot(), nil
+}
+
+func (s *RefreshScheduler) GetRefresh(ctx context.Context, playlistId string) (ScheduledRefresh, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	scheduled, err := s.findUserRefresh(ctx, playlistId)
+	if err != nil {
+		return ScheduledRefresh{}, err
+	}
+	return scheduled.snapshot(), nil
+}
+
+func (s *RefreshScheduler) Cancel(ctx context.Context, playlistId string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	scheduled, err := s.findUserRefresh(ctx, playlistId)
+	if err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err = s.store.Delete(scheduled.UserId, playlistId); err != nil {
+			return err
+		}
+	}
+	delete(s.refreshes, refreshKey(scheduled.UserId, playlistId))
+	return nil
+}
+
+// Keeps the refreshes in the given store, loading the ones scheduled before a restart
+func (s *RefreshScheduler) SetStore(store RefreshStore) error {
+	stored, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("could not load scheduled refreshes: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.store = store
+	for _, scheduled := range stored {
+		s.refreshes[refreshKey(scheduled.UserId, scheduled.PlaylistId)] = &scheduled
+	}
+	return nil
+}
+
+// Sets how often each playlist is refreshed
+func (s *RefreshScheduler) SetInterval(interval time.Duration) {
+	s.interval = interval
+}
+
+// Sets how often the scheduler looks for playlists due for a refresh
+func (s *RefreshScheduler) SetCheckInterval(interval time.Duration) {
+	s.checkInterval = interval
+}
+
+func (s *RefreshScheduler) SetTokenKey(key types.ContextKey) {
+	s.tokenKey = key
+}
+
+func (s *RefreshScheduler) SetUserIdKey(key types.ContextKey) {
+	s.userIdKey = key
+}
+
+func (s *RefreshScheduler) findUserRefresh(ctx context.Context, playlistId string) (*ScheduledRefresh, error) {
+	userId, ok := ctx.Value(s.userIdKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve user id from context when getting refresh")
+	}
+
+	scheduled, ok := s.refreshes[refreshKey(userId, playlistId)]
+	if !ok {
+		return nil, fmt.Errorf("%w: playlist %s", ErrRefreshNotFound, playlistId)
+	}
+	return scheduled, nil
+}
+
+// Refreshes one playlist after another, so setlist.fm rate limits are respected
+func (s *RefreshScheduler) refreshDue() {
+	s.mutex.Lock()
+	var due []*ScheduledRefresh
+	for _, scheduled := range s.refreshes {
+		if !s.now().Before(scheduled.NextRefreshAt) {
+			due = append(due, scheduled)
+		}
+	}
+	s.mutex.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	token, err := s.authClient.GetAccessToken()
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("could not obtain access token to refresh playlists: %v", err))
+		return
+	}
+
+	for _, scheduled := range due {
+		// Scheduled refreshes are only replaced, never modified, so they can be read without the lock
+		ctx := context.WithValue(context.Background(), s.tokenKey, token)
+		ctx = context.WithValue(ctx, s.userIdKey, scheduled.UserId)
+		result, err := s.playlistService.RefreshPlaylist(ctx, scheduled.PlaylistId, scheduled.Artists, scheduled.Options)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("could not refresh playlist %s: %v", scheduled.PlaylistId, err))
+		}
+		s.recordRefresh(scheduled, result, err)
+	}
+}
+
+func (s *RefreshScheduler) recordRefresh(scheduled *ScheduledRefresh, result services.PlaylistRefresh, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The playlist may have been cancelled or scheduled again while refreshing it
+	key := refreshKey(scheduled.UserId, scheduled.PlaylistId)
+	if s.refreshes[key] != scheduled {
+		return
+	}
+	refreshed := scheduled.snapshot()
+	refreshed.LastRefreshedAt = s.now()
+	refreshed.LastRefresh = result
+	refreshed.LastErr = err
+	if err == nil {
+		refreshed.Options.AddedSongUris = result.AddedSongUris
+	}
+	refreshed.NextRefreshAt = s.now().Add(s.interval)
+	s.refreshes[key] = &refreshed
+	if s.store != nil {
+		if err := s.store.Save(refreshed); err != nil {
+			s.logger.Error(fmt.Sprintf("could not store refresh of playlist %s: %v", scheduled.PlaylistId, err))
+		}
+	}
+}
+
+func (r *ScheduledRefresh) snapshot() ScheduledRefresh {
+	snapshot := *r
+	snapshot.Artists = slices.Clone(r.Artists)
+	return snapshot
+}
+
+func refreshKey(userId string, playlistId string) string {
+	return userId + ":" + playlistId
+}
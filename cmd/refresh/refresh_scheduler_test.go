@@ -0,0 +1,307 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"festwrap/cmd/middleware/auth"
+	services "festwrap/cmd/services"
+	playlistmocks "festwrap/cmd/services/mocks"
+	types "festwrap/internal"
+	"festwrap/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	playlistId = "some_playlist"
+	userId     = "some_user"
+	token      = "some_token"
+)
+
+func testContext(user string) context.Context {
+	return context.WithValue(context.Background(), types.ContextKey("user_id"), user)
+}
+
+func testArtists() []string {
+	return []string{"Comeback Kid", "Municipal Waste"}
+}
+
+func testRefresh() services.PlaylistRefresh {
+	return services.PlaylistRefresh{PlaylistId: playlistId, FailedArtists: 1}
+}
+
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
+
+func setupScheduler(
+	result services.PlaylistRefresh,
+	err error,
+) (*RefreshScheduler, *playlistmocks.PlaylistServiceMock, *auth.AuthClientMock, *testClock) {
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On("CheckPlaylistOwner", mock.Anything, playlistId).Return(nil)
+	playlistService.On(
+		"RefreshPlaylist", mock.Anything, playlistId, testArtists(), services.RefreshOptions{RemoveDroppedSongs: true},
+	).Return(result, err)
+	authClient := &auth.AuthClientMock{}
+	authClient.On("GetAccessToken").Return(token, nil)
+	clock := &testClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	scheduler := NewRefreshScheduler(playlistService, authClient, logging.NoopLogger{})
+	scheduler.SetInterval(time.Hour)
+	scheduler.now = clock.Now
+	return scheduler, playlistService, authClient, clock
+}
+
+func schedule(t *testing.T, scheduler *RefreshScheduler) ScheduledRefresh {
+	t.Helper()
+
+	scheduled, err := scheduler.Schedule(
+		testContext(userId), playlistId, testArtists(), services.RefreshOptions{RemoveDroppedSongs: true},
+	)
+	assert.Nil(t, err)
+	return scheduled
+}
+
+func TestScheduleReturnsNextRefresh(t *testing.T) {
+	scheduler, _, _, clock := setupScheduler(testRefresh(), nil)
+
+	actual := schedule(t, scheduler)
+
+	expected := ScheduledRefresh{
+		PlaylistId:    playlistId,
+		UserId:        userId,
+		Artists:       testArtists(),
+		Options:       services.RefreshOptions{RemoveDroppedSongs: true},
+		NextRefreshAt: clock.Now().Add(time.Hour),
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestScheduleReturnsErrorWhenUserIdMissing(t *testing.T) {
+	scheduler, _, _, _ := setupScheduler(testRefresh(), nil)
+
+	_, err := scheduler.Schedule(context.Background(), playlistId, testArtists(), services.RefreshOptions{})
+
+	assert.NotNil(t, err)
+}
+
+func TestScheduleReturnsErrorWhenPlaylistNotOwned(t *testing.T) {
+	scheduler, _, _, _ := setupScheduler(testRefresh(), nil)
+	playlistService := &playlistmocks.PlaylistServiceMock{}
+	playlistService.On("CheckPlaylistOwner", mock.Anything, playlistId).Return(services.ErrPlaylistNotOwned)
+	scheduler.playlistService = playlistService
+
+	_, err := scheduler.Schedule(testContext(userId), playlistId, testArtists(), services.RefreshOptions{})
+	_, getErr := scheduler.GetRefresh(testContext(userId), playlistId)
+
+	assert.ErrorIs(t, err, services.ErrPlaylistNotOwned)
+	assert.ErrorIs(t, getErr, ErrRefreshNotFound)
+}
+
+func TestRefreshDueRefreshesPlaylistOnBehalfOfUser(t *testing.T) {
+	scheduler, playlistService, _, clock := setupScheduler(testRefresh(), nil)
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+
+	scheduler.refreshDue()
+
+	playlistService.AssertExpectations(t)
+	ctx := playlistService.Calls[1].Arguments.Get(0).(context.Context)
+	assert.Equal(t, token, ctx.Value(types.ContextKey("token")))
+	assert.Equal(t, userId, ctx.Value(types.ContextKey("user_id")))
+}
+
+func TestRefreshDueRecordsResult(t *testing.T) {
+	refreshErr := errors.New("test error")
+
+	tests := map[string]struct {
+		result services.PlaylistRefresh
+		err    error
+	}{
+		"success": {
+			result: testRefresh(),
+		},
+		"error": {
+			err: refreshErr,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheduler, _, _, clock := setupScheduler(test.result, test.err)
+			schedule(t, scheduler)
+			clock.Advance(time.Hour)
+
+			scheduler.refreshDue()
+			actual, err := scheduler.GetRefresh(testContext(userId), playlistId)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.result, actual.LastRefresh)
+			assert.Equal(t, test.err, actual.LastErr)
+			assert.Equal(t, clock.Now(), actual.LastRefreshedAt)
+			assert.Equal(t, clock.Now().Add(time.Hour), actual.NextRefreshAt)
+		})
+	}
+}
+
+func TestRefreshDueSkipsPlaylistsNotDue(t *testing.T) {
+	scheduler, playlistService, authClient, clock := setupScheduler(testRefresh(), nil)
+	schedule(t, scheduler)
+	clock.Advance(time.Minute)
+
+	scheduler.refreshDue()
+
+	playlistService.AssertNotCalled(t, "RefreshPlaylist", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	authClient.AssertNotCalled(t, "GetAccessToken")
+}
+
+func TestRefreshDueSkipsCancelledPlaylists(t *testing.T) {
+	scheduler, playlistService, _, clock := setupScheduler(testRefresh(), nil)
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+
+	cancelErr := scheduler.Cancel(testContext(userId), playlistId)
+	scheduler.refreshDue()
+
+	assert.Nil(t, cancelErr)
+	playlistService.AssertNotCalled(t, "RefreshPlaylist", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshDueDoesNotRefreshWithoutToken(t *testing.T) {
+	scheduler, playlistService, _, clock := setupScheduler(testRefresh(), nil)
+	authClient := &auth.AuthClientMock{}
+	authClient.On("GetAccessToken").Return("", errors.New("test error"))
+	scheduler.authClient = authClient
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+
+	scheduler.refreshDue()
+
+	playlistService.AssertNotCalled(t, "RefreshPlaylist", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshesCanOnlyBeReadAndCancelledByTheirUser(t *testing.T) {
+	scheduler, _, _, _ := setupScheduler(testRefresh(), nil)
+	schedule(t, scheduler)
+
+	_, getErr := scheduler.GetRefresh(testContext("another_user"), playlistId)
+	cancelErr := scheduler.Cancel(testContext("another_user"), playlistId)
+	_, ownerErr := scheduler.GetRefresh(testContext(userId), playlistId)
+
+	assert.ErrorIs(t, getErr, ErrRefreshNotFound)
+	assert.ErrorIs(t, cancelErr, ErrRefreshNotFound)
+	assert.Nil(t, ownerErr)
+}
+
+func TestStartedSchedulerRefreshesPeriodically(t *testing.T) {
+	scheduler, playlistService, _, clock := setupScheduler(testRefresh(), nil)
+	scheduler.SetCheckInterval(time.Millisecond)
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+
+	scheduler.Start()
+	assert.Eventually(t, func() bool {
+		refreshed, _ := scheduler.GetRefresh(testContext(userId), playlistId)
+		return !refreshed.LastRefreshedAt.IsZero()
+	}, time.Second, time.Millisecond)
+	scheduler.Stop()
+
+	playlistService.AssertNumberOfCalls(t, "RefreshPlaylist", 1)
+}
+
+func TestScheduledRefreshesAreStored(t *testing.T) {
+	scheduler, _, _, clock := setupScheduler(testRefresh(), errors.New("test error"))
+	store, err := NewFileRefreshStore(t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, scheduler.SetStore(store))
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+
+	scheduler.refreshDue()
+	expected, _ := scheduler.GetRefresh(testContext(userId), playlistId)
+	actual, err := store.Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []ScheduledRefresh{expected}, actual)
+}
+
+func TestCancelledRefreshesAreRemovedFromStore(t *testing.T) {
+	scheduler, _, _, _ := setupScheduler(testRefresh(), nil)
+	store, err := NewFileRefreshStore(t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, scheduler.SetStore(store))
+	schedule(t, scheduler)
+
+	cancelErr := scheduler.Cancel(testContext(userId), playlistId)
+	actual, err := store.Load()
+
+	assert.Nil(t, cancelErr)
+	assert.Nil(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestSetStoreLoadsStoredRefreshes(t *testing.T) {
+	scheduler, _, _, _ := setupScheduler(testRefresh(), nil)
+	store, err := NewFileRefreshStore(t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, scheduler.SetStore(store))
+	expected := schedule(t, scheduler)
+
+	restarted, _, _, _ := setupScheduler(testRefresh(), nil)
+	storeErr := restarted.SetStore(store)
+	actual, err := restarted.GetRefresh(testContext(userId), playlistId)
+
+	assert.Nil(t, storeErr)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestRefreshDuePassesSongsAddedByEarlierRefreshes(t *testing.T) {
+	addedSongUris := map[string][]string{"Comeback Kid": {"some_uri"}}
+	result := testRefresh()
+	result.AddedSongUris = addedSongUris
+	scheduler, playlistService, _, clock := setupScheduler(result, nil)
+	nextOptions := services.RefreshOptions{RemoveDroppedSongs: true, AddedSongUris: addedSongUris}
+	playlistService.On("RefreshPlaylist", mock.Anything, playlistId, testArtists(), nextOptions).Return(result, nil)
+	schedule(t, scheduler)
+
+	clock.Advance(time.Hour)
+	scheduler.refreshDue()
+	clock.Advance(time.Hour)
+	scheduler.refreshDue()
+
+	playlistService.AssertCalled(t, "RefreshPlaylist", mock.Anything, playlistId, testArtists(), nextOptions)
+}
+
+func TestScheduleKeepsSongsAddedForRemainingArtists(t *testing.T) {
+	result := testRefresh()
+	result.AddedSongUris = map[string][]string{"Comeback Kid": {"some_uri"}, "Municipal Waste": {"another_uri"}}
+	scheduler, _, _, clock := setupScheduler(result, nil)
+	schedule(t, scheduler)
+	clock.Advance(time.Hour)
+	scheduler.refreshDue()
+
+	actual, err := scheduler.Schedule(testContext(userId), playlistId, []string{"Comeback Kid"}, services.RefreshOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{"Comeback Kid": {"some_uri"}}, actual.Options.AddedSongUris)
+}
@@ -0,0 +1,143 @@
+package refresh
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	services "festwrap/cmd/services"
+)
+
+type RefreshStore interface {
+	Save(refresh ScheduledRefresh) error
+	Delete(userId string, playlistId string) error
+	Load() ([]ScheduledRefresh, error)
+}
+
+// Errors are kept as their message, since they cannot be serialized
+type storedRefresh struct {
+	PlaylistId      string                   `json:"playlistId"`
+	UserId          string                   `json:"userId"`
+	Artists         []string                 `json:"artists"`
+	Options         services.RefreshOptions  `json:"options"`
+	NextRefreshAt   time.Time                `json:"nextRefreshAt"`
+	LastRefreshedAt time.Time                `json:"lastRefreshedAt"`
+	LastRefresh     services.PlaylistRefresh `json:"lastRefresh"`
+	LastErr         string                   `json:"lastError,omitempty"`
+}
+
+func newStoredRefresh(refresh ScheduledRefresh) storedRefresh {
+	stored := storedRefresh{
+		PlaylistId:      refresh.PlaylistId,
+		UserId:          refresh.UserId,
+		Artists:         refresh.Artists,
+		Options:         refresh.Options,
+		NextRefreshAt:   refresh.NextRefreshAt,
+		LastRefreshedAt: refresh.LastRefreshedAt,
+		LastRefresh:     refresh.LastRefresh,
+	}
+	if refresh.LastErr != nil {
+		stored.LastErr = refresh.LastErr.Error()
+	}
+	return stored
+}
+
+func (r storedRefresh) scheduledRefresh() ScheduledRefresh {
+	refresh := ScheduledRefresh{
+		PlaylistId:      r.PlaylistId,
+		UserId:          r.UserId,
+		Artists:         r.Artists,
+		Options:         r.Options,
+		NextRefreshAt:   r.NextRefreshAt,
+		LastRefreshedAt: r.LastRefreshedAt,
+		LastRefresh:     r.LastRefresh,
+	}
+	if r.LastErr != "" {
+		refresh.LastErr = errors.New(r.LastErr)
+	}
+	return refresh
+}
+
+// Keeps each scheduled refresh in its own file, so schedules survive restarts
+type FileRefreshStore struct {
+	dir string
+}
+
+// Creates the directory if missing
+func NewFileRefreshStore(dir string) (*FileRefreshStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create refreshes directory: %v", err)
+	}
+	return &FileRefreshStore{dir: dir}, nil
+}
+
+// Writes to a temporary file first, so a crash never leaves a refresh half written
+func (s *FileRefreshStore) Save(refresh ScheduledRefresh) error {
+	refreshBytes, err := json.Marshal(newStoredRefresh(refresh))
+	if err != nil {
+		return fmt.Errorf("could not serialize refresh of playlist %s: %v", refresh.PlaylistId, err)
+	}
+
+	file, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not store refresh of playlist %s: %v", refresh.PlaylistId, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(refreshBytes); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), s.refreshPath(refresh.UserId, refresh.PlaylistId))
+	}
+	if err != nil {
+		return fmt.Errorf("could not store refresh of playlist %s: %v", refresh.PlaylistId, err)
+	}
+	return nil
+}
+
+func (s *FileRefreshStore) Delete(userId string, playlistId string) error {
+	if err := os.Remove(s.refreshPath(userId, playlistId)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove refresh of playlist %s: %v", playlistId, err)
+	}
+	return nil
+}
+
+func (s *FileRefreshStore) Load() ([]ScheduledRefresh, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read refreshes directory %s: %v", s.dir, err)
+	}
+
+	refreshes := []ScheduledRefresh{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(s.dir, entry.Name())
+		refreshBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read refresh %s: %v", path, err)
+		}
+		var stored storedRefresh
+		if err = json.Unmarshal(refreshBytes, &stored); err != nil {
+			return nil, fmt.Errorf("could not deserialize refresh %s: %v", path, err)
+		}
+		refreshes = append(refreshes, stored.scheduledRefresh())
+	}
+	return refreshes, nil
+}
+
+// Ids are hex encoded, so they are safe to use as file names
+func (s *FileRefreshStore) refreshPath(userId string, playlistId string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(refreshKey(userId, playlistId)))+".json")
+}
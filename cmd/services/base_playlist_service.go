@@ -2,13 +2,17 @@ package playlist
 
 import (
 	"context"
+	"errors"
 
+	types "festwrap/internal"
 	"festwrap/internal/event"
 	"festwrap/internal/logging"
 	"festwrap/internal/playlist"
 	"festwrap/internal/setlist"
 	"festwrap/internal/song"
 	"fmt"
+	"math/rand/v2"
+	"sync"
 	"time"
 )
 
@@ -23,8 +27,17 @@ type BasePlaylistService struct {
 	setlistRepository        setlist.SetlistRepository
 	songRepository           song.SongRepository
 	playlistCreationNotifier event.Notifier[event.PlaylistCreatedEvent]
+	playlistFailureNotifier  event.Notifier[event.PlaylistCreationFailedEvent]
+	playlistUpdateNotifier   event.Notifier[event.PlaylistUpdatedEvent]
+	setlistNotFoundNotifier  event.Notifier[event.SetlistNotFoundEvent]
+	songNotFoundNotifier     event.Notifier[event.SongNotFoundEvent]
+	playlistType             event.PlaylistType
 	minSongs                 int
 	addSetlistSleepMs        int
+	maxSongWorkers           int
+	songLookupSlots          chan struct{}
+	userIdKey                types.ContextKey
+	randomSeed               *uint64
 	logger                   logging.Logger
 }
 
@@ -39,9 +52,17 @@ func NewBasePlaylistService(
 		setlistRepository:        setlistRepository,
 		songRepository:           songRepository,
 		playlistCreationNotifier: event.NewBaseNotifier[event.PlaylistCreatedEvent](),
+		playlistFailureNotifier:  event.NewBaseNotifier[event.PlaylistCreationFailedEvent](),
+		playlistUpdateNotifier:   event.NewBaseNotifier[event.PlaylistUpdatedEvent](),
+		setlistNotFoundNotifier:  event.NewBaseNotifier[event.SetlistNotFoundEvent](),
+		songNotFoundNotifier:     event.NewBaseNotifier[event.SongNotFoundEvent](),
+		playlistType:             event.PLAYLIST_TYPE_SPOTIFY,
 		logger:                   logger,
 		minSongs:                 4,
 		addSetlistSleepMs:        0,
+		maxSongWorkers:           5,
+		songLookupSlots:          make(chan struct{}, 20),
+		userIdKey:                "user_id",
 	}
 }
 
@@ -49,39 +70,140 @@ func (s *BasePlaylistService) CreatePlaylistWithArtists(
 	ctx context.Context,
 	playlist playlist.PlaylistDetails,
 	artists []string,
+	options CreationOptions,
 ) (PlaylistCreation, error) {
-	playlistId, err := s.playlistRepository.CreatePlaylist(ctx, playlist)
+	// Setlists are resolved first so the description can mention them
+	deduplicator := newSongDeduplicator(options.Deduplication)
+	resolution, err := s.resolveArtists(ctx, playlist.Name, artists, deduplicator, options.Progress)
+	s.notifyResolutionFailures(resolution)
 	if err != nil {
-		return PlaylistCreation{}, fmt.Errorf("could not create playlist: %v", err)
+		s.notifyPlaylistCreationFailed("", playlist.Name, resolution.artists, err, CleanupReport{})
+		return PlaylistCreation{}, err
 	}
 
-	errors := 0
+	return s.createWithResolution(ctx, playlist, resolution, options)
+}
+
+// Songs are added as given, without looking up the setlists again nor removing duplicates
+func (s *BasePlaylistService) CreatePlaylistWithSongs(
+	ctx context.Context,
+	playlist playlist.PlaylistDetails,
+	artists []ArtistSongs,
+	options CreationOptions,
+) (PlaylistCreation, error) {
+	resolution := artistsResolution{artists: make([]artistSongs, len(artists))}
 	for i, artist := range artists {
-		if i > 0 {
-			// Sleep to avoid hitting Setlistfm rate limit
-			time.Sleep(time.Duration(s.addSetlistSleepMs) * time.Millisecond)
+		resolution.artists[i] = artistSongs{artist: artist.Name, setlistUrl: artist.SetlistUrl}
+		if len(artist.Songs) == 0 {
+			resolution.artists[i].err = errors.New("no songs selected")
+			resolution.failures += 1
+			continue
 		}
-		err := s.addSetlistToPlaylist(ctx, playlistId, artist)
-		if err != nil {
-			s.logger.Warn(fmt.Sprintf("could not add songs for %s to playlist %s: %v", artist, playlistId, err))
-			errors += 1
+		resolution.artists[i].songs = artist.Songs
+		resolution.artists[i].matches = make([]SongMatch, len(artist.Songs))
+		for j, addedSong := range artist.Songs {
+			resolution.artists[i].matches[j] = SongMatch{
+				SetlistTitle: addedSong.SetlistTitle, Status: SongMatched, Song: addedSong.Song,
+			}
 		}
 	}
-	if errors == len(artists) {
-		s.logger.Error(fmt.Sprintf("could not add any of artists %v to playlist %s", artists, playlistId))
-		return PlaylistCreation{}, fmt.Errorf("all artists failed to be added to playlist %s", playlistId)
+
+	if resolution.failures == len(artists) {
+		err := errors.New("no songs provided for any artist")
+		s.notifyPlaylistCreationFailed("", playlist.Name, resolution.artists, err, CleanupReport{})
+		return PlaylistCreation{}, err
 	}
 
-	var status CreationStatus
-	if errors == 0 {
-		status = Success
-	} else {
-		status = PartialFailure
+	return s.createWithResolution(ctx, playlist, resolution, options)
+}
+
+func (s *BasePlaylistService) createWithResolution(
+	ctx context.Context,
+	playlist playlist.PlaylistDetails,
+	resolution artistsResolution,
+	options CreationOptions,
+) (PlaylistCreation, error) {
+	if playlist.Description == "" {
+		playlist.Description = generatePlaylistDescription(resolution.artists)
 	}
 
-	s.notifyPlaylistCreated(ctx, playlistId, playlist.Name, artists, status)
+	playlistId, err := s.playlistRepository.CreatePlaylist(ctx, playlist)
+	if err != nil {
+		err = fmt.Errorf("could not create playlist: %v", err)
+		s.notifyPlaylistCreationFailed("", playlist.Name, resolution.artists, err, CleanupReport{})
+		return PlaylistCreation{}, err
+	}
+
+	creation, err := s.addResolvedArtists(ctx, playlistId, resolution, options.Ordering)
+	if err != nil {
+		cleanup := s.rollbackPlaylist(ctx, playlistId, nil)
+		s.notifyPlaylistCreationFailed(playlistId, playlist.Name, resolution.artists, err, cleanup)
+		return PlaylistCreation{}, &CreationError{Err: err, Cleanup: cleanup}
+	}
+
+	if ctx.Err() != nil && options.RollbackOnCancel {
+		cleanup := s.rollbackPlaylist(ctx, playlistId, creation.addedSongs())
+		s.notifyPlaylistCreationFailed(playlistId, playlist.Name, resolution.artists, ctx.Err(), cleanup)
+		return PlaylistCreation{}, &CreationError{Err: ctx.Err(), Cleanup: cleanup}
+	}
 
-	return PlaylistCreation{PlaylistId: playlistId, Status: status}, nil
+	s.notifyPlaylistCreated(ctx, playlistId, playlist.Name, creation.Artists, creation.Status)
+
+	return creation, nil
+}
+
+func (s *BasePlaylistService) AddArtistsToPlaylist(
+	ctx context.Context,
+	playlistId string,
+	artists []string,
+) (PlaylistCreation, error) {
+	existingSongs, err := s.getOwnedPlaylistSongs(ctx, playlistId, "adding artists")
+	if err != nil {
+		return PlaylistCreation{}, err
+	}
+
+	// Songs already in the playlist are not added again
+	deduplicator := newSongDeduplicator(DeduplicationOptions{})
+	for _, existingSong := range existingSongs {
+		deduplicator.Add(existingSong)
+	}
+
+	resolution, err := s.resolveArtists(ctx, playlistId, artists, deduplicator, nil)
+	s.notifyResolutionFailures(resolution)
+	if err != nil {
+		return PlaylistCreation{}, err
+	}
+
+	return s.addResolvedArtists(ctx, playlistId, resolution, OrderingOptions{Strategy: SequentialOrdering})
+}
+
+func (s *BasePlaylistService) PreviewPlaylistWithArtists(
+	ctx context.Context,
+	artists []string,
+	options DeduplicationOptions,
+) (PlaylistPreview, error) {
+	// Artists failing are part of the preview, so the resolution error is not returned
+	resolution, _ := s.resolveArtists(ctx, "preview", artists, newSongDeduplicator(options), nil)
+	if err := ctx.Err(); err != nil {
+		return PlaylistPreview{}, err
+	}
+
+	preview := PlaylistPreview{
+		Artists:           make([]ArtistPreview, len(resolution.artists)),
+		FailedArtists:     resolution.failures,
+		DuplicatesRemoved: resolution.duplicates,
+	}
+	for i, resolvedArtist := range resolution.artists {
+		preview.Artists[i] = ArtistPreview{
+			Name:       resolvedArtist.artist,
+			SetlistUrl: resolvedArtist.setlistUrl,
+			Songs:      resolvedArtist.matches,
+			Err:        resolvedArtist.err,
+		}
+		preview.MatchedSongs += len(resolvedArtist.songs)
+		preview.UnmatchedSongs += len(resolvedArtist.unmatched)
+	}
+	return preview, nil
 }
 
 func (s *BasePlaylistService) SetAddSetlistSleep(sleepMs int) {
@@ -92,6 +214,38 @@ func (s *BasePlaylistService) SetMinSongs(minSongs int) {
 	s.minSongs = minSongs
 }
 
+// Sets the maximum number of concurrent song lookups for a single setlist
+func (s *BasePlaylistService) SetMaxSongWorkers(workers int) {
+	s.maxSongWorkers = max(workers, 1)
+}
+
+// Sets the maximum number of concurrent song lookups shared across all requests
+func (s *BasePlaylistService) SetMaxGlobalSongWorkers(workers int) {
+	s.songLookupSlots = make(chan struct{}, max(workers, 1))
+}
+
+// Sets the seed used to shuffle songs, so the shuffled order can be reproduced
+func (s *BasePlaylistService) SetRandomSeed(seed uint64) {
+	s.randomSeed = &seed
+}
+
+// Random sources are not safe for concurrent use, so each creation gets its own
+func (s *BasePlaylistService) newRandom() *rand.Rand {
+	if s.randomSeed != nil {
+		return rand.New(rand.NewPCG(*s.randomSeed, *s.randomSeed))
+	}
+	return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+}
+
+func (s *BasePlaylistService) SetUserIdKey(key types.ContextKey) {
+	s.userIdKey = key
+}
+
+// Sets the type reported in the events, which should match the backend of the playlist repository
+func (s *BasePlaylistService) SetPlaylistType(playlistType event.PlaylistType) {
+	s.playlistType = playlistType
+}
+
 func (s *BasePlaylistService) SetPlaylistCreateNotifier(
 	subject event.Notifier[event.PlaylistCreatedEvent],
 ) *BasePlaylistService {
@@ -99,44 +253,393 @@ func (s *BasePlaylistService) SetPlaylistCreateNotifier(
 	return s
 }
 
-func (s *BasePlaylistService) addSetlistToPlaylist(ctx context.Context, playlistId string, artist string) error {
+func (s *BasePlaylistService) SetPlaylistFailureNotifier(
+	subject event.Notifier[event.PlaylistCreationFailedEvent],
+) *BasePlaylistService {
+	s.playlistFailureNotifier = subject
+	return s
+}
+
+func (s *BasePlaylistService) SetPlaylistUpdateNotifier(
+	subject event.Notifier[event.PlaylistUpdatedEvent],
+) *BasePlaylistService {
+	s.playlistUpdateNotifier = subject
+	return s
+}
+
+func (s *BasePlaylistService) SetSetlistNotFoundNotifier(
+	subject event.Notifier[event.SetlistNotFoundEvent],
+) *BasePlaylistService {
+	s.setlistNotFoundNotifier = subject
+	return s
+}
+
+func (s *BasePlaylistService) SetSongNotFoundNotifier(
+	subject event.Notifier[event.SongNotFoundEvent],
+) *BasePlaylistService {
+	s.songNotFoundNotifier = subject
+	return s
+}
+
+// Returns the songs of the playlist, as long as it belongs to the user in the context
+func (s *BasePlaylistService) getOwnedPlaylistSongs(
+	ctx context.Context,
+	playlistId string,
+	action string,
+) ([]song.Song, error) {
+	if err := s.checkPlaylistOwner(ctx, playlistId, action); err != nil {
+		return nil, err
+	}
+
+	existingSongs, err := s.playlistRepository.GetSongs(ctx, playlistId)
+	if err != nil {
+		return nil, fmt.Errorf("could not get songs from playlist %s: %v", playlistId, err)
+	}
+	return existingSongs, nil
+}
+
+func (s *BasePlaylistService) CheckPlaylistOwner(ctx context.Context, playlistId string) error {
+	return s.checkPlaylistOwner(ctx, playlistId, "checking playlist owner")
+}
+
+func (s *BasePlaylistService) checkPlaylistOwner(ctx context.Context, playlistId string, action string) error {
+	userId, ok := ctx.Value(s.userIdKey).(string)
+	if !ok {
+		return fmt.Errorf("could not retrieve user id from context when %s", action)
+	}
+
+	existingPlaylist, err := s.playlistRepository.GetPlaylist(ctx, playlistId)
+	if err != nil {
+		return fmt.Errorf("could not get playlist %s: %v", playlistId, err)
+	}
+
+	if existingPlaylist.OwnerId != userId {
+		return fmt.Errorf("%w: playlist %s, user %s", ErrPlaylistNotOwned, playlistId, userId)
+	}
+	return nil
+}
+
+type artistsResolution struct {
+	artists    []artistSongs
+	failures   int
+	duplicates int
+}
+
+// Resolves the songs of all artists, so they can be added to the playlist in a single ordered pass
+func (s *BasePlaylistService) resolveArtists(
+	ctx context.Context,
+	target string,
+	artists []string,
+	deduplicator *songDeduplicator,
+	progress ProgressListener,
+) (artistsResolution, error) {
+	resolution := artistsResolution{artists: make([]artistSongs, len(artists))}
+	for i, artist := range artists {
+		if i > 0 {
+			// Sleep to avoid hitting Setlistfm rate limit, unless the request is cancelled meanwhile
+			if err := sleepWithContext(ctx, time.Duration(s.addSetlistSleepMs)*time.Millisecond); err != nil {
+				return resolution, err
+			}
+		}
+		artistProgress := func(event ProgressEvent) {
+			event.ArtistIndex = i
+			event.Artist = artist
+			progress.notify(event)
+		}
+		setlistResolution, err := s.resolveSetlistSongs(ctx, artist, deduplicator, artistProgress)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("could not find songs for %s to add to playlist %s: %v", artist, target, err))
+			resolution.failures += 1
+			artistProgress(ProgressEvent{Type: ArtistFailed, SetlistUrl: setlistResolution.setlistUrl, Err: err})
+		} else {
+			totalSongs := len(setlistResolution.matches)
+			artistProgress(ProgressEvent{
+				Type:         ArtistResolved,
+				SetlistUrl:   setlistResolution.setlistUrl,
+				TotalSongs:   totalSongs,
+				MatchedSongs: totalSongs - len(setlistResolution.unmatched),
+			})
+		}
+		resolution.duplicates += setlistResolution.duplicates
+		resolution.artists[i] = artistSongs{
+			artist:     artist,
+			setlistUrl: setlistResolution.setlistUrl,
+			songs:      setlistResolution.songs,
+			unmatched:  setlistResolution.unmatched,
+			matches:    setlistResolution.matches,
+			err:        err,
+		}
+	}
+	if resolution.failures == len(artists) {
+		s.logger.Error(fmt.Sprintf("could not add any of artists %v to playlist %s", artists, target))
+		// The resolution is still returned so the failure of each artist can be reported
+		return resolution, fmt.Errorf("all artists failed to be added to playlist %s", target)
+	}
+	return resolution, nil
+}
+
+func (s *BasePlaylistService) addResolvedArtists(
+	ctx context.Context,
+	playlistId string,
+	resolution artistsResolution,
+	ordering OrderingOptions,
+) (PlaylistCreation, error) {
+	resolvedArtists := resolution.artists
+	artists := make([]string, len(resolvedArtists))
+	for i, resolvedArtist := range resolvedArtists {
+		artists[i] = resolvedArtist.artist
+	}
+
+	orderedSongs := orderSongs(resolvedArtists, ordering, s.newRandom())
+	snapshotIds, orderedSongs, err := s.addOrderedSongs(ctx, playlistId, orderedSongs)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("could not add songs of artists %v to playlist %s: %v", artists, playlistId, err))
+		return PlaylistCreation{}, fmt.Errorf("all artists failed to be added to playlist %s", playlistId)
+	}
+
+	artistCreations := make([]ArtistCreation, len(artists))
+	for i, resolvedArtist := range resolvedArtists {
+		artistCreations[i] = ArtistCreation{
+			Name:           resolvedArtist.artist,
+			SetlistUrl:     resolvedArtist.setlistUrl,
+			UnmatchedSongs: resolvedArtist.unmatched,
+			RequestedSongs: len(resolvedArtist.matches),
+			Err:            resolvedArtist.err,
+		}
+		if resolvedArtist.songs != nil {
+			artistCreations[i].Songs = []AddedSong{}
+		}
+	}
+	for _, orderedSong := range orderedSongs {
+		creation := &artistCreations[orderedSong.artistIndex]
+		creation.Songs = append(creation.Songs, orderedSong.song)
+	}
+
+	var status CreationStatus
+	if resolution.failures == 0 {
+		status = Success
+	} else {
+		status = PartialFailure
+	}
+
+	snapshotId := ""
+	if len(snapshotIds) > 0 {
+		snapshotId = snapshotIds[len(snapshotIds)-1]
+	}
+
+	return PlaylistCreation{
+		PlaylistId:        playlistId,
+		SnapshotId:        snapshotId,
+		Status:            status,
+		Artists:           artistCreations,
+		DuplicatesRemoved: resolution.duplicates,
+	}, nil
+}
+
+// Adds the songs to the playlist and returns the ones that made it into it
+func (s *BasePlaylistService) addOrderedSongs(
+	ctx context.Context,
+	playlistId string,
+	orderedSongs []orderedSong,
+) ([]string, []orderedSong, error) {
+	if len(orderedSongs) == 0 {
+		return nil, orderedSongs, nil
+	}
+
+	songs := make([]song.Song, len(orderedSongs))
+	for i, orderedSong := range orderedSongs {
+		songs[i] = orderedSong.song.Song
+	}
+
+	snapshotIds, err := s.playlistRepository.AddSongs(ctx, playlistId, songs)
+	if addSongsErr, ok := playlist.AsAddSongsError(err); ok {
+		// Some chunks may have been added, so we only report the songs that made it into the playlist
+		addedSongs := excludeFailedSongs(orderedSongs, addSongsErr)
+		if len(addedSongs) == 0 {
+			return nil, nil, err
+		}
+		s.logger.Warn(fmt.Sprintf("could not add some songs to playlist %s: %v", playlistId, err))
+		return snapshotIds, addedSongs, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return snapshotIds, orderedSongs, nil
+}
+
+type setlistResolution struct {
+	setlistUrl string
+	songs      []AddedSong
+	unmatched  []UnmatchedSong
+	matches    []SongMatch
+	duplicates int
+}
+
+// Finds the songs from the artist setlist, skipping the ones the deduplicator has already seen
+func (s *BasePlaylistService) resolveSetlistSongs(
+	ctx context.Context,
+	artist string,
+	deduplicator *songDeduplicator,
+	progress ProgressListener,
+) (setlistResolution, error) {
 	setlist, err := s.setlistRepository.GetSetlist(artist, s.minSongs)
 	if err != nil {
-		return err
+		return setlistResolution{}, err
 	}
 
 	s.logger.Info(fmt.Sprintf("Found setlist: %s for artist: %s", setlist.GetUrl(), artist))
 
-	songsCount := len(setlist.GetSongs())
-	ch := make(chan FetchSongResult)
-	rankedResults := make([]FetchSongResult, songsCount)
-	for i, song := range setlist.GetSongs() {
-		go s.fetchSong(ctx, artist, song, i, ch)
+	setlistSongs := setlist.GetSongs()
+	progress.notify(ProgressEvent{Type: SetlistFound, SetlistUrl: setlist.GetUrl(), TotalSongs: len(setlistSongs)})
+	resolvedSongs := []AddedSong{}
+	var unmatchedSongs []UnmatchedSong
+	matches := make([]SongMatch, 0, len(setlistSongs))
+	duplicates := 0
+	// Songs are reported as soon as they are found, keeping the setlist order so duplicates are told apart as usual
+	s.fetchSongs(ctx, artist, setlistSongs, func(fetchResult FetchSongResult) {
+		setlistTitle := setlistSongs[fetchResult.Rank].GetTitle()
+		var match SongMatch
+		if fetchResult.Err != nil {
+			unmatchedSongs = append(unmatchedSongs, UnmatchedSong{SetlistTitle: setlistTitle, Err: fetchResult.Err})
+			match = SongMatch{SetlistTitle: setlistTitle, Status: SongNotFound, Err: fetchResult.Err}
+		} else if deduplicator.IsDuplicate(fetchResult.Song) {
+			duplicates += 1
+			match = SongMatch{SetlistTitle: setlistTitle, Status: SongDuplicated, Song: fetchResult.Song}
+		} else {
+			deduplicator.Add(fetchResult.Song)
+			resolvedSongs = append(resolvedSongs, AddedSong{SetlistTitle: setlistTitle, Song: fetchResult.Song})
+			match = SongMatch{SetlistTitle: setlistTitle, Status: SongMatched, Song: fetchResult.Song}
+		}
+		matches = append(matches, match)
+		progress.notify(ProgressEvent{
+			Type:       SongResolved,
+			SetlistUrl: setlist.GetUrl(),
+			SongTitle:  match.SetlistTitle,
+			SongStatus: match.Status,
+			Err:        match.Err,
+		})
+	})
+	if err := ctx.Err(); err != nil {
+		return setlistResolution{setlistUrl: setlist.GetUrl()}, err
 	}
 
-	// Keep songs in the original setlist order
-	for range songsCount {
-		fetchResult := <-ch
-		rankedResults[fetchResult.Rank] = fetchResult
+	if len(resolvedSongs) == 0 && duplicates > 0 {
+		s.logger.Info(fmt.Sprintf("all songs for %s are already in the playlist", artist))
+	} else if len(resolvedSongs) == 0 {
+		// Unmatched songs are still reported, so callers can tell which titles could not be found
+		resolution := setlistResolution{setlistUrl: setlist.GetUrl(), unmatched: unmatchedSongs, matches: matches}
+		return resolution, fmt.Errorf("no songs found for artist %s", artist)
 	}
 
-	songs := []song.Song{}
-	for _, fetchResult := range rankedResults {
-		if fetchResult.Err == nil {
-			songs = append(songs, fetchResult.Song)
+	return setlistResolution{
+		setlistUrl: setlist.GetUrl(),
+		songs:      resolvedSongs,
+		unmatched:  unmatchedSongs,
+		matches:    matches,
+		duplicates: duplicates,
+	}, nil
+}
+
+// Undoes the changes made to a new playlist, so failed creations do not leave playlists behind
+func (s *BasePlaylistService) rollbackPlaylist(
+	ctx context.Context,
+	playlistId string,
+	addedSongs []song.Song,
+) CleanupReport {
+	// The request may have been cancelled, but the changes must be undone anyway
+	ctx = context.WithoutCancel(ctx)
+	report := CleanupReport{PlaylistId: playlistId}
+
+	if len(addedSongs) > 0 {
+		if remover, ok := s.playlistRepository.(playlist.SongRemover); !ok {
+			report.Errors = append(report.Errors, errors.New("removing songs is not supported"))
+		} else if err := remover.RemoveSongs(ctx, playlistId, addedSongs); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("could not remove songs: %v", err))
+		} else {
+			report.SongsRemoved = len(addedSongs)
 		}
 	}
 
-	if len(songs) == 0 {
-		return fmt.Errorf("no songs to add to playlist %s for artist %s", playlistId, artist)
+	if deleter, ok := s.playlistRepository.(playlist.PlaylistDeleter); !ok {
+		report.Errors = append(report.Errors, errors.New("deleting playlists is not supported"))
+	} else if err := deleter.DeletePlaylist(ctx, playlistId); err != nil {
+		report.Errors = append(report.Errors, fmt.Errorf("could not delete playlist: %v", err))
+	} else {
+		report.PlaylistDeleted = true
 	}
 
-	err = s.playlistRepository.AddSongs(ctx, playlistId, songs)
-	if err != nil {
-		return err
+	if len(report.Errors) > 0 {
+		s.logger.Warn(fmt.Sprintf("could not fully clean up playlist %s: %v", playlistId, report.Errors))
+	} else {
+		s.logger.Info(fmt.Sprintf("cleaned up playlist %s after failing to create it", playlistId))
 	}
+	return report
+}
 
-	return nil
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func excludeFailedSongs(orderedSongs []orderedSong, addSongsErr *playlist.AddSongsError) []orderedSong {
+	result := []orderedSong{}
+	for i, orderedSong := range orderedSongs {
+		if !addSongsErr.IsFailed(i) {
+			result = append(result, orderedSong)
+		}
+	}
+	return result
+}
+
+// Looks up the songs at the same time, handing their results to onFetched one after the other in the setlist order,
+// as soon as the song and the ones before it are looked up. Songs left when the context is cancelled are not handed
+func (s *BasePlaylistService) fetchSongs(
+	ctx context.Context,
+	artist string,
+	songs []setlist.Song,
+	onFetched func(FetchSongResult),
+) {
+	// Results are stored by rank until the ones before them are handed
+	rankedResults := make([]*FetchSongResult, len(songs))
+	nextRank := 0
+	var mutex sync.Mutex
+	handle := func(result FetchSongResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		rankedResults[result.Rank] = &result
+		for ; nextRank < len(songs) && rankedResults[nextRank] != nil; nextRank++ {
+			onFetched(*rankedResults[nextRank])
+		}
+	}
+
+	ranks := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.maxSongWorkers, len(songs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rank := range ranks {
+				handle(s.fetchSong(ctx, artist, songs[rank], rank))
+			}
+		}()
+	}
+
+dispatch:
+	for sent := 0; sent < len(songs); sent++ {
+		select {
+		case ranks <- sent:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(ranks)
+	wg.Wait()
 }
 
 func (s *BasePlaylistService) fetchSong(
@@ -144,17 +647,28 @@ func (s *BasePlaylistService) fetchSong(
 	artist string,
 	song setlist.Song,
 	rank int,
-	ch chan<- FetchSongResult,
-) {
+) FetchSongResult {
+	select {
+	case s.songLookupSlots <- struct{}{}:
+		defer func() { <-s.songLookupSlots }()
+	case <-ctx.Done():
+		return FetchSongResult{Err: ctx.Err(), Rank: rank}
+	}
+
+	// Both cases might be ready at once, so check again before sending the request
+	if err := ctx.Err(); err != nil {
+		return FetchSongResult{Err: err, Rank: rank}
+	}
+
 	songDetails, err := s.songRepository.GetSong(ctx, artist, song.GetTitle())
-	ch <- FetchSongResult{Song: songDetails, Err: err, Rank: rank}
+	return FetchSongResult{Song: songDetails, Err: err, Rank: rank}
 }
 
 func (s *BasePlaylistService) notifyPlaylistCreated(
 	ctx context.Context,
 	playlistId,
 	playlistName string,
-	artists []string,
+	artists []ArtistCreation,
 	status CreationStatus,
 ) {
 	playlistCreatedEvent := s.createPlaylistCreatedEvent(
@@ -166,10 +680,68 @@ func (s *BasePlaylistService) notifyPlaylistCreated(
 	s.playlistCreationNotifier.Notify(event.NewEventWrapper(playlistCreatedEvent))
 }
 
+func (s *BasePlaylistService) notifyPlaylistCreationFailed(
+	playlistId string,
+	playlistName string,
+	artists []artistSongs,
+	reason error,
+	cleanup CleanupReport,
+) {
+	failedArtists := []event.FailedPlaylistArtist{}
+	for _, artist := range artists {
+		if artist.err != nil {
+			failedArtists = append(
+				failedArtists,
+				event.FailedPlaylistArtist{Name: artist.artist, SetlistUrl: artist.setlistUrl, Reason: artist.err.Error()},
+			)
+		}
+	}
+	cleanupErrors := make([]string, len(cleanup.Errors))
+	for i, err := range cleanup.Errors {
+		cleanupErrors[i] = err.Error()
+	}
+	failedEvent := event.PlaylistCreationFailedEvent{
+		Playlist: event.FailedPlaylist{Id: playlistId, Name: playlistName, Type: s.playlistType},
+		Reason:   reason.Error(),
+		Artists:  failedArtists,
+		Cleanup: event.PlaylistCleanup{
+			PlaylistDeleted: cleanup.PlaylistDeleted,
+			SongsRemoved:    cleanup.SongsRemoved,
+			Errors:          cleanupErrors,
+		},
+	}
+	s.playlistFailureNotifier.Notify(event.NewEventWrapper(failedEvent))
+}
+
+// Reports the setlists and songs that could not be found, unless the resolution was cancelled
+func (s *BasePlaylistService) notifyResolutionFailures(resolution artistsResolution) {
+	for _, artist := range resolution.artists {
+		if artist.err != nil && artist.setlistUrl == "" {
+			s.setlistNotFoundNotifier.Notify(event.NewEventWrapper(event.SetlistNotFoundEvent{
+				Artist:       artist.artist,
+				Reason:       artist.err.Error(),
+				PlaylistType: s.playlistType,
+			}))
+		}
+		for _, unmatchedSong := range artist.unmatched {
+			if errors.Is(unmatchedSong.Err, context.Canceled) || errors.Is(unmatchedSong.Err, context.DeadlineExceeded) {
+				continue
+			}
+			s.songNotFoundNotifier.Notify(event.NewEventWrapper(event.SongNotFoundEvent{
+				Artist:       artist.artist,
+				SetlistTitle: unmatchedSong.SetlistTitle,
+				SetlistUrl:   artist.setlistUrl,
+				Reason:       unmatchedSong.Err.Error(),
+				PlaylistType: s.playlistType,
+			}))
+		}
+	}
+}
+
 func (s *BasePlaylistService) createPlaylistCreatedEvent(
 	playlistId,
 	playlistName string,
-	artists []string,
+	artists []ArtistCreation,
 	status CreationStatus,
 ) event.PlaylistCreatedEvent {
 	var eventStatus event.PlaylistCreationStatus
@@ -184,7 +756,11 @@ func (s *BasePlaylistService) createPlaylistCreatedEvent(
 
 	artistArray := make([]event.CreatedPlaylistArtist, len(artists))
 	for i, artist := range artists {
-		artistArray[i] = event.CreatedPlaylistArtist{Name: artist}
+		tracks := make([]event.CreatedPlaylistTrack, len(artist.Songs))
+		for j, addedSong := range artist.Songs {
+			tracks[j] = newCreatedPlaylistTrack(addedSong)
+		}
+		artistArray[i] = newCreatedPlaylistArtist(artist, tracks)
 	}
 
 	return event.PlaylistCreatedEvent{
@@ -192,8 +768,63 @@ func (s *BasePlaylistService) createPlaylistCreatedEvent(
 			Id:      playlistId,
 			Name:    playlistName,
 			Artists: artistArray,
-			Type:    event.PLAYLIST_TYPE_SPOTIFY,
+			Type:    s.playlistType,
 		},
 		CreationStatus: eventStatus,
 	}
 }
+
+func newCreatedPlaylistArtist(artist ArtistCreation, tracks []event.CreatedPlaylistTrack) event.CreatedPlaylistArtist {
+	unmatchedTitles := make([]string, len(artist.UnmatchedSongs))
+	for i, unmatchedSong := range artist.UnmatchedSongs {
+		unmatchedTitles[i] = unmatchedSong.SetlistTitle
+	}
+
+	status := event.ARTIST_CREATED_OK
+	if artist.Err != nil {
+		status = event.ARTIST_CREATION_FAILED
+	} else if len(artist.UnmatchedSongs) > 0 {
+		status = event.ARTIST_CREATED_PARTIAL_ERRORS
+	}
+
+	return event.CreatedPlaylistArtist{
+		Name:            artist.Name,
+		Tracks:          tracks,
+		Status:          status,
+		SetlistUrl:      artist.SetlistUrl,
+		RequestedSongs:  artist.RequestedSongs,
+		MatchedSongs:    artist.RequestedSongs - len(artist.UnmatchedSongs),
+		UnmatchedTitles: unmatchedTitles,
+		ErrorCategory:   artistErrorCategory(artist),
+	}
+}
+
+// Groups the reasons an artist failed, so consumers do not need to parse error messages
+func artistErrorCategory(artist ArtistCreation) event.ArtistErrorCategory {
+	switch {
+	case artist.Err == nil:
+		return ""
+	case errors.Is(artist.Err, context.Canceled) || errors.Is(artist.Err, context.DeadlineExceeded):
+		return event.ARTIST_ERROR_CANCELLED
+	case artist.SetlistUrl == "":
+		return event.ARTIST_ERROR_SETLIST_NOT_FOUND
+	default:
+		return event.ARTIST_ERROR_NO_SONGS_FOUND
+	}
+}
+
+// Songs without artists still have an empty array of them, as the event schema requires
+func newCreatedPlaylistTrack(addedSong AddedSong) event.CreatedPlaylistTrack {
+	artists := addedSong.Song.Artists
+	if artists == nil {
+		artists = []string{}
+	}
+	return event.CreatedPlaylistTrack{
+		SetlistTitle: addedSong.SetlistTitle,
+		Id:           addedSong.Song.Id,
+		Uri:          addedSong.Song.Uri,
+		Name:         addedSong.Song.Name,
+		Artists:      artists,
+		Isrc:         addedSong.Song.Isrc,
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
+	"time"
 
+	types "festwrap/internal"
 	"festwrap/internal/event"
 	"festwrap/internal/logging"
 	"festwrap/internal/playlist"
@@ -14,7 +17,6 @@ import (
 	setlistmocks "festwrap/internal/setlist/mocks"
 	"festwrap/internal/song"
 	songmocks "festwrap/internal/song/mocks"
-	"festwrap/internal/testtools"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,6 +24,7 @@ import (
 
 const (
 	playlistId          = "myPlaylist"
+	snapshotId          = "mySnapshot"
 	artistName          = "myArtist"
 	playlistName        = "My playlist"
 	playlistDescription = "Some playlist"
@@ -140,6 +143,41 @@ func testArtistNames() []string {
 	return names
 }
 
+func expectedArtistCreations(testCase []TestArtist) []ArtistCreation {
+	creations := make([]ArtistCreation, len(testCase))
+	for i, artist := range testCase {
+		creations[i] = ArtistCreation{Name: artist.name}
+		if artist.setlist.err != nil {
+			creations[i].Err = artist.setlist.err
+			continue
+		}
+		creations[i].SetlistUrl = artist.setlist.value.GetUrl()
+		creations[i].RequestedSongs = len(artist.setlist.value.GetSongs())
+		noSongsErr := fmt.Errorf("no songs found for artist %s", artist.name)
+		if len(artist.setlist.value.GetSongs()) == 0 {
+			creations[i].Err = noSongsErr
+			continue
+		}
+		songs := []AddedSong{}
+		for j, setlistSong := range artist.setlist.value.GetSongs() {
+			if artist.songs[j].err != nil {
+				creations[i].UnmatchedSongs = append(
+					creations[i].UnmatchedSongs,
+					UnmatchedSong{SetlistTitle: setlistSong.GetTitle(), Err: artist.songs[j].err},
+				)
+				continue
+			}
+			songs = append(songs, AddedSong{SetlistTitle: setlistSong.GetTitle(), Song: artist.songs[j].value})
+		}
+		if len(songs) > 0 {
+			creations[i].Songs = songs
+		} else {
+			creations[i].Err = noSongsErr
+		}
+	}
+	return creations
+}
+
 func testContext() context.Context {
 	return context.Background()
 }
@@ -150,8 +188,22 @@ func testPlaylist() playlist.PlaylistDetails {
 
 func playlistCreatedEvent() event.PlaylistCreatedEvent {
 	artists := make([]event.CreatedPlaylistArtist, len(testArtistNames()))
-	for i, name := range testArtistNames() {
-		artists[i] = event.CreatedPlaylistArtist{Name: name}
+	for i, artist := range expectedArtistCreations(mainTestCase()) {
+		tracks := make([]event.CreatedPlaylistTrack, len(artist.Songs))
+		for j, addedSong := range artist.Songs {
+			tracks[j] = event.CreatedPlaylistTrack{
+				SetlistTitle: addedSong.SetlistTitle, Uri: addedSong.Song.Uri, Artists: []string{},
+			}
+		}
+		artists[i] = event.CreatedPlaylistArtist{
+			Name:            artist.Name,
+			Tracks:          tracks,
+			Status:          event.ARTIST_CREATED_OK,
+			SetlistUrl:      artist.SetlistUrl,
+			RequestedSongs:  len(tracks),
+			MatchedSongs:    len(tracks),
+			UnmatchedTitles: []string{},
+		}
 	}
 	return event.PlaylistCreatedEvent{
 		Playlist: event.CreatedPlaylist{
@@ -167,22 +219,14 @@ func playlistCreatedEvent() event.PlaylistCreatedEvent {
 func newPlaylistRepositoryMock(artists []TestArtist) *playlistmocks.PlaylistRepositoryMock {
 	repository := playlistmocks.NewPlaylistRepositoryMock()
 	repository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
-	for _, artist := range artists {
-		var songs []song.Song
-		for _, song := range artist.songs {
-			if song.err == nil {
-				songs = append(songs, song.value)
-			}
+	var songs []song.Song
+	for _, artist := range expectedArtistCreations(artists) {
+		for _, addedSong := range artist.Songs {
+			songs = append(songs, addedSong.Song)
 		}
-		songsMatcher := mock.MatchedBy(func(items []song.Song) bool {
-			return testtools.HaveSameElements(songs, items)
-		})
-		repository.On(
-			"AddSongs",
-			testContext(),
-			playlistId,
-			songsMatcher,
-		).Return(nil)
+	}
+	if len(songs) > 0 {
+		repository.On("AddSongs", testContext(), playlistId, songs).Return([]string{snapshotId}, nil)
 	}
 	return &repository
 }
@@ -225,7 +269,12 @@ func TestCreatePlaylistPerformsExpectedSideEffects(t *testing.T) {
 	playlistRepository, setlistRepository, songRepository := testSetup(mainTestCase())
 	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
 
-	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames())
+	_, err := service.CreatePlaylistWithArtists(
+		testContext(),
+		testPlaylist(),
+		testArtistNames(),
+		DefaultCreationOptions(),
+	)
 
 	assert.Nil(t, err)
 	playlistRepository.AssertExpectations(t)
@@ -239,7 +288,12 @@ func TestCreatePlaylistReturnsErrorOnCreateError(t *testing.T) {
 	service := NewBasePlaylistService(
 		&playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
 
-	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames())
+	_, err := service.CreatePlaylistWithArtists(
+		testContext(),
+		testPlaylist(),
+		testArtistNames(),
+		DefaultCreationOptions(),
+	)
 
 	assert.NotNil(t, err)
 }
@@ -253,37 +307,57 @@ func TestCreatePlaylistResult(t *testing.T) {
 		"all setlists fail": {
 			testCase:       allSetlistsFailTestCase(),
 			expectedStatus: PlaylistCreation{},
-			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistId),
+			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistName),
 		},
 		"all songs failed to be added": {
 			testCase:       allSongsFailTestCase(),
 			expectedStatus: PlaylistCreation{},
-			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistId),
+			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistName),
 		},
 		"all setlists empty": {
 			testCase:       allSetlistsEmptyTestCase(),
 			expectedStatus: PlaylistCreation{},
-			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistId),
+			expectedError:  fmt.Errorf("all artists failed to be added to playlist %s", playlistName),
 		},
 		"some setlists failed": {
-			testCase:       someSetlistsFailTestCase(),
-			expectedStatus: PlaylistCreation{PlaylistId: playlistId, Status: PartialFailure},
-			expectedError:  nil,
+			testCase: someSetlistsFailTestCase(),
+			expectedStatus: PlaylistCreation{
+				PlaylistId: playlistId,
+				SnapshotId: snapshotId,
+				Status:     PartialFailure,
+				Artists:    expectedArtistCreations(someSetlistsFailTestCase()),
+			},
+			expectedError: nil,
 		},
 		"some setlists empty": {
-			testCase:       someSetlistEmptyTestCase(),
-			expectedStatus: PlaylistCreation{PlaylistId: playlistId, Status: PartialFailure},
-			expectedError:  nil,
+			testCase: someSetlistEmptyTestCase(),
+			expectedStatus: PlaylistCreation{
+				PlaylistId: playlistId,
+				SnapshotId: snapshotId,
+				Status:     PartialFailure,
+				Artists:    expectedArtistCreations(someSetlistEmptyTestCase()),
+			},
+			expectedError: nil,
 		},
 		"some songs failed": {
-			testCase:       someSongsFailedTestCase(),
-			expectedStatus: PlaylistCreation{PlaylistId: playlistId, Status: Success},
-			expectedError:  nil,
+			testCase: someSongsFailedTestCase(),
+			expectedStatus: PlaylistCreation{
+				PlaylistId: playlistId,
+				SnapshotId: snapshotId,
+				Status:     Success,
+				Artists:    expectedArtistCreations(someSongsFailedTestCase()),
+			},
+			expectedError: nil,
 		},
 		"success": {
-			testCase:       mainTestCase(),
-			expectedStatus: PlaylistCreation{PlaylistId: playlistId, Status: Success},
-			expectedError:  nil,
+			testCase: mainTestCase(),
+			expectedStatus: PlaylistCreation{
+				PlaylistId: playlistId,
+				SnapshotId: snapshotId,
+				Status:     Success,
+				Artists:    expectedArtistCreations(mainTestCase()),
+			},
+			expectedError: nil,
 		},
 	}
 
@@ -295,7 +369,12 @@ func TestCreatePlaylistResult(t *testing.T) {
 			service := NewBasePlaylistService(
 				playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
 
-			status, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames())
+			status, err := service.CreatePlaylistWithArtists(
+				testContext(),
+				testPlaylist(),
+				testArtistNames(),
+				DefaultCreationOptions(),
+			)
 
 			assert.Equal(t, test.expectedStatus, status)
 			assert.Equal(t, test.expectedError, err)
@@ -315,9 +394,977 @@ func TestCreatePlaylistNotifiesSubjectWithCreateInfo(t *testing.T) {
 		playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
 	service.SetPlaylistCreateNotifier(subject)
 
-	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames())
+	_, err := service.CreatePlaylistWithArtists(
+		testContext(),
+		testPlaylist(),
+		testArtistNames(),
+		DefaultCreationOptions(),
+	)
 
 	assert.Nil(t, err)
 	assert.Len(t, fakeObserver.GetEvents(), 1)
 	assert.Equal(t, fakeObserver.GetEvents()[0].Event, playlistCreatedEvent())
 }
+
+func TestCreatePlaylistNotifiesArtistResults(t *testing.T) {
+	tests := map[string]struct {
+		testCase []TestArtist
+		expected event.CreatedPlaylistArtist
+	}{
+		"some songs failed": {
+			testCase: someSongsFailedTestCase(),
+			expected: event.CreatedPlaylistArtist{
+				Name: "Alexisonfire",
+				Tracks: []event.CreatedPlaylistTrack{
+					{SetlistTitle: "Accidents", Uri: "http://some_url2", Artists: []string{}},
+				},
+				Status:          event.ARTIST_CREATED_PARTIAL_ERRORS,
+				SetlistUrl:      "https://alexisonfire",
+				RequestedSongs:  2,
+				MatchedSongs:    1,
+				UnmatchedTitles: []string{"Crisis"},
+			},
+		},
+		"setlist failed": {
+			testCase: someSetlistsFailTestCase(),
+			expected: event.CreatedPlaylistArtist{
+				Name:            "Alexisonfire",
+				Tracks:          []event.CreatedPlaylistTrack{},
+				Status:          event.ARTIST_CREATION_FAILED,
+				UnmatchedTitles: []string{},
+				ErrorCategory:   event.ARTIST_ERROR_SETLIST_NOT_FOUND,
+			},
+		},
+		"setlist empty": {
+			testCase: someSetlistEmptyTestCase(),
+			expected: event.CreatedPlaylistArtist{
+				Name:            "Alexisonfire",
+				Tracks:          []event.CreatedPlaylistTrack{},
+				Status:          event.ARTIST_CREATION_FAILED,
+				SetlistUrl:      "https://empty_setlist",
+				UnmatchedTitles: []string{},
+				ErrorCategory:   event.ARTIST_ERROR_NO_SONGS_FOUND,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			subject := event.NewBaseNotifier[event.PlaylistCreatedEvent]()
+			fakeObserver := event.NewFakeObserver[event.PlaylistCreatedEvent]()
+			subject.AddObserver(fakeObserver)
+			playlistRepository, setlistRepository, songRepository := testSetup(test.testCase)
+			service := NewBasePlaylistService(
+				playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+			service.SetPlaylistCreateNotifier(subject)
+
+			_, err := service.CreatePlaylistWithArtists(
+				testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions(),
+			)
+
+			assert.Nil(t, err)
+			assert.Len(t, fakeObserver.GetEvents(), 1)
+			assert.Equal(t, test.expected, fakeObserver.GetEvents()[0].Event.Playlist.Artists[0])
+		})
+	}
+}
+
+func TestCreatePlaylistNotifiesConfiguredPlaylistType(t *testing.T) {
+	subject := event.NewBaseNotifier[event.PlaylistCreatedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistCreatedEvent]()
+	subject.AddObserver(fakeObserver)
+	playlistRepository, setlistRepository, songRepository := testSetup(mainTestCase())
+	service := NewBasePlaylistService(
+		playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetPlaylistCreateNotifier(subject)
+	service.SetPlaylistType(event.PLAYLIST_TYPE_APPLE_MUSIC)
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	assert.Nil(t, err)
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, event.PLAYLIST_TYPE_APPLE_MUSIC, fakeObserver.GetEvents()[0].Event.Playlist.Type)
+}
+
+func TestCreatePlaylistAddsSongsInSetlistOrder(t *testing.T) {
+	titles := []string{"Crisis", "Accidents", "Boiled Frogs", "Young Cardinals", "Pulmonary Archery", "Sharks"}
+	setlistSongs := make([]setlist.Song, len(titles))
+	expectedSongs := make([]song.Song, len(titles))
+	songRepository := songmocks.NewSongRepositoryMock()
+	for i, title := range titles {
+		setlistSongs[i] = setlist.NewSong(title)
+		expectedSongs[i] = song.NewSong(fmt.Sprintf("http://some_url%d", i))
+		songRepository.On("GetSong", testContext(), artistName, title).Return(expectedSongs[i], nil)
+	}
+	setlistRepository := setlistmocks.NewSetlistRepositoryMock()
+	setlistRepository.On("GetSetlist", artistName, mock.Anything).Return(
+		setlist.NewSetlist(artistName, setlistSongs, "https://alexisonfire"), nil,
+	)
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On("AddSongs", testContext(), playlistId, expectedSongs).Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, &setlistRepository, &songRepository, logging.NoopLogger{})
+	service.SetMaxSongWorkers(2)
+	service.SetMaxGlobalSongWorkers(3)
+
+	_, err := service.CreatePlaylistWithArtists(
+		testContext(),
+		testPlaylist(),
+		[]string{artistName},
+		DefaultCreationOptions(),
+	)
+
+	assert.Nil(t, err)
+	playlistRepository.AssertExpectations(t)
+}
+
+func TestCreatePlaylistDoesNotLookUpSongsOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext())
+	cancel()
+	_, setlistRepository, _ := testSetup(mainTestCase())
+	songRepository := songmocks.NewSongRepositoryMock()
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", ctx, testPlaylist()).Return(playlistId, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+
+	_, err := service.CreatePlaylistWithArtists(
+		ctx,
+		testPlaylist(),
+		testArtistNames(),
+		DefaultCreationOptions(),
+	)
+
+	assert.NotNil(t, err)
+	songRepository.AssertNotCalled(t, "GetSong", mock.Anything, mock.Anything, mock.Anything)
+	playlistRepository.AssertNotCalled(t, "AddSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePlaylistStopsWaitingBetweenArtistsOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext())
+	defer cancel()
+	testCase := mainTestCase()
+	setlistRepository := setlistmocks.NewSetlistRepositoryMock()
+	setlistRepository.On("GetSetlist", testCase[0].name, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(testCase[0].setlist.value, nil)
+	songRepository := songmocks.NewSongRepositoryMock()
+	songRepository.On("GetSong", mock.Anything, mock.Anything, mock.Anything).Return(song.Song{}, context.Canceled)
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	service := NewBasePlaylistService(&playlistRepository, &setlistRepository, &songRepository, logging.NoopLogger{})
+	service.SetAddSetlistSleep(int(time.Hour.Milliseconds()))
+
+	_, err := service.CreatePlaylistWithArtists(ctx, testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	setlistRepository.AssertNotCalled(t, "GetSetlist", testCase[1].name, mock.Anything)
+}
+
+func TestCreatePlaylistOnlyReportsSongsFromAddedChunks(t *testing.T) {
+	testCase := mainTestCase()[:1]
+	_, setlistRepository, songRepository := testSetup(testCase)
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	addSongsErr := &playlist.AddSongsError{
+		Chunks: []playlist.ChunkError{{Start: 1, End: 2, Err: errors.New("chunk test error")}},
+	}
+	playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Return([]string{snapshotId}, addSongsErr)
+	service := NewBasePlaylistService(
+		&playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	actual, err := service.CreatePlaylistWithArtists(
+		testContext(),
+		testPlaylist(),
+		[]string{testCase[0].name},
+		DefaultCreationOptions(),
+	)
+
+	expected := PlaylistCreation{
+		PlaylistId: playlistId,
+		SnapshotId: snapshotId,
+		Status:     Success,
+		Artists: []ArtistCreation{
+			{
+				Name:           testCase[0].name,
+				SetlistUrl:     "https://alexisonfire",
+				Songs:          []AddedSong{{SetlistTitle: "Crisis", Song: testCase[0].songs[0].value}},
+				RequestedSongs: 2,
+			},
+		},
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+const ownerId = "myUser"
+
+func addArtistsContext() context.Context {
+	return context.WithValue(testContext(), types.ContextKey("user_id"), ownerId)
+}
+
+func existingPlaylist(owner string) playlist.Playlist {
+	return playlist.Playlist{PlaylistDetails: testPlaylist(), Id: playlistId, OwnerId: owner}
+}
+
+func TestAddArtistsToPlaylistSkipsSongsAlreadyInPlaylist(t *testing.T) {
+	ctx := addArtistsContext()
+	testCase := mainTestCase()
+	setlistRepository := newSetlistRepositoryMock(testCase)
+	songRepository := songmocks.NewSongRepositoryMock()
+	for _, artist := range testCase {
+		for i, setlistSong := range artist.setlist.value.GetSongs() {
+			songRepository.On("GetSong", ctx, artist.name, setlistSong.GetTitle()).Return(artist.songs[i].value, nil)
+		}
+	}
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("GetPlaylist", ctx, playlistId).Return(existingPlaylist(ownerId), nil)
+	playlistRepository.On("GetSongs", ctx, playlistId).Return([]song.Song{testCase[0].songs[0].value}, nil)
+	playlistRepository.On(
+		"AddSongs", ctx, playlistId, []song.Song{testCase[0].songs[1].value, testCase[1].songs[0].value},
+	).Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+
+	actual, err := service.AddArtistsToPlaylist(ctx, playlistId, testArtistNames())
+
+	expected := PlaylistCreation{
+		PlaylistId: playlistId,
+		SnapshotId: snapshotId,
+		Status:     Success,
+		Artists: []ArtistCreation{
+			{
+				Name:           "Alexisonfire",
+				SetlistUrl:     "https://alexisonfire",
+				Songs:          []AddedSong{{SetlistTitle: "Accidents", Song: testCase[0].songs[1].value}},
+				RequestedSongs: 2,
+			},
+			{
+				Name:           "AFI",
+				SetlistUrl:     "https://afi",
+				Songs:          []AddedSong{{SetlistTitle: "Silver and cold", Song: testCase[1].songs[0].value}},
+				RequestedSongs: 1,
+			},
+		},
+		DuplicatesRemoved: 1,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	playlistRepository.AssertExpectations(t)
+}
+
+func TestAddArtistsToPlaylistReturnsErrorIfPlaylistNotOwned(t *testing.T) {
+	ctx := addArtistsContext()
+	playlistRepository, setlistRepository, songRepository := testSetup(mainTestCase())
+	playlistRepository.On("GetPlaylist", ctx, playlistId).Return(existingPlaylist("someoneElse"), nil)
+	service := NewBasePlaylistService(
+		playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	_, err := service.AddArtistsToPlaylist(ctx, playlistId, testArtistNames())
+
+	assert.ErrorIs(t, err, ErrPlaylistNotOwned)
+	playlistRepository.AssertNotCalled(t, "AddSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddArtistsToPlaylistReturnsErrorWhenUserIdMissing(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(mainTestCase())
+	service := NewBasePlaylistService(
+		playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	_, err := service.AddArtistsToPlaylist(testContext(), playlistId, testArtistNames())
+
+	assert.NotNil(t, err)
+}
+
+func duplicatedSongsTestCase() []TestArtist {
+	testArtists := mainTestCase()
+	testArtists[0].songs[0].value = song.Song{
+		Uri: "http://some_url1", Name: "Crisis", Isrc: "isrc1", Artists: []string{"Alexisonfire"},
+	}
+	testArtists[0].songs[1].value = song.Song{
+		Uri: "http://some_url2", Name: "Accidents", Isrc: "isrc2", Artists: []string{"Alexisonfire"},
+	}
+	testArtists[1].setlist.value = setlist.NewSetlist(
+		"AFI",
+		[]setlist.Song{setlist.NewSong("Crisis"), setlist.NewSong("Accidents"), setlist.NewSong("Silver and cold")},
+		"https://afi",
+	)
+	testArtists[1].songs = []SongResult{
+		{value: song.Song{Uri: "http://some_url1", Name: "Crisis", Isrc: "isrc1", Artists: []string{"Alexisonfire"}}},
+		{value: song.Song{
+			Uri: "http://other_url2", Name: "Accidents (Live)", Isrc: "isrc2", Artists: []string{"alexisonfire"},
+		}},
+		{value: song.Song{Uri: "http://some_url3", Name: "Silver and cold", Isrc: "isrc3", Artists: []string{"AFI"}}},
+	}
+	return testArtists
+}
+
+func TestCreatePlaylistRemovesDuplicatedSongs(t *testing.T) {
+	tests := map[string]struct {
+		options            DeduplicationOptions
+		expectedAfiUris    []string
+		expectedDuplicates int
+	}{
+		"disabled": {
+			options:            DeduplicationOptions{Disabled: true},
+			expectedAfiUris:    []string{"http://some_url1", "http://other_url2", "http://some_url3"},
+			expectedDuplicates: 0,
+		},
+		"by uri": {
+			options:            DeduplicationOptions{},
+			expectedAfiUris:    []string{"http://other_url2", "http://some_url3"},
+			expectedDuplicates: 1,
+		},
+		"by isrc": {
+			options:            DeduplicationOptions{ByIsrc: true},
+			expectedAfiUris:    []string{"http://some_url3"},
+			expectedDuplicates: 2,
+		},
+		"by title": {
+			options:            DeduplicationOptions{ByTitle: true},
+			expectedAfiUris:    []string{"http://some_url3"},
+			expectedDuplicates: 2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			testCase := duplicatedSongsTestCase()
+			setlistRepository := newSetlistRepositoryMock(testCase)
+			songRepository := newSongRepositoryMock(testCase)
+			playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+			playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+			playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+			service := NewBasePlaylistService(
+				&playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+			options := DefaultCreationOptions()
+			options.Deduplication = test.options
+
+			actual, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+
+			var afiUris []string
+			for _, addedSong := range actual.Artists[1].Songs {
+				afiUris = append(afiUris, addedSong.Song.GetUri())
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedAfiUris, afiUris)
+			assert.Equal(t, test.expectedDuplicates, actual.DuplicatesRemoved)
+		})
+	}
+}
+
+func TestCreatePlaylistKeepsSongsWithSameTitleFromOtherArtists(t *testing.T) {
+	testCase := duplicatedSongsTestCase()
+	testCase[1].songs[1].value = song.Song{Uri: "http://other_url2", Name: "Accidents", Artists: []string{"AFI"}}
+	setlistRepository := newSetlistRepositoryMock(testCase)
+	songRepository := newSongRepositoryMock(testCase)
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(&playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	options := DefaultCreationOptions()
+	options.Deduplication = DeduplicationOptions{ByTitle: true}
+
+	actual, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+
+	var afiUris []string
+	for _, addedSong := range actual.Artists[1].Songs {
+		afiUris = append(afiUris, addedSong.Song.GetUri())
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"http://other_url2", "http://some_url3"}, afiUris)
+	assert.Equal(t, 1, actual.DuplicatesRemoved)
+}
+
+func orderingTestCase() []TestArtist {
+	testArtists := mainTestCase()
+	testArtists[1].setlist.value = setlist.NewSetlist(
+		"AFI",
+		[]setlist.Song{setlist.NewSong("Silver and cold"), setlist.NewSong("Girl's not grey")},
+		"https://afi",
+	)
+	testArtists[1].songs = []SongResult{
+		{value: song.NewSong("http://some_url3")},
+		{value: song.NewSong("http://some_url4")},
+	}
+	return testArtists
+}
+
+func TestCreatePlaylistOrdersSongs(t *testing.T) {
+	tests := map[string]struct {
+		options      OrderingOptions
+		expectedUris []string
+	}{
+		"sequential": {
+			options:      OrderingOptions{Strategy: SequentialOrdering},
+			expectedUris: []string{"http://some_url1", "http://some_url2", "http://some_url3", "http://some_url4"},
+		},
+		"round robin": {
+			options:      OrderingOptions{Strategy: RoundRobinOrdering},
+			expectedUris: []string{"http://some_url1", "http://some_url3", "http://some_url2", "http://some_url4"},
+		},
+		"timetable": {
+			options: OrderingOptions{
+				Strategy: TimetableOrdering,
+				Timetable: map[string]time.Time{
+					"Alexisonfire": time.Date(2025, 6, 20, 22, 0, 0, 0, time.UTC),
+					"AFI":          time.Date(2025, 6, 20, 20, 0, 0, 0, time.UTC),
+				},
+			},
+			expectedUris: []string{"http://some_url3", "http://some_url4", "http://some_url1", "http://some_url2"},
+		},
+		"timetable without start times": {
+			options: OrderingOptions{
+				Strategy:  TimetableOrdering,
+				Timetable: map[string]time.Time{"AFI": time.Date(2025, 6, 20, 20, 0, 0, 0, time.UTC)},
+			},
+			expectedUris: []string{"http://some_url3", "http://some_url4", "http://some_url1", "http://some_url2"},
+		},
+		"headliners last": {
+			options:      OrderingOptions{Strategy: HeadlinersLastOrdering, Headliners: []string{"Alexisonfire"}},
+			expectedUris: []string{"http://some_url3", "http://some_url4", "http://some_url1", "http://some_url2"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			testCase := orderingTestCase()
+			var expectedSongs []song.Song
+			for _, uri := range test.expectedUris {
+				expectedSongs = append(expectedSongs, song.NewSong(uri))
+			}
+			playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+			playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+			playlistRepository.On("AddSongs", testContext(), playlistId, expectedSongs).Return([]string{snapshotId}, nil)
+			service := NewBasePlaylistService(
+				&playlistRepository, newSetlistRepositoryMock(testCase), newSongRepositoryMock(testCase), logging.NoopLogger{})
+			options := DefaultCreationOptions()
+			options.Ordering = test.options
+
+			actual, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expectedArtistCreations(testCase), actual.Artists)
+			playlistRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreatePlaylistShuffleAvoidsConsecutiveSongsFromSameArtist(t *testing.T) {
+	testCase := orderingTestCase()
+	var addedSongs []song.Song
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Run(func(args mock.Arguments) {
+		addedSongs = args.Get(2).([]song.Song)
+	}).Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, newSetlistRepositoryMock(testCase), newSongRepositoryMock(testCase), logging.NoopLogger{})
+	service.SetRandomSeed(42)
+	options := DefaultCreationOptions()
+	options.Ordering = OrderingOptions{Strategy: ShuffleOrdering}
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+
+	artistByUri := map[string]string{
+		"http://some_url1": "Alexisonfire",
+		"http://some_url2": "Alexisonfire",
+		"http://some_url3": "AFI",
+		"http://some_url4": "AFI",
+	}
+	assert.Nil(t, err)
+	assert.Len(t, addedSongs, 4)
+	for i := 1; i < len(addedSongs); i++ {
+		assert.NotEqual(t, artistByUri[addedSongs[i-1].Uri], artistByUri[addedSongs[i].Uri])
+	}
+}
+
+func TestCreatePlaylistShuffleIsReproducibleWithSeed(t *testing.T) {
+	testCase := orderingTestCase()
+	addedSongs := [][]song.Song{}
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Run(func(args mock.Arguments) {
+		addedSongs = append(addedSongs, args.Get(2).([]song.Song))
+	}).Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, newSetlistRepositoryMock(testCase), newSongRepositoryMock(testCase), logging.NoopLogger{})
+	service.SetRandomSeed(42)
+	options := DefaultCreationOptions()
+	options.Ordering = OrderingOptions{Strategy: ShuffleOrdering}
+
+	for range 2 {
+		_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+		assert.Nil(t, err)
+	}
+
+	assert.Len(t, addedSongs, 2)
+	assert.Equal(t, addedSongs[0], addedSongs[1])
+}
+
+func TestCreatePlaylistGeneratesDescriptionIfMissing(t *testing.T) {
+	tests := map[string]struct {
+		testCase            []TestArtist
+		expectedDescription string
+	}{
+		"all artists found": {
+			testCase: mainTestCase(),
+			expectedDescription: "Songs from the latest setlists of Alexisonfire and AFI. " +
+				"Sources: https://alexisonfire, https://afi",
+		},
+		"some setlists fail": {
+			testCase:            someSetlistsFailTestCase(),
+			expectedDescription: "Songs from the latest setlists of AFI. Sources: https://afi",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			details := testPlaylist()
+			details.Description = ""
+			expectedDetails := testPlaylist()
+			expectedDetails.Description = test.expectedDescription
+			playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+			playlistRepository.On("CreatePlaylist", testContext(), expectedDetails).Return(playlistId, nil)
+			playlistRepository.On("AddSongs", testContext(), playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+			service := NewBasePlaylistService(
+				&playlistRepository,
+				newSetlistRepositoryMock(test.testCase),
+				newSongRepositoryMock(test.testCase),
+				logging.NoopLogger{},
+			)
+
+			_, err := service.CreatePlaylistWithArtists(testContext(), details, testArtistNames(), DefaultCreationOptions())
+
+			assert.Nil(t, err)
+			playlistRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreatePlaylistDoesNotCreatePlaylistIfAllArtistsFail(t *testing.T) {
+	testCase := allSetlistsFailTestCase()
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	service := NewBasePlaylistService(
+		&playlistRepository, newSetlistRepositoryMock(testCase), newSongRepositoryMock(testCase), logging.NoopLogger{})
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	assert.NotNil(t, err)
+	playlistRepository.AssertNotCalled(t, "CreatePlaylist", mock.Anything, mock.Anything)
+}
+
+func chosenArtistSongs() []ArtistSongs {
+	return []ArtistSongs{
+		{
+			Name:       "Alexisonfire",
+			SetlistUrl: "https://alexisonfire",
+			Songs: []AddedSong{
+				{SetlistTitle: "Young Cardinals", Song: song.NewSong("spotify:track:2")},
+				{SetlistTitle: "Accidents", Song: song.NewSong("spotify:track:1")},
+			},
+		},
+		{Name: "AFI", SetlistUrl: "https://afi", Songs: []AddedSong{}},
+	}
+}
+
+func TestCreatePlaylistWithSongsAddsSongsWithoutLookingUpSetlists(t *testing.T) {
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", testContext(), testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On(
+		"AddSongs", testContext(), playlistId, []song.Song{song.NewSong("spotify:track:2"), song.NewSong("spotify:track:1")},
+	).Return([]string{snapshotId}, nil)
+	setlistRepository := setlistmocks.NewSetlistRepositoryMock()
+	songRepository := songmocks.NewSongRepositoryMock()
+	service := NewBasePlaylistService(&playlistRepository, &setlistRepository, &songRepository, logging.NoopLogger{})
+
+	actual, err := service.CreatePlaylistWithSongs(
+		testContext(), testPlaylist(), chosenArtistSongs(), DefaultCreationOptions(),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, playlistId, actual.PlaylistId)
+	assert.Equal(t, PartialFailure, actual.Status)
+	assert.Equal(t, chosenArtistSongs()[0].Songs, actual.Artists[0].Songs)
+	assert.NotNil(t, actual.Artists[1].Err)
+	playlistRepository.AssertExpectations(t)
+	setlistRepository.AssertNotCalled(t, "GetSetlist", mock.Anything, mock.Anything)
+	songRepository.AssertNotCalled(t, "GetSong", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePlaylistWithSongsDoesNotCreatePlaylistWithoutSongs(t *testing.T) {
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	setlistRepository := setlistmocks.NewSetlistRepositoryMock()
+	songRepository := songmocks.NewSongRepositoryMock()
+	service := NewBasePlaylistService(&playlistRepository, &setlistRepository, &songRepository, logging.NoopLogger{})
+	artists := []ArtistSongs{{Name: "Alexisonfire", Songs: []AddedSong{}}}
+
+	_, err := service.CreatePlaylistWithSongs(testContext(), testPlaylist(), artists, DefaultCreationOptions())
+
+	assert.NotNil(t, err)
+	playlistRepository.AssertNotCalled(t, "CreatePlaylist", mock.Anything, mock.Anything)
+}
+
+func TestCreatePlaylistReportsProgress(t *testing.T) {
+	testCase := mainTestCase()
+	testCase[0].SetFirstSongError()
+	testCase[1].SetSetlistError()
+	playlistRepository, setlistRepository, songRepository := testSetup(testCase)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	var events []ProgressEvent
+	options := DefaultCreationOptions()
+	options.Progress = func(progressEvent ProgressEvent) { events = append(events, progressEvent) }
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), options)
+
+	expected := []ProgressEvent{
+		{Type: SetlistFound, ArtistIndex: 0, Artist: "Alexisonfire", SetlistUrl: "https://alexisonfire", TotalSongs: 2},
+		{
+			Type:        SongResolved,
+			ArtistIndex: 0,
+			Artist:      "Alexisonfire",
+			SetlistUrl:  "https://alexisonfire",
+			SongTitle:   "Crisis",
+			SongStatus:  SongNotFound,
+			Err:         testCase[0].songs[0].err,
+		},
+		{
+			Type:        SongResolved,
+			ArtistIndex: 0,
+			Artist:      "Alexisonfire",
+			SetlistUrl:  "https://alexisonfire",
+			SongTitle:   "Accidents",
+			SongStatus:  SongMatched,
+		},
+		{
+			Type:         ArtistResolved,
+			ArtistIndex:  0,
+			Artist:       "Alexisonfire",
+			SetlistUrl:   "https://alexisonfire",
+			TotalSongs:   2,
+			MatchedSongs: 1,
+		},
+		{Type: ArtistFailed, ArtistIndex: 1, Artist: "AFI", Err: testCase[1].setlist.err},
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, events)
+}
+
+func TestCreatePlaylistReportsSongsBeforeLookingUpTheRest(t *testing.T) {
+	testCase := mainTestCase()[:1]
+	playlistRepository, setlistRepository, _ := testSetup(testCase)
+	reportedSongs := []string{}
+	var reportedBeforeLastLookup []string
+	songRepository := songmocks.NewSongRepositoryMock()
+	songRepository.On("GetSong", testContext(), testCase[0].name, "Crisis").Return(testCase[0].songs[0].value, nil)
+	songRepository.On("GetSong", testContext(), testCase[0].name, "Accidents").
+		Run(func(mock.Arguments) { reportedBeforeLastLookup = slices.Clone(reportedSongs) }).
+		Return(testCase[0].songs[1].value, nil)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+	service.SetMaxSongWorkers(1)
+	options := DefaultCreationOptions()
+	options.Progress = func(progressEvent ProgressEvent) {
+		if progressEvent.Type == SongResolved {
+			reportedSongs = append(reportedSongs, progressEvent.SongTitle)
+		}
+	}
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), []string{testCase[0].name}, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Crisis"}, reportedBeforeLastLookup)
+	assert.Equal(t, []string{"Crisis", "Accidents"}, reportedSongs)
+}
+
+func TestPreviewPlaylistReportsMatchesPerArtist(t *testing.T) {
+	testCase := mainTestCase()
+	testCase[0].SetFirstSongError()
+	testCase[1].songs[0].value = testCase[0].songs[1].value
+	playlistRepository, setlistRepository, songRepository := testSetup(testCase)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	actual, err := service.PreviewPlaylistWithArtists(testContext(), testArtistNames(), DeduplicationOptions{})
+
+	expected := PlaylistPreview{
+		Artists: []ArtistPreview{
+			{
+				Name:       "Alexisonfire",
+				SetlistUrl: "https://alexisonfire",
+				Songs: []SongMatch{
+					{SetlistTitle: "Crisis", Status: SongNotFound, Err: testCase[0].songs[0].err},
+					{SetlistTitle: "Accidents", Status: SongMatched, Song: testCase[0].songs[1].value},
+				},
+			},
+			{
+				Name:       "AFI",
+				SetlistUrl: "https://afi",
+				Songs: []SongMatch{
+					{SetlistTitle: "Silver and cold", Status: SongDuplicated, Song: testCase[1].songs[0].value},
+				},
+			},
+		},
+		MatchedSongs:      1,
+		UnmatchedSongs:    1,
+		DuplicatesRemoved: 1,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPreviewPlaylistReportsFailedArtists(t *testing.T) {
+	testCase := mainTestCase()
+	testCase[0].SetSetlistError()
+	testCase[1].SetAllSongsError()
+	playlistRepository, setlistRepository, songRepository := testSetup(testCase)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	actual, err := service.PreviewPlaylistWithArtists(testContext(), testArtistNames(), DeduplicationOptions{})
+
+	expected := PlaylistPreview{
+		Artists: []ArtistPreview{
+			{Name: "Alexisonfire", Err: testCase[0].setlist.err},
+			{
+				Name:       "AFI",
+				SetlistUrl: "https://afi",
+				Songs: []SongMatch{
+					{SetlistTitle: "Silver and cold", Status: SongNotFound, Err: testCase[1].songs[0].err},
+				},
+				Err: errors.New("no songs found for artist AFI"),
+			},
+		},
+		FailedArtists:  2,
+		UnmatchedSongs: 1,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPreviewPlaylistDoesNotModifyPlaylists(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(mainTestCase())
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+	_, err := service.PreviewPlaylistWithArtists(testContext(), testArtistNames(), DeduplicationOptions{})
+
+	assert.Nil(t, err)
+	playlistRepository.AssertNotCalled(t, "CreatePlaylist", mock.Anything, mock.Anything)
+	playlistRepository.AssertNotCalled(t, "AddSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreviewPlaylistReturnsErrorOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext())
+	cancel()
+	setlistRepository := newSetlistRepositoryMock(mainTestCase())
+	songRepository := songmocks.NewSongRepositoryMock()
+	playlistRepository := playlistmocks.NewPlaylistRepositoryMock()
+	service := NewBasePlaylistService(
+		&playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+
+	_, err := service.PreviewPlaylistWithArtists(ctx, testArtistNames(), DeduplicationOptions{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func failedAddSongsRepository() *playlistmocks.CleanablePlaylistRepositoryMock {
+	repository := playlistmocks.NewCleanablePlaylistRepositoryMock()
+	repository.On("CreatePlaylist", mock.Anything, testPlaylist()).Return(playlistId, nil)
+	repository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return(nil, errors.New("add test error"))
+	return &repository
+}
+
+func TestCreatePlaylistDeletesPlaylistIfNoSongsAdded(t *testing.T) {
+	_, setlistRepository, songRepository := testSetup(mainTestCase())
+	playlistRepository := failedAddSongsRepository()
+	playlistRepository.On("DeletePlaylist", mock.Anything, playlistId).Return(nil)
+	subject := event.NewBaseNotifier[event.PlaylistCreationFailedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistCreationFailedEvent]()
+	subject.AddObserver(fakeObserver)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetPlaylistFailureNotifier(subject)
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	creationErr, ok := AsCreationError(err)
+	assert.True(t, ok)
+	assert.Equal(t, CleanupReport{PlaylistId: playlistId, PlaylistDeleted: true}, creationErr.Cleanup)
+	playlistRepository.AssertExpectations(t)
+	expectedEvent := event.PlaylistCreationFailedEvent{
+		Playlist: event.FailedPlaylist{Id: playlistId, Name: playlistName, Type: event.PLAYLIST_TYPE_SPOTIFY},
+		Reason:   creationErr.Err.Error(),
+		Artists:  []event.FailedPlaylistArtist{},
+		Cleanup:  event.PlaylistCleanup{PlaylistDeleted: true, Errors: []string{}},
+	}
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, expectedEvent, fakeObserver.GetEvents()[0].Event)
+}
+
+func TestCreatePlaylistNotifiesFailureWhenAllSetlistsFail(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(allSetlistsFailTestCase())
+	subject := event.NewBaseNotifier[event.PlaylistCreationFailedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistCreationFailedEvent]()
+	subject.AddObserver(fakeObserver)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetPlaylistFailureNotifier(subject)
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	expectedEvent := event.PlaylistCreationFailedEvent{
+		Playlist: event.FailedPlaylist{Name: playlistName, Type: event.PLAYLIST_TYPE_SPOTIFY},
+		Reason:   err.Error(),
+		Artists: []event.FailedPlaylistArtist{
+			{Name: "Alexisonfire", Reason: "setlist test error"},
+			{Name: "AFI", Reason: "setlist test error"},
+		},
+		Cleanup: event.PlaylistCleanup{Errors: []string{}},
+	}
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, expectedEvent, fakeObserver.GetEvents()[0].Event)
+}
+
+func TestCreatePlaylistNotifiesSetlistsNotFound(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(someSetlistsFailTestCase())
+	subject := event.NewBaseNotifier[event.SetlistNotFoundEvent]()
+	fakeObserver := event.NewFakeObserver[event.SetlistNotFoundEvent]()
+	subject.AddObserver(fakeObserver)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetSetlistNotFoundNotifier(subject)
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	expectedEvent := event.SetlistNotFoundEvent{
+		Artist:       "Alexisonfire",
+		Reason:       "setlist test error",
+		PlaylistType: event.PLAYLIST_TYPE_SPOTIFY,
+	}
+	assert.Nil(t, err)
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, expectedEvent, fakeObserver.GetEvents()[0].Event)
+}
+
+func TestCreatePlaylistNotifiesSongsNotFound(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(someSongsFailedTestCase())
+	subject := event.NewBaseNotifier[event.SongNotFoundEvent]()
+	fakeObserver := event.NewFakeObserver[event.SongNotFoundEvent]()
+	subject.AddObserver(fakeObserver)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetSongNotFoundNotifier(subject)
+
+	_, err := service.CreatePlaylistWithArtists(testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	expectedEvent := event.SongNotFoundEvent{
+		Artist:       "Alexisonfire",
+		SetlistTitle: "Crisis",
+		SetlistUrl:   "https://alexisonfire",
+		Reason:       "song test error",
+		PlaylistType: event.PLAYLIST_TYPE_SPOTIFY,
+	}
+	assert.Nil(t, err)
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, expectedEvent, fakeObserver.GetEvents()[0].Event)
+}
+
+func TestPreviewPlaylistDoesNotNotifySongsNotFound(t *testing.T) {
+	playlistRepository, setlistRepository, songRepository := testSetup(someSongsFailedTestCase())
+	subject := event.NewBaseNotifier[event.SongNotFoundEvent]()
+	fakeObserver := event.NewFakeObserver[event.SongNotFoundEvent]()
+	subject.AddObserver(fakeObserver)
+	service := NewBasePlaylistService(playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+	service.SetSongNotFoundNotifier(subject)
+
+	_, err := service.PreviewPlaylistWithArtists(testContext(), testArtistNames(), DeduplicationOptions{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, fakeObserver.GetEvents())
+}
+
+func TestCreatePlaylistReportsCleanupErrors(t *testing.T) {
+	tests := map[string]struct {
+		playlistRepository playlist.PlaylistRepository
+		expectedCleanup    CleanupReport
+	}{
+		"delete fails": {
+			playlistRepository: func() playlist.PlaylistRepository {
+				repository := failedAddSongsRepository()
+				repository.On("DeletePlaylist", mock.Anything, playlistId).Return(errors.New("delete test error"))
+				return repository
+			}(),
+			expectedCleanup: CleanupReport{
+				PlaylistId: playlistId,
+				Errors:     []error{errors.New("could not delete playlist: delete test error")},
+			},
+		},
+		"delete not supported": {
+			playlistRepository: &failedAddSongsRepository().PlaylistRepositoryMock,
+			expectedCleanup: CleanupReport{
+				PlaylistId: playlistId,
+				Errors:     []error{errors.New("deleting playlists is not supported")},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, setlistRepository, songRepository := testSetup(mainTestCase())
+			service := NewBasePlaylistService(
+				test.playlistRepository, setlistRepository, songRepository, logging.NoopLogger{})
+
+			_, err := service.CreatePlaylistWithArtists(
+				testContext(), testPlaylist(), testArtistNames(), DefaultCreationOptions(),
+			)
+
+			creationErr, ok := AsCreationError(err)
+			assert.True(t, ok)
+			assert.Equal(t, test.expectedCleanup, creationErr.Cleanup)
+		})
+	}
+}
+
+func cancelledWhileAddingSongsSetup(
+	cancel context.CancelFunc,
+) (*playlistmocks.CleanablePlaylistRepositoryMock, BasePlaylistService) {
+	setlistRepository := newSetlistRepositoryMock(mainTestCase())
+	songRepository := songmocks.NewSongRepositoryMock()
+	songRepository.On("GetSong", mock.Anything, mock.Anything, mock.Anything).Return(song.NewSong("http://some_url"), nil)
+	playlistRepository := playlistmocks.NewCleanablePlaylistRepositoryMock()
+	playlistRepository.On("CreatePlaylist", mock.Anything, testPlaylist()).Return(playlistId, nil)
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return([]string{snapshotId}, nil)
+	service := NewBasePlaylistService(
+		&playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+	return &playlistRepository, service
+}
+
+func TestCreatePlaylistRollsBackOnCancelIfRequested(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext())
+	defer cancel()
+	playlistRepository, service := cancelledWhileAddingSongsSetup(cancel)
+	addedSongs := []song.Song{song.NewSong("http://some_url")}
+	playlistRepository.On("RemoveSongs", mock.Anything, playlistId, addedSongs).Return(nil)
+	playlistRepository.On("DeletePlaylist", mock.Anything, playlistId).Return(nil)
+	options := DefaultCreationOptions()
+	options.RollbackOnCancel = true
+
+	_, err := service.CreatePlaylistWithArtists(ctx, testPlaylist(), testArtistNames(), options)
+
+	creationErr, ok := AsCreationError(err)
+	assert.True(t, ok)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, CleanupReport{PlaylistId: playlistId, PlaylistDeleted: true, SongsRemoved: 1}, creationErr.Cleanup)
+	playlistRepository.AssertExpectations(t)
+}
+
+func TestCreatePlaylistKeepsPlaylistOnCancelByDefault(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext())
+	defer cancel()
+	playlistRepository, service := cancelledWhileAddingSongsSetup(cancel)
+
+	actual, err := service.CreatePlaylistWithArtists(ctx, testPlaylist(), testArtistNames(), DefaultCreationOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, playlistId, actual.PlaylistId)
+	playlistRepository.AssertNotCalled(t, "RemoveSongs", mock.Anything, mock.Anything, mock.Anything)
+	playlistRepository.AssertNotCalled(t, "DeletePlaylist", mock.Anything, mock.Anything)
+}
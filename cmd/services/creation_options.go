@@ -0,0 +1,22 @@
+package playlist
+
+// Controls which songs are considered the same when building a playlist.
+// Duplicates by URI are always removed unless deduplication is disabled
+type DeduplicationOptions struct {
+	Disabled bool
+	ByIsrc   bool
+	ByTitle  bool
+}
+
+type CreationOptions struct {
+	Deduplication DeduplicationOptions
+	Ordering      OrderingOptions
+	// Removes the songs already added and deletes the playlist when the request is cancelled
+	RollbackOnCancel bool
+	// Optional, notified as each artist is resolved
+	Progress ProgressListener
+}
+
+func DefaultCreationOptions() CreationOptions {
+	return CreationOptions{Ordering: OrderingOptions{Strategy: SequentialOrdering}}
+}
@@ -0,0 +1,34 @@
+package playlist
+
+type ProgressEventType string
+
+const (
+	SetlistFound   ProgressEventType = "setlist_found"
+	SongResolved   ProgressEventType = "song_resolved"
+	ArtistResolved ProgressEventType = "artist_resolved"
+	ArtistFailed   ProgressEventType = "artist_failed"
+)
+
+// Describes a step in the resolution of one of the artists of a playlist
+type ProgressEvent struct {
+	Type ProgressEventType
+	// Position of the artist in the request, since the same name could be requested twice
+	ArtistIndex  int
+	Artist       string
+	SetlistUrl   string
+	TotalSongs   int
+	MatchedSongs int
+	// Only set for song events
+	SongTitle  string
+	SongStatus SongMatchStatus
+	Err        error
+}
+
+// Receives the progress events of a playlist creation, which are sent sequentially
+type ProgressListener func(ProgressEvent)
+
+func (l ProgressListener) notify(event ProgressEvent) {
+	if l != nil {
+		l(event)
+	}
+}
@@ -21,7 +21,51 @@ func (s *PlaylistServiceMock) CreatePlaylistWithArtists(
 	ctx context.Context,
 	playlist playlist.PlaylistDetails,
 	artists []string,
+	options services.CreationOptions,
 ) (services.PlaylistCreation, error) {
-	args := s.Called(ctx, playlist, artists)
+	args := s.Called(ctx, playlist, artists, options)
 	return args.Get(0).(services.PlaylistCreation), args.Error(1)
 }
+
+func (s *PlaylistServiceMock) CreatePlaylistWithSongs(
+	ctx context.Context,
+	playlist playlist.PlaylistDetails,
+	artists []services.ArtistSongs,
+	options services.CreationOptions,
+) (services.PlaylistCreation, error) {
+	args := s.Called(ctx, playlist, artists, options)
+	return args.Get(0).(services.PlaylistCreation), args.Error(1)
+}
+
+func (s *PlaylistServiceMock) AddArtistsToPlaylist(
+	ctx context.Context,
+	playlistId string,
+	artists []string,
+) (services.PlaylistCreation, error) {
+	args := s.Called(ctx, playlistId, artists)
+	return args.Get(0).(services.PlaylistCreation), args.Error(1)
+}
+
+func (s *PlaylistServiceMock) PreviewPlaylistWithArtists(
+	ctx context.Context,
+	artists []string,
+	options services.DeduplicationOptions,
+) (services.PlaylistPreview, error) {
+	args := s.Called(ctx, artists, options)
+	return args.Get(0).(services.PlaylistPreview), args.Error(1)
+}
+
+func (s *PlaylistServiceMock) CheckPlaylistOwner(ctx context.Context, playlistId string) error {
+	args := s.Called(ctx, playlistId)
+	return args.Error(0)
+}
+
+func (s *PlaylistServiceMock) RefreshPlaylist(
+	ctx context.Context,
+	playlistId string,
+	artists []string,
+	options services.RefreshOptions,
+) (services.PlaylistRefresh, error) {
+	args := s.Called(ctx, playlistId, artists, options)
+	return args.Get(0).(services.PlaylistRefresh), args.Error(1)
+}
@@ -0,0 +1,43 @@
+package playlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spotify rejects playlist descriptions longer than this. Shared with the handlers validating requests
+const MaxDescriptionLength = 300
+
+func generatePlaylistDescription(artists []artistSongs) string {
+	var names []string
+	var sources []string
+	for _, artist := range artists {
+		if artist.setlistUrl == "" {
+			continue
+		}
+		names = append(names, artist.artist)
+		sources = append(sources, artist.setlistUrl)
+	}
+
+	description := fmt.Sprintf("Songs from the latest setlists of %s", joinNames(names))
+	withSources := fmt.Sprintf("%s. Sources: %s", description, strings.Join(sources, ", "))
+	if len([]rune(withSources)) <= MaxDescriptionLength {
+		return withSources
+	}
+	return truncate(description, MaxDescriptionLength)
+}
+
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return fmt.Sprintf("%s and %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
+
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(runes[:maxLength-3]) + "..."
+}
@@ -0,0 +1,207 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"festwrap/internal/event"
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+)
+
+func (s *BasePlaylistService) RefreshPlaylist(
+	ctx context.Context,
+	playlistId string,
+	artists []string,
+	options RefreshOptions,
+) (PlaylistRefresh, error) {
+	existingSongs, err := s.getOwnedPlaylistSongs(ctx, playlistId, "refreshing playlist")
+	if err != nil {
+		return PlaylistRefresh{}, err
+	}
+
+	resolution, err := s.resolveArtists(ctx, playlistId, artists, newSongDeduplicator(DeduplicationOptions{}), nil)
+	s.notifyResolutionFailures(resolution)
+	if err != nil {
+		return PlaylistRefresh{}, err
+	}
+
+	refresh := diffPlaylistSongs(playlistId, existingSongs, resolution, options.AddedSongUris)
+	if !options.RemoveDroppedSongs {
+		refresh.RemovedSongs = nil
+	} else if resolution.failures > 0 && len(refresh.RemovedSongs) > 0 {
+		s.logger.Warn(fmt.Sprintf("not removing songs from playlist %s since some artists failed", playlistId))
+		refresh.RemovedSongs = nil
+	} else if hasSongLookupErrors(resolution) && len(refresh.RemovedSongs) > 0 {
+		s.logger.Warn(fmt.Sprintf("not removing songs from playlist %s since some song lookups failed", playlistId))
+		refresh.RemovedSongs = nil
+	}
+
+	// Songs are added first, so the playlist is not left without songs if adding them fails
+	if len(refresh.AddedSongs) > 0 {
+		songs := make([]song.Song, len(refresh.AddedSongs))
+		for i, addedSong := range refresh.AddedSongs {
+			songs[i] = addedSong.Song
+		}
+		_, err = s.playlistRepository.AddSongs(ctx, playlistId, songs)
+		if addSongsErr, ok := playlist.AsAddSongsError(err); ok {
+			// Some chunks may have been added, so only the songs that made it into the playlist are reported
+			refresh.AddedSongs = excludeFailedAddedSongs(refresh.AddedSongs, addSongsErr)
+			if len(refresh.AddedSongs) == 0 {
+				return PlaylistRefresh{}, fmt.Errorf("could not add songs to playlist %s: %v", playlistId, err)
+			}
+			s.logger.Warn(fmt.Sprintf("could not add some songs to playlist %s: %v", playlistId, err))
+		} else if err != nil {
+			return PlaylistRefresh{}, fmt.Errorf("could not add songs to playlist %s: %v", playlistId, err)
+		}
+	}
+
+	if len(refresh.RemovedSongs) > 0 {
+		remover, ok := s.playlistRepository.(playlist.SongRemover)
+		if !ok {
+			return PlaylistRefresh{}, errors.New("removing songs is not supported")
+		}
+		if err = remover.RemoveSongs(ctx, playlistId, refresh.RemovedSongs); err != nil {
+			return PlaylistRefresh{}, fmt.Errorf("could not remove songs from playlist %s: %v", playlistId, err)
+		}
+	}
+
+	refresh.AddedSongUris = nextAddedSongUris(options.AddedSongUris, existingSongs, resolution, refresh)
+	s.logger.Info(fmt.Sprintf(
+		"refreshed playlist %s: %d songs added, %d removed",
+		playlistId, len(refresh.AddedSongs), len(refresh.RemovedSongs),
+	))
+	if len(refresh.AddedSongs) > 0 || len(refresh.RemovedSongs) > 0 {
+		s.notifyPlaylistUpdated(refresh)
+	}
+	return refresh, nil
+}
+
+// Compares the playlist songs with the ones resolved from the latest setlists. Only the songs earlier refreshes
+// added for an artist whose setlist was found are dropped, so songs added by hand or for other artists are kept
+func diffPlaylistSongs(
+	playlistId string,
+	existingSongs []song.Song,
+	resolution artistsResolution,
+	addedSongUris map[string][]string,
+) PlaylistRefresh {
+	existingByUri := map[string]song.Song{}
+	for _, existingSong := range existingSongs {
+		existingByUri[existingSong.Uri] = existingSong
+	}
+
+	refresh := PlaylistRefresh{PlaylistId: playlistId, FailedArtists: resolution.failures}
+	resolvedUris := map[string]bool{}
+	for _, resolvedArtist := range resolution.artists {
+		// Songs already added for another artist are still in the setlist, even though they are not added again
+		for _, match := range resolvedArtist.matches {
+			if match.Status == SongDuplicated {
+				resolvedUris[match.Song.Uri] = true
+			}
+		}
+		for _, resolvedSong := range resolvedArtist.songs {
+			resolvedUris[resolvedSong.Song.Uri] = true
+			if _, ok := existingByUri[resolvedSong.Song.Uri]; !ok {
+				refresh.AddedSongs = append(refresh.AddedSongs, resolvedSong)
+			}
+		}
+	}
+
+	removedUris := map[string]bool{}
+	for _, resolvedArtist := range resolution.artists {
+		if resolvedArtist.err != nil {
+			continue
+		}
+		for _, uri := range addedSongUris[resolvedArtist.artist] {
+			existingSong, ok := existingByUri[uri]
+			if ok && !resolvedUris[uri] && !removedUris[uri] {
+				removedUris[uri] = true
+				refresh.RemovedSongs = append(refresh.RemovedSongs, existingSong)
+			}
+		}
+	}
+	return refresh
+}
+
+// Songs that were not found do not count, since they are not expected to be found in later lookups either
+func hasSongLookupErrors(resolution artistsResolution) bool {
+	for _, resolvedArtist := range resolution.artists {
+		for _, unmatchedSong := range resolvedArtist.unmatched {
+			if !errors.Is(unmatchedSong.Err, song.ErrSongNotFound) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Keeps track of the songs added for each artist, forgetting the ones no longer in the playlist
+func nextAddedSongUris(
+	previous map[string][]string,
+	existingSongs []song.Song,
+	resolution artistsResolution,
+	refresh PlaylistRefresh,
+) map[string][]string {
+	existingUris := map[string]bool{}
+	for _, existingSong := range existingSongs {
+		existingUris[existingSong.Uri] = true
+	}
+	for _, removedSong := range refresh.RemovedSongs {
+		existingUris[removedSong.Uri] = false
+	}
+	addedUris := map[string]bool{}
+	for _, addedSong := range refresh.AddedSongs {
+		addedUris[addedSong.Song.Uri] = true
+	}
+
+	var next map[string][]string
+	for _, resolvedArtist := range resolution.artists {
+		var uris []string
+		for _, uri := range previous[resolvedArtist.artist] {
+			if existingUris[uri] {
+				uris = append(uris, uri)
+			}
+		}
+		for _, resolvedSong := range resolvedArtist.songs {
+			if addedUris[resolvedSong.Song.Uri] {
+				uris = append(uris, resolvedSong.Song.Uri)
+			}
+		}
+		if len(uris) > 0 {
+			if next == nil {
+				next = map[string][]string{}
+			}
+			next[resolvedArtist.artist] = uris
+		}
+	}
+	return next
+}
+
+func excludeFailedAddedSongs(addedSongs []AddedSong, addSongsErr *playlist.AddSongsError) []AddedSong {
+	var result []AddedSong
+	for i, addedSong := range addedSongs {
+		if !addSongsErr.IsFailed(i) {
+			result = append(result, addedSong)
+		}
+	}
+	return result
+}
+
+func (s *BasePlaylistService) notifyPlaylistUpdated(refresh PlaylistRefresh) {
+	addedTracks := make([]event.CreatedPlaylistTrack, len(refresh.AddedSongs))
+	for i, addedSong := range refresh.AddedSongs {
+		addedTracks[i] = newCreatedPlaylistTrack(addedSong)
+	}
+	removedTracks := make([]event.RemovedPlaylistTrack, len(refresh.RemovedSongs))
+	for i, removedSong := range refresh.RemovedSongs {
+		removedTracks[i] = event.RemovedPlaylistTrack{Id: removedSong.Id, Uri: removedSong.Uri, Name: removedSong.Name}
+	}
+
+	updatedEvent := event.PlaylistUpdatedEvent{
+		Playlist:      event.UpdatedPlaylist{Id: refresh.PlaylistId, Type: s.playlistType},
+		AddedTracks:   addedTracks,
+		RemovedTracks: removedTracks,
+	}
+	s.playlistUpdateNotifier.Notify(event.NewEventWrapper(updatedEvent))
+}
@@ -0,0 +1,263 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"festwrap/internal/event"
+	"festwrap/internal/logging"
+	"festwrap/internal/playlist"
+	playlistmocks "festwrap/internal/playlist/mocks"
+	"festwrap/internal/song"
+	songmocks "festwrap/internal/song/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func droppedSong() song.Song {
+	return song.Song{Id: "dropped", Uri: "http://dropped_url", Name: "Dropped"}
+}
+
+// Options of a refresh where an earlier one added the dropped song for the first artist
+func removeDroppedSongsOptions() RefreshOptions {
+	return RefreshOptions{
+		RemoveDroppedSongs: true,
+		AddedSongUris:      map[string][]string{"Alexisonfire": {droppedSong().Uri}},
+	}
+}
+
+func refreshSetup(testCase []TestArtist) (BasePlaylistService, *playlistmocks.CleanablePlaylistRepositoryMock) {
+	setlistRepository := newSetlistRepositoryMock(testCase)
+	songRepository := songmocks.NewSongRepositoryMock()
+	for _, artist := range testCase {
+		for i, setlistSong := range artist.setlist.value.GetSongs() {
+			songRepository.On(
+				"GetSong", mock.Anything, artist.name, setlistSong.GetTitle(),
+			).Return(artist.songs[i].value, artist.songs[i].err)
+		}
+	}
+
+	playlistRepository := playlistmocks.NewCleanablePlaylistRepositoryMock()
+	playlistRepository.On("GetPlaylist", mock.Anything, playlistId).Return(existingPlaylist(ownerId), nil)
+	playlistRepository.On(
+		"GetSongs", mock.Anything, playlistId,
+	).Return([]song.Song{testCase[0].songs[0].value, droppedSong()}, nil)
+
+	service := NewBasePlaylistService(&playlistRepository, setlistRepository, &songRepository, logging.NoopLogger{})
+	return service, &playlistRepository
+}
+
+func TestRefreshPlaylistAddsNewSongs(t *testing.T) {
+	testCase := mainTestCase()
+	service, playlistRepository := refreshSetup(testCase)
+	newSongs := []song.Song{testCase[0].songs[1].value, testCase[1].songs[0].value}
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, newSongs).Return([]string{snapshotId}, nil)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), RefreshOptions{})
+
+	expected := PlaylistRefresh{
+		PlaylistId: playlistId,
+		AddedSongs: []AddedSong{
+			{SetlistTitle: "Accidents", Song: testCase[0].songs[1].value},
+			{SetlistTitle: "Silver and cold", Song: testCase[1].songs[0].value},
+		},
+		AddedSongUris: map[string][]string{
+			"Alexisonfire": {testCase[0].songs[1].value.Uri},
+			"AFI":          {testCase[1].songs[0].value.Uri},
+		},
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	playlistRepository.AssertExpectations(t)
+	playlistRepository.AssertNotCalled(t, "RemoveSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshPlaylistRemovesDroppedSongsIfRequested(t *testing.T) {
+	service, playlistRepository := refreshSetup(mainTestCase())
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+	playlistRepository.On("RemoveSongs", mock.Anything, playlistId, []song.Song{droppedSong()}).Return(nil)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []song.Song{droppedSong()}, actual.RemovedSongs)
+	assert.NotContains(t, actual.AddedSongUris["Alexisonfire"], droppedSong().Uri)
+	playlistRepository.AssertExpectations(t)
+}
+
+func TestRefreshPlaylistOnlyRemovesSongsAddedByEarlierRefreshes(t *testing.T) {
+	tests := map[string]struct {
+		addedSongUris map[string][]string
+	}{
+		"song added by hand": {
+			addedSongUris: nil,
+		},
+		"song added for an artist not refreshed": {
+			addedSongUris: map[string][]string{"Municipal Waste": {droppedSong().Uri}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, playlistRepository := refreshSetup(mainTestCase())
+			playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+			options := RefreshOptions{RemoveDroppedSongs: true, AddedSongUris: test.addedSongUris}
+
+			actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), options)
+
+			assert.Nil(t, err)
+			assert.Empty(t, actual.RemovedSongs)
+			playlistRepository.AssertNotCalled(t, "RemoveSongs", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRefreshPlaylistKeepsSongsIfSomeSongLookupFails(t *testing.T) {
+	testCase := mainTestCase()
+	testCase[1].songs[0].err = errors.New("too many requests")
+	service, playlistRepository := refreshSetup(testCase)
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+	assert.Nil(t, err)
+	assert.Empty(t, actual.RemovedSongs)
+	assert.Contains(t, actual.AddedSongUris["Alexisonfire"], droppedSong().Uri)
+	playlistRepository.AssertNotCalled(t, "RemoveSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshPlaylistRemovesDroppedSongsIfSomeSongsAreNotFound(t *testing.T) {
+	testCase := mainTestCase()
+	testCase[0].songs[1].err = fmt.Errorf("no songs found: %w", song.ErrSongNotFound)
+	service, playlistRepository := refreshSetup(testCase)
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+	playlistRepository.On("RemoveSongs", mock.Anything, playlistId, []song.Song{droppedSong()}).Return(nil)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []song.Song{droppedSong()}, actual.RemovedSongs)
+	playlistRepository.AssertExpectations(t)
+}
+
+func TestRefreshPlaylistKeepsSongsIfSomeArtistFails(t *testing.T) {
+	testCase := someSetlistsFailTestCase()
+	service, playlistRepository := refreshSetup(testCase)
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, actual.FailedArtists)
+	assert.Empty(t, actual.RemovedSongs)
+	playlistRepository.AssertNotCalled(t, "RemoveSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshPlaylistNotifiesChanges(t *testing.T) {
+	testCase := mainTestCase()
+	service, playlistRepository := refreshSetup(testCase)
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+	playlistRepository.On("RemoveSongs", mock.Anything, playlistId, mock.Anything).Return(nil)
+	subject := event.NewBaseNotifier[event.PlaylistUpdatedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistUpdatedEvent]()
+	subject.AddObserver(fakeObserver)
+	service.SetPlaylistUpdateNotifier(subject)
+
+	_, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+	expected := event.PlaylistUpdatedEvent{
+		Playlist: event.UpdatedPlaylist{Id: playlistId, Type: event.PLAYLIST_TYPE_SPOTIFY},
+		AddedTracks: []event.CreatedPlaylistTrack{
+			{SetlistTitle: "Accidents", Uri: testCase[0].songs[1].value.Uri, Artists: []string{}},
+			{SetlistTitle: "Silver and cold", Uri: testCase[1].songs[0].value.Uri, Artists: []string{}},
+		},
+		RemovedTracks: []event.RemovedPlaylistTrack{{Id: "dropped", Uri: "http://dropped_url", Name: "Dropped"}},
+	}
+	assert.Nil(t, err)
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Equal(t, expected, fakeObserver.GetEvents()[0].Event)
+}
+
+func TestRefreshPlaylistReportsSongsAddedBeforeFailing(t *testing.T) {
+	testCase := mainTestCase()
+	service, playlistRepository := refreshSetup(testCase)
+	addSongsErr := &playlist.AddSongsError{
+		Chunks: []playlist.ChunkError{{Start: 1, End: 2, Err: errors.New("chunk test error")}},
+	}
+	playlistRepository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, addSongsErr)
+	subject := event.NewBaseNotifier[event.PlaylistUpdatedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistUpdatedEvent]()
+	subject.AddObserver(fakeObserver)
+	service.SetPlaylistUpdateNotifier(subject)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), RefreshOptions{})
+
+	expectedSongs := []AddedSong{{SetlistTitle: "Accidents", Song: testCase[0].songs[1].value}}
+	assert.Nil(t, err)
+	assert.Equal(t, expectedSongs, actual.AddedSongs)
+	assert.Equal(t, map[string][]string{"Alexisonfire": {testCase[0].songs[1].value.Uri}}, actual.AddedSongUris)
+	assert.Len(t, fakeObserver.GetEvents(), 1)
+	assert.Len(t, fakeObserver.GetEvents()[0].Event.AddedTracks, 1)
+}
+
+func TestRefreshPlaylistDoesNotNotifyWithoutChanges(t *testing.T) {
+	testCase := mainTestCase()
+	service, playlistRepository := refreshSetup(testCase)
+	playlistRepository.ExpectedCalls = nil
+	playlistRepository.On("GetPlaylist", mock.Anything, playlistId).Return(existingPlaylist(ownerId), nil)
+	playlistRepository.On("GetSongs", mock.Anything, playlistId).Return(
+		[]song.Song{testCase[0].songs[0].value, testCase[0].songs[1].value, testCase[1].songs[0].value}, nil,
+	)
+	subject := event.NewBaseNotifier[event.PlaylistUpdatedEvent]()
+	fakeObserver := event.NewFakeObserver[event.PlaylistUpdatedEvent]()
+	subject.AddObserver(fakeObserver)
+	service.SetPlaylistUpdateNotifier(subject)
+
+	actual, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), RefreshOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, PlaylistRefresh{PlaylistId: playlistId}, actual)
+	assert.Empty(t, fakeObserver.GetEvents())
+	playlistRepository.AssertNotCalled(t, "AddSongs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshPlaylistReturnsErrorIfPlaylistNotOwned(t *testing.T) {
+	service, playlistRepository := refreshSetup(mainTestCase())
+	playlistRepository.ExpectedCalls = nil
+	playlistRepository.On("GetPlaylist", mock.Anything, playlistId).Return(existingPlaylist("someoneElse"), nil)
+
+	_, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), RefreshOptions{})
+
+	assert.ErrorIs(t, err, ErrPlaylistNotOwned)
+}
+
+func TestRefreshPlaylistReturnsErrorOnRepositoryErrors(t *testing.T) {
+	tests := map[string]struct {
+		setupRepository func(repository *playlistmocks.CleanablePlaylistRepositoryMock)
+	}{
+		"add fails": {
+			setupRepository: func(repository *playlistmocks.CleanablePlaylistRepositoryMock) {
+				repository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return(nil, errors.New("test error"))
+			},
+		},
+		"remove fails": {
+			setupRepository: func(repository *playlistmocks.CleanablePlaylistRepositoryMock) {
+				repository.On("AddSongs", mock.Anything, playlistId, mock.Anything).Return([]string{snapshotId}, nil)
+				repository.On("RemoveSongs", mock.Anything, playlistId, mock.Anything).Return(errors.New("test error"))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, playlistRepository := refreshSetup(mainTestCase())
+			test.setupRepository(playlistRepository)
+
+			_, err := service.RefreshPlaylist(addArtistsContext(), playlistId, testArtistNames(), removeDroppedSongsOptions())
+
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -2,10 +2,15 @@ package playlist
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"festwrap/internal/playlist"
+	"festwrap/internal/song"
 )
 
+var ErrPlaylistNotOwned = errors.New("playlist is not owned by the current user")
+
 type CreationStatus int
 
 const (
@@ -13,9 +18,129 @@ const (
 	PartialFailure
 )
 
+// Song added to the playlist along with the setlist title it was resolved from
+type AddedSong struct {
+	SetlistTitle string
+	Song         song.Song
+}
+
+// Setlist song that could not be matched to any song in the streaming service
+type UnmatchedSong struct {
+	SetlistTitle string
+	Err          error
+}
+
+type ArtistCreation struct {
+	Name           string
+	SetlistUrl     string
+	Songs          []AddedSong
+	UnmatchedSongs []UnmatchedSong
+	// Number of songs in the setlist, and the reason none of them could be added, if so
+	RequestedSongs int
+	Err            error
+}
+
 type PlaylistCreation struct {
-	PlaylistId string
-	Status     CreationStatus
+	PlaylistId        string
+	SnapshotId        string
+	Status            CreationStatus
+	Artists           []ArtistCreation
+	DuplicatesRemoved int
+}
+
+func (c PlaylistCreation) addedSongs() []song.Song {
+	var songs []song.Song
+	for _, artist := range c.Artists {
+		for _, addedSong := range artist.Songs {
+			songs = append(songs, addedSong.Song)
+		}
+	}
+	return songs
+}
+
+// Changes undone after the creation of a playlist failed
+type CleanupReport struct {
+	PlaylistId      string
+	PlaylistDeleted bool
+	SongsRemoved    int
+	Errors          []error
+}
+
+// Returned when a playlist creation fails after the playlist was created
+type CreationError struct {
+	Err     error
+	Cleanup CleanupReport
+}
+
+func (e *CreationError) Error() string {
+	return fmt.Sprintf("could not create playlist %s: %v", e.Cleanup.PlaylistId, e.Err)
+}
+
+func (e *CreationError) Unwrap() error {
+	return e.Err
+}
+
+func AsCreationError(err error) (*CreationError, bool) {
+	var creationErr *CreationError
+	ok := errors.As(err, &creationErr)
+	return creationErr, ok
+}
+
+type SongMatchStatus string
+
+const (
+	SongMatched    SongMatchStatus = "matched"
+	SongDuplicated SongMatchStatus = "duplicated"
+	SongNotFound   SongMatchStatus = "not_found"
+)
+
+// Outcome of looking up a setlist song. Song is empty when it was not found, in which case Err holds the reason
+type SongMatch struct {
+	SetlistTitle string
+	Status       SongMatchStatus
+	Song         song.Song
+	Err          error
+}
+
+type ArtistPreview struct {
+	Name       string
+	SetlistUrl string
+	Songs      []SongMatch
+	// Set when the artist would not contribute any song to the playlist
+	Err error
+}
+
+type PlaylistPreview struct {
+	Artists           []ArtistPreview
+	FailedArtists     int
+	MatchedSongs      int
+	UnmatchedSongs    int
+	DuplicatesRemoved int
+}
+
+type RefreshOptions struct {
+	// Removes the songs no longer in the setlists, unless some artist or song lookup fails so temporary errors
+	// do not empty playlists
+	RemoveDroppedSongs bool
+	// Uris of the songs earlier refreshes added for each artist. Only these songs are ever removed
+	AddedSongUris map[string][]string
+}
+
+// Changes applied to a playlist to match the latest setlists of its artists
+type PlaylistRefresh struct {
+	PlaylistId    string
+	AddedSongs    []AddedSong
+	RemovedSongs  []song.Song
+	FailedArtists int
+	// Songs added by this and earlier refreshes still in the playlist, to be passed to the next refresh
+	AddedSongUris map[string][]string
+}
+
+// Songs chosen for an artist, such as the ones of a preview edited by the user
+type ArtistSongs struct {
+	Name       string
+	SetlistUrl string
+	Songs      []AddedSong
 }
 
 type PlaylistService interface {
@@ -23,5 +148,34 @@ type PlaylistService interface {
 		ctx context.Context,
 		playlist playlist.PlaylistDetails,
 		artists []string,
+		options CreationOptions,
+	) (PlaylistCreation, error)
+	// Creates a playlist with the songs of each artist, such as the ones of an edited preview
+	CreatePlaylistWithSongs(
+		ctx context.Context,
+		playlist playlist.PlaylistDetails,
+		artists []ArtistSongs,
+		options CreationOptions,
 	) (PlaylistCreation, error)
+	// Resolves the songs a new playlist would have for the given artists, without creating it
+	PreviewPlaylistWithArtists(
+		ctx context.Context,
+		artists []string,
+		options DeduplicationOptions,
+	) (PlaylistPreview, error)
+	// Appends the setlists of the given artists to a playlist owned by the current user
+	AddArtistsToPlaylist(
+		ctx context.Context,
+		playlistId string,
+		artists []string,
+	) (PlaylistCreation, error)
+	// Returns ErrPlaylistNotOwned when the playlist is not owned by the current user
+	CheckPlaylistOwner(ctx context.Context, playlistId string) error
+	// Updates a playlist owned by the current user with the latest setlists of the given artists
+	RefreshPlaylist(
+		ctx context.Context,
+		playlistId string,
+		artists []string,
+		options RefreshOptions,
+	) (PlaylistRefresh, error)
 }
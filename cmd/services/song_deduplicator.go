@@ -0,0 +1,64 @@
+package playlist
+
+import (
+	"fmt"
+	"strings"
+
+	"festwrap/internal/song"
+	"festwrap/internal/str"
+)
+
+// Keeps track of the songs in a playlist to detect duplicates. A nil deduplicator detects no duplicates
+type songDeduplicator struct {
+	options DeduplicationOptions
+	seen    map[string]bool
+}
+
+func newSongDeduplicator(options DeduplicationOptions) *songDeduplicator {
+	if options.Disabled {
+		return nil
+	}
+	return &songDeduplicator{options: options, seen: make(map[string]bool)}
+}
+
+func (d *songDeduplicator) IsDuplicate(s song.Song) bool {
+	if d == nil {
+		return false
+	}
+	for _, key := range d.keys(s) {
+		if d.seen[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *songDeduplicator) Add(s song.Song) {
+	if d == nil {
+		return
+	}
+	for _, key := range d.keys(s) {
+		d.seen[key] = true
+	}
+}
+
+func (d *songDeduplicator) keys(s song.Song) []string {
+	keys := []string{fmt.Sprintf("uri:%s", s.GetUri())}
+	if d.options.ByIsrc && s.Isrc != "" {
+		keys = append(keys, fmt.Sprintf("isrc:%s", s.Isrc))
+	}
+	// Different artists may have songs with the same title, so only the ones of the same primary artist match
+	if d.options.ByTitle {
+		if title := str.NormalizeTitle(s.Name); title != "" {
+			keys = append(keys, fmt.Sprintf("title:%s:%s", primaryArtist(s), title))
+		}
+	}
+	return keys
+}
+
+func primaryArtist(s song.Song) string {
+	if len(s.Artists) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(s.Artists[0]))
+}
@@ -0,0 +1,191 @@
+package playlist
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+type OrderingStrategy string
+
+const (
+	// Each artist block goes after the other, in the requested order
+	SequentialOrdering OrderingStrategy = "sequential"
+	// Takes one song from each artist in turns
+	RoundRobinOrdering OrderingStrategy = "round_robin"
+	// Artist blocks sorted by their start time. Artists without one go last
+	TimetableOrdering OrderingStrategy = "timetable"
+	// Random order, avoiding consecutive songs from the same artist when possible
+	ShuffleOrdering OrderingStrategy = "shuffle"
+	// Artist blocks in the requested order, moving the headliners to the end
+	HeadlinersLastOrdering OrderingStrategy = "headliners_last"
+)
+
+func IsValidOrderingStrategy(strategy OrderingStrategy) bool {
+	switch strategy {
+	case SequentialOrdering, RoundRobinOrdering, TimetableOrdering, ShuffleOrdering, HeadlinersLastOrdering:
+		return true
+	default:
+		return false
+	}
+}
+
+type OrderingOptions struct {
+	Strategy   OrderingStrategy
+	Timetable  map[string]time.Time
+	Headliners []string
+}
+
+type artistSongs struct {
+	artist     string
+	setlistUrl string
+	songs      []AddedSong
+	unmatched  []UnmatchedSong
+	matches    []SongMatch
+	err        error
+}
+
+// Song in the final playlist order, along with the index of the artist it belongs to
+type orderedSong struct {
+	artistIndex int
+	song        AddedSong
+}
+
+func orderSongs(artists []artistSongs, options OrderingOptions, random *rand.Rand) []orderedSong {
+	switch options.Strategy {
+	case RoundRobinOrdering:
+		return roundRobinOrder(artists)
+	case TimetableOrdering:
+		return blocksOrder(artists, timetableArtistOrder(artists, options.Timetable))
+	case ShuffleOrdering:
+		return shuffleOrder(artists, random)
+	case HeadlinersLastOrdering:
+		return blocksOrder(artists, headlinersLastArtistOrder(artists, options.Headliners))
+	default:
+		return blocksOrder(artists, requestArtistOrder(artists))
+	}
+}
+
+func requestArtistOrder(artists []artistSongs) []int {
+	order := make([]int, len(artists))
+	for i := range artists {
+		order[i] = i
+	}
+	return order
+}
+
+func timetableArtistOrder(artists []artistSongs, timetable map[string]time.Time) []int {
+	order := requestArtistOrder(artists)
+	slices.SortStableFunc(order, func(i, j int) int {
+		iTime, iFound := timetable[artists[i].artist]
+		jTime, jFound := timetable[artists[j].artist]
+		switch {
+		case iFound && jFound:
+			return iTime.Compare(jTime)
+		case iFound:
+			return -1
+		case jFound:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return order
+}
+
+func headlinersLastArtistOrder(artists []artistSongs, headliners []string) []int {
+	order := requestArtistOrder(artists)
+	slices.SortStableFunc(order, func(i, j int) int {
+		iHeadliner := slices.Contains(headliners, artists[i].artist)
+		jHeadliner := slices.Contains(headliners, artists[j].artist)
+		switch {
+		case iHeadliner == jHeadliner:
+			return 0
+		case iHeadliner:
+			return 1
+		default:
+			return -1
+		}
+	})
+	return order
+}
+
+func blocksOrder(artists []artistSongs, artistOrder []int) []orderedSong {
+	result := []orderedSong{}
+	for _, artistIndex := range artistOrder {
+		for _, song := range artists[artistIndex].songs {
+			result = append(result, orderedSong{artistIndex: artistIndex, song: song})
+		}
+	}
+	return result
+}
+
+func roundRobinOrder(artists []artistSongs) []orderedSong {
+	result := []orderedSong{}
+	for position := 0; ; position++ {
+		added := false
+		for artistIndex, artist := range artists {
+			if position < len(artist.songs) {
+				result = append(result, orderedSong{artistIndex: artistIndex, song: artist.songs[position]})
+				added = true
+			}
+		}
+		if !added {
+			return result
+		}
+	}
+}
+
+func shuffleOrder(artists []artistSongs, random *rand.Rand) []orderedSong {
+	remaining := make([][]AddedSong, len(artists))
+	total := 0
+	for i, artist := range artists {
+		remaining[i] = slices.Clone(artist.songs)
+		random.Shuffle(len(remaining[i]), func(a, b int) {
+			remaining[i][a], remaining[i][b] = remaining[i][b], remaining[i][a]
+		})
+		total += len(remaining[i])
+	}
+
+	result := make([]orderedSong, 0, total)
+	lastArtist := -1
+	for left := total; left > 0; left-- {
+		artistIndex := pickNextArtist(remaining, left, lastArtist, random)
+		result = append(result, orderedSong{artistIndex: artistIndex, song: remaining[artistIndex][0]})
+		remaining[artistIndex] = remaining[artistIndex][1:]
+		lastArtist = artistIndex
+	}
+	return result
+}
+
+// Picks a random artist with songs left, weighted by the number of songs, that differs from the
+// previous one. An artist holding more than half of the songs left is picked first, so the rest
+// of songs can still be spread between its own
+func pickNextArtist(remaining [][]AddedSong, left int, lastArtist int, random *rand.Rand) int {
+	candidates := []int{}
+	weight := 0
+	for artistIndex, songs := range remaining {
+		if len(songs) == 0 || artistIndex == lastArtist {
+			continue
+		}
+		if 2*len(songs) > left {
+			return artistIndex
+		}
+		candidates = append(candidates, artistIndex)
+		weight += len(songs)
+	}
+
+	// Only the previous artist has songs left, so we cannot avoid repeating it
+	if len(candidates) == 0 {
+		return lastArtist
+	}
+
+	target := random.IntN(weight)
+	for _, artistIndex := range candidates {
+		target -= len(remaining[artistIndex])
+		if target < 0 {
+			return artistIndex
+		}
+	}
+	return candidates[len(candidates)-1]
+}
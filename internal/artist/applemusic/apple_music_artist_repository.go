@@ -0,0 +1,84 @@
+package applemusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	types "festwrap/internal"
+	"festwrap/internal/artist"
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/serialization"
+)
+
+type AppleMusicArtistRepository struct {
+	developerTokenKey types.ContextKey
+	host              string
+	storefront        string
+	deserializer      serialization.Deserializer[appleMusicResponse]
+	httpSender        httpsender.HTTPRequestSender
+}
+
+func NewAppleMusicArtistRepository(httpSender httpsender.HTTPRequestSender) AppleMusicArtistRepository {
+	return AppleMusicArtistRepository{
+		developerTokenKey: "apple_music_token",
+		host:              "api.music.apple.com",
+		storefront:        "us",
+		deserializer:      serialization.NewJsonDeserializer[appleMusicResponse](),
+		httpSender:        httpSender,
+	}
+}
+
+func (r *AppleMusicArtistRepository) SearchArtist(
+	ctx context.Context,
+	name string,
+	limit int,
+) ([]artist.Artist, error) {
+	token, ok := ctx.Value(r.developerTokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve developer token from context while searching for artist")
+	}
+
+	responseBody, err := r.httpSender.Send(r.createSearchHttpOptions(name, limit, token))
+	if err != nil {
+		return nil, err
+	}
+
+	var response appleMusicResponse
+	err = r.deserializer.Deserialize(*responseBody, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.GetArtists(), nil
+}
+
+func (r *AppleMusicArtistRepository) createSearchHttpOptions(
+	name string,
+	limit int,
+	token string,
+) httpsender.HTTPRequestOptions {
+	queryParams := url.Values{}
+	queryParams.Set("types", "artists")
+	queryParams.Set("term", name)
+	queryParams.Set("limit", fmt.Sprint(limit))
+	url := fmt.Sprintf("https://%s/v1/catalog/%s/search?%s", r.host, r.storefront, queryParams.Encode())
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	httpOptions.SetHeaders(
+		map[string]string{"Authorization": fmt.Sprintf("Bearer %s", token)},
+	)
+	return httpOptions
+}
+
+func (r *AppleMusicArtistRepository) SetDeveloperTokenKey(key types.ContextKey) {
+	r.developerTokenKey = key
+}
+
+func (r *AppleMusicArtistRepository) SetHost(host string) {
+	r.host = host
+}
+
+func (r *AppleMusicArtistRepository) SetStorefront(storefront string) {
+	r.storefront = storefront
+}
@@ -0,0 +1,100 @@
+package applemusic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	types "festwrap/internal"
+	"festwrap/internal/artist"
+	"festwrap/internal/testtools"
+	"festwrap/internal/testtools/stubserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	searchName        = "Movements"
+	limit             = 2
+	developerTokenKey = types.ContextKey("developer")
+	developerToken    = "some_token"
+)
+
+func artistSearchResponse(t *testing.T) []byte {
+	t.Helper()
+	path := filepath.Join(testtools.GetParentDir(t), "testdata", "apple_music_artist_search_response.json")
+	return testtools.LoadTestDataOrError(t, path)
+}
+
+// Stub of the Apple Music catalog search, only answering requests with the expected parameters
+func searchStubServer(t *testing.T, responseBody []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/v1/catalog/us/search" ||
+			query.Get("term") != searchName ||
+			query.Get("types") != "artists" ||
+			query.Get("limit") != "2" ||
+			r.Header.Get("Authorization") != "Bearer some_token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write(responseBody)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func stubRepository(server *httptest.Server) AppleMusicArtistRepository {
+	repository := NewAppleMusicArtistRepository(stubserver.NewSender(server))
+	repository.SetHost(strings.TrimPrefix(server.URL, "https://"))
+	repository.SetDeveloperTokenKey(developerTokenKey)
+	return repository
+}
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), developerTokenKey, developerToken)
+}
+
+func TestSearchArtistReturnsArtists(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, artistSearchResponse(t)))
+
+	actual, err := repository.SearchArtist(testContext(), searchName, limit)
+
+	expected := []artist.Artist{
+		artist.NewArtistWithImageUri(
+			"Movements", "https://is1-ssl.mzstatic.com/image/thumb/Music/movements/160x160bb.jpg",
+		),
+		artist.NewArtist("Movements of Sound"),
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSearchArtistReturnsEmptyIfNoneFound(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, []byte(`{"results":{}}`)))
+
+	actual, err := repository.SearchArtist(testContext(), searchName, limit)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []artist.Artist{}, actual)
+}
+
+func TestSearchArtistReturnsErrorWithoutDeveloperToken(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, artistSearchResponse(t)))
+
+	_, err := repository.SearchArtist(context.Background(), searchName, limit)
+
+	assert.NotNil(t, err)
+}
+
+func TestSearchArtistReturnsErrorOnApiError(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, artistSearchResponse(t)))
+
+	_, err := repository.SearchArtist(testContext(), "Another artist", limit)
+
+	assert.NotNil(t, err)
+}
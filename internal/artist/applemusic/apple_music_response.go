@@ -0,0 +1,55 @@
+package applemusic
+
+import (
+	"festwrap/internal/artist"
+	"strings"
+)
+
+// Size in pixels of the artist images, which Apple Music lets us choose
+const imageSize = "160"
+
+type appleMusicArtwork struct {
+	Url string `json:"url"`
+}
+
+type appleMusicArtistAttributes struct {
+	Name    string             `json:"name"`
+	Artwork *appleMusicArtwork `json:"artwork"`
+}
+
+type appleMusicArtist struct {
+	Attributes appleMusicArtistAttributes `json:"attributes"`
+}
+
+// Artwork URLs are templates with the width and height of the image as placeholders
+func (a appleMusicArtist) getImageUri() string {
+	if a.Attributes.Artwork == nil {
+		return ""
+	}
+	replacer := strings.NewReplacer("{w}", imageSize, "{h}", imageSize)
+	return replacer.Replace(a.Attributes.Artwork.Url)
+}
+
+type appleMusicArtists struct {
+	Data []appleMusicArtist `json:"data"`
+}
+
+type appleMusicSearchResults struct {
+	Artists appleMusicArtists `json:"artists"`
+}
+
+type appleMusicResponse struct {
+	Results appleMusicSearchResults `json:"results"`
+}
+
+func (r *appleMusicResponse) GetArtists() []artist.Artist {
+	result := []artist.Artist{}
+	for _, currentArtist := range r.Results.Artists.Data {
+		resultArtist := artist.NewArtist(currentArtist.Attributes.Name)
+		if imageUri := currentArtist.getImageUri(); imageUri != "" {
+			resultArtist.SetImageUri(imageUri)
+		}
+		result = append(result, resultArtist)
+	}
+	return result
+}
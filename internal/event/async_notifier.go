@@ -0,0 +1,124 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"festwrap/internal/logging"
+)
+
+// What to do with an event when the queue of an async notifier is full
+type OverflowPolicy string
+
+const (
+	DropNewest OverflowPolicy = "drop_newest"
+	DropOldest OverflowPolicy = "drop_oldest"
+	Block      OverflowPolicy = "block"
+)
+
+func IsValidOverflowPolicy(policy OverflowPolicy) bool {
+	return policy == DropNewest || policy == DropOldest || policy == Block
+}
+
+// Queues events so notifying them does not wait for the observers, which are updated by a single background worker
+type AsyncNotifier[T Event] struct {
+	notifier      *BaseNotifier[T]
+	policy        OverflowPolicy
+	logger        logging.Logger
+	queue         chan EventWrapper[T]
+	mutex         sync.RWMutex
+	stopped       bool
+	stopOnce      sync.Once
+	done          chan struct{}
+	droppedEvents atomic.Int64
+}
+
+func NewAsyncNotifier[T Event](notifier *BaseNotifier[T], capacity int, policy OverflowPolicy) *AsyncNotifier[T] {
+	return &AsyncNotifier[T]{
+		notifier: notifier,
+		policy:   policy,
+		logger:   logging.NoopLogger{},
+		queue:    make(chan EventWrapper[T], capacity),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *AsyncNotifier[T]) Start() {
+	go func() {
+		defer close(s.done)
+		for event := range s.queue {
+			s.notifier.Notify(event)
+		}
+	}()
+}
+
+// Notifies the events still queued before returning. Events notified afterwards are dropped. Stopping again
+// does nothing
+func (s *AsyncNotifier[T]) Stop() {
+	s.stopOnce.Do(func() {
+		s.mutex.Lock()
+		s.stopped = true
+		close(s.queue)
+		s.mutex.Unlock()
+		<-s.done
+	})
+}
+
+func (s *AsyncNotifier[T]) AddObserver(observer Observer[T]) {
+	s.notifier.AddObserver(observer)
+}
+
+func (s *AsyncNotifier[T]) RemoveObserver(observer Observer[T]) {
+	s.notifier.RemoveObserver(observer)
+}
+
+func (s *AsyncNotifier[T]) Notify(event EventWrapper[T]) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.stopped {
+		s.drop(event, "notifier is stopped")
+		return
+	}
+
+	switch s.policy {
+	case Block:
+		s.queue <- event
+	case DropOldest:
+		for {
+			select {
+			case s.queue <- event:
+				return
+			default:
+			}
+			select {
+			case oldest := <-s.queue:
+				s.drop(oldest, "queue is full")
+			default:
+			}
+		}
+	default:
+		select {
+		case s.queue <- event:
+		default:
+			s.drop(event, "queue is full")
+		}
+	}
+}
+
+func (s *AsyncNotifier[T]) drop(event EventWrapper[T], reason string) {
+	s.droppedEvents.Add(1)
+	s.logger.Warn(fmt.Sprintf("dropped %s event %s: %s", event.EventType, event.EventID, reason))
+}
+
+func (s *AsyncNotifier[T]) Metrics() NotifierMetrics {
+	metrics := s.notifier.Metrics()
+	metrics.DroppedEvents = s.droppedEvents.Load()
+	metrics.QueuedEvents = int64(len(s.queue))
+	return metrics
+}
+
+func (s *AsyncNotifier[T]) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
@@ -0,0 +1,99 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAsyncNotifier(
+	capacity int,
+	policy OverflowPolicy,
+) (*AsyncNotifier[PlaylistCreatedEvent], *FakeObserver[PlaylistCreatedEvent]) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	observer := NewFakeObserver[PlaylistCreatedEvent]()
+	notifier.AddObserver(observer)
+	return NewAsyncNotifier(notifier, capacity, policy), observer
+}
+
+func TestAsyncNotifierUpdatesObserversInBackground(t *testing.T) {
+	notifier, observer := setupAsyncNotifier(10, DropNewest)
+	notifier.Start()
+	events := []EventWrapper[PlaylistCreatedEvent]{testEvent("first"), testEvent("second")}
+
+	for _, event := range events {
+		notifier.Notify(event)
+	}
+	notifier.Stop()
+
+	assert.Equal(t, events, observer.GetEvents())
+	assert.Equal(t, NotifierMetrics{Notifications: 2}, notifier.Metrics())
+}
+
+func TestAsyncNotifierOverflowPolicies(t *testing.T) {
+	first := testEvent("first")
+	second := testEvent("second")
+	tests := map[string]struct {
+		policy   OverflowPolicy
+		expected []EventWrapper[PlaylistCreatedEvent]
+	}{
+		"drop newest": {
+			policy:   DropNewest,
+			expected: []EventWrapper[PlaylistCreatedEvent]{first},
+		},
+		"drop oldest": {
+			policy:   DropOldest,
+			expected: []EventWrapper[PlaylistCreatedEvent]{second},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			notifier, observer := setupAsyncNotifier(1, test.policy)
+
+			notifier.Notify(first)
+			notifier.Notify(second)
+			metrics := notifier.Metrics()
+			notifier.Start()
+			notifier.Stop()
+
+			assert.Equal(t, test.expected, observer.GetEvents())
+			assert.Equal(t, NotifierMetrics{DroppedEvents: 1, QueuedEvents: 1}, metrics)
+		})
+	}
+}
+
+func TestAsyncNotifierBlocksUntilQueued(t *testing.T) {
+	notifier, observer := setupAsyncNotifier(1, Block)
+	notifier.Start()
+
+	for range 5 {
+		notifier.Notify(testEvent("some_id"))
+	}
+	notifier.Stop()
+
+	assert.Len(t, observer.GetEvents(), 5)
+	assert.Equal(t, int64(0), notifier.Metrics().DroppedEvents)
+}
+
+func TestAsyncNotifierDropsEventsAfterStopping(t *testing.T) {
+	notifier, observer := setupAsyncNotifier(10, DropNewest)
+	notifier.Start()
+	notifier.Stop()
+
+	notifier.Notify(testEvent("some_id"))
+
+	assert.Empty(t, observer.GetEvents())
+	assert.Equal(t, int64(1), notifier.Metrics().DroppedEvents)
+}
+
+func TestAsyncNotifierCanBeStoppedTwice(t *testing.T) {
+	notifier, observer := setupAsyncNotifier(10, DropNewest)
+	notifier.Start()
+	notifier.Notify(testEvent("some_id"))
+
+	notifier.Stop()
+	notifier.Stop()
+
+	assert.Len(t, observer.GetEvents(), 1)
+}
@@ -0,0 +1,110 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How events are written into the published messages
+type Encoding string
+
+const (
+	// The id, timestamp, type and payload fields of the event wrapper
+	WrapperEncoding Encoding = "wrapper"
+	// CloudEvents attributes and data in a single JSON document
+	CloudEventsStructuredEncoding Encoding = "cloudevents_structured"
+	// CloudEvents attributes as message attributes, with the data as the message
+	CloudEventsBinaryEncoding Encoding = "cloudevents_binary"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+	jsonContentType        = "application/json"
+)
+
+func IsValidEncoding(encoding Encoding) bool {
+	return encoding == WrapperEncoding ||
+		encoding == CloudEventsStructuredEncoding ||
+		encoding == CloudEventsBinaryEncoding
+}
+
+// Events describing a single resource, such as a playlist or an artist, report it as the CloudEvents subject
+type SubjectEvent interface {
+	Subject() string
+}
+
+type CloudEvent[T Event] struct {
+	SpecVersion     string    `json:"specversion"`
+	Id              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            EventType `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	SchemaVersion   string    `json:"schemaversion"`
+	Data            T         `json:"data"`
+}
+
+func NewCloudEvent[T Event](event EventWrapper[T], source string) CloudEvent[T] {
+	cloudEvent := CloudEvent[T]{
+		SpecVersion:     CloudEventsSpecVersion,
+		Id:              event.EventID,
+		Source:          source,
+		Type:            event.EventType,
+		Time:            time.UnixMilli(event.Timestamp).UTC(),
+		DataContentType: jsonContentType,
+		SchemaVersion:   event.SchemaVersion,
+		Data:            event.Event,
+	}
+	if subjectEvent, ok := any(event.Event).(SubjectEvent); ok {
+		cloudEvent.Subject = subjectEvent.Subject()
+	}
+	return cloudEvent
+}
+
+// Message attributes of the binary mode, following the Pub/Sub protocol binding of CloudEvents
+func (e CloudEvent[T]) BinaryAttributes() map[string]string {
+	attributes := map[string]string{
+		"ce-specversion":   e.SpecVersion,
+		"ce-id":            e.Id,
+		"ce-source":        e.Source,
+		"ce-type":          string(e.Type),
+		"ce-time":          e.Time.Format(time.RFC3339Nano),
+		"ce-schemaversion": e.SchemaVersion,
+		"content-type":     e.DataContentType,
+	}
+	if e.Subject != "" {
+		attributes["ce-subject"] = e.Subject
+	}
+	return attributes
+}
+
+// Message attributes of the structured mode, so consumers can tell the message is a CloudEvent
+func StructuredAttributes() map[string]string {
+	return map[string]string{"content-type": cloudEventsContentType}
+}
+
+// Parses the encodings of the topics, written as comma separated topic=encoding pairs
+func ParseTopicEncodings(value string) (map[string]Encoding, error) {
+	encodings := map[string]Encoding{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		topic, encoding, found := strings.Cut(pair, "=")
+		topic = strings.TrimSpace(topic)
+		encoding = strings.TrimSpace(encoding)
+		if !found || topic == "" {
+			return nil, fmt.Errorf("invalid topic encoding %s, expected topic=encoding", pair)
+		}
+		if !IsValidEncoding(Encoding(encoding)) {
+			return nil, fmt.Errorf("unsupported encoding %s for topic %s", encoding, topic)
+		}
+		encodings[topic] = Encoding(encoding)
+	}
+	return encodings, nil
+}
@@ -9,7 +9,11 @@ import (
 type EventType string
 
 const (
-	PlaylistCreated EventType = "playlist_created"
+	PlaylistCreated        EventType = "playlist_created"
+	PlaylistCreationFailed EventType = "playlist_creation_failed"
+	PlaylistUpdated        EventType = "playlist_updated"
+	SetlistNotFound        EventType = "setlist_not_found"
+	SongNotFound           EventType = "song_not_found"
 )
 
 type Event interface {
@@ -17,17 +21,19 @@ type Event interface {
 }
 
 type EventWrapper[T Event] struct {
-	EventID   string    `json:"id"`
-	Timestamp int64     `json:"timestamp"`
-	EventType EventType `json:"type"`
-	Event     T         `json:"payload"`
+	EventID       string    `json:"id"`
+	Timestamp     int64     `json:"timestamp"`
+	EventType     EventType `json:"type"`
+	SchemaVersion string    `json:"schemaVersion"`
+	Event         T         `json:"payload"`
 }
 
 func NewEventWrapper[T Event](event T) EventWrapper[T] {
 	return EventWrapper[T]{
-		Timestamp: time.Now().UnixMilli(),
-		EventID:   uuid.NewString(),
-		Event:     event,
-		EventType: event.Type(),
+		Timestamp:     time.Now().UnixMilli(),
+		EventID:       uuid.NewString(),
+		Event:         event,
+		EventType:     event.Type(),
+		SchemaVersion: SchemaVersion(event.Type()),
 	}
 }
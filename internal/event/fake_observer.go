@@ -1,7 +1,14 @@
 package event
 
+import (
+	"slices"
+	"sync"
+)
+
 type FakeObserver[T Event] struct {
+	mutex  sync.Mutex
 	events []EventWrapper[T]
+	err    error
 }
 
 func NewFakeObserver[T Event]() *FakeObserver[T] {
@@ -11,10 +18,21 @@ func NewFakeObserver[T Event]() *FakeObserver[T] {
 }
 
 func (f *FakeObserver[T]) Update(event EventWrapper[T]) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
 	f.events = append(f.events, event)
-	return nil
+	return f.err
 }
 
 func (f *FakeObserver[T]) GetEvents() []EventWrapper[T] {
-	return f.events
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return slices.Clone(f.events)
+}
+
+// Makes the observer fail after recording the events
+func (f *FakeObserver[T]) SetError(err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.err = err
 }
@@ -1,36 +1,133 @@
 package event
 
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"festwrap/internal/logging"
+)
+
 type Notifier[T Event] interface {
 	AddObserver(Observer[T])
 	RemoveObserver(Observer[T])
 	Notify(EventWrapper[T])
 }
 
+type NotifierMetrics struct {
+	Notifications    int64
+	ObserverErrors   int64
+	ObserverTimeouts int64
+	DroppedEvents    int64
+	QueuedEvents     int64
+}
+
+// Safe for concurrent use. Observers are updated one after the other in the goroutine calling Notify
 type BaseNotifier[T Event] struct {
-	observers []Observer[T]
+	mutex            sync.RWMutex
+	observers        []Observer[T]
+	observerTimeout  time.Duration
+	logger           logging.Logger
+	notifications    atomic.Int64
+	observerErrors   atomic.Int64
+	observerTimeouts atomic.Int64
 }
 
 func NewBaseNotifier[T Event]() *BaseNotifier[T] {
 	return &BaseNotifier[T]{
 		observers: make([]Observer[T], 0),
+		logger:    logging.NoopLogger{},
 	}
 }
 
 func (s *BaseNotifier[T]) AddObserver(observer Observer[T]) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.observers = append(s.observers, observer)
 }
 
 func (s *BaseNotifier[T]) RemoveObserver(observer Observer[T]) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	for i, obs := range s.observers {
 		if obs == observer {
-			s.observers = append(s.observers[:i], s.observers[i+1:]...)
+			s.observers = append(s.observers[:i:i], s.observers[i+1:]...)
 			break
 		}
 	}
 }
 
+// Logs the errors of the observers, which are still updated when a previous one fails
 func (s *BaseNotifier[T]) Notify(event EventWrapper[T]) {
-	for _, obs := range s.observers {
-		obs.Update(event)
+	if err := s.Dispatch(event); err != nil {
+		s.logger.Error(fmt.Sprintf("could not notify %s event %s: %v", event.EventType, event.EventID, err))
 	}
 }
+
+// Counts and logs the errors of observers updating in the background, which Notify cannot see
+func (s *BaseNotifier[T]) ReportObserverError(event EventWrapper[T], err error) {
+	s.observerErrors.Add(1)
+	s.logger.Error(fmt.Sprintf("could not notify %s event %s: %v", event.EventType, event.EventID, err))
+}
+
+// Updates all observers and returns their errors joined
+func (s *BaseNotifier[T]) Dispatch(event EventWrapper[T]) error {
+	s.notifications.Add(1)
+
+	s.mutex.RLock()
+	observers := s.observers
+	timeout := s.observerTimeout
+	s.mutex.RUnlock()
+
+	var errs []error
+	for _, observer := range observers {
+		if err := s.update(observer, event, timeout); err != nil {
+			s.observerErrors.Add(1)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Observers exceeding the timeout are counted as failed, but keep running in the background until they return
+func (s *BaseNotifier[T]) update(observer Observer[T], event EventWrapper[T], timeout time.Duration) error {
+	if timeout <= 0 {
+		return observer.Update(event)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- observer.Update(event)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		s.observerTimeouts.Add(1)
+		return fmt.Errorf("observer %T timed out after %v", observer, timeout)
+	}
+}
+
+func (s *BaseNotifier[T]) Metrics() NotifierMetrics {
+	return NotifierMetrics{
+		Notifications:    s.notifications.Load(),
+		ObserverErrors:   s.observerErrors.Load(),
+		ObserverTimeouts: s.observerTimeouts.Load(),
+	}
+}
+
+// Sets how long each observer can take to process an event. There is no limit when it is zero
+func (s *BaseNotifier[T]) SetObserverTimeout(timeout time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.observerTimeout = timeout
+}
+
+func (s *BaseNotifier[T]) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Blocks until released, to simulate observers that take too long
+type slowObserver struct {
+	release chan struct{}
+}
+
+func newSlowObserver() *slowObserver {
+	return &slowObserver{release: make(chan struct{})}
+}
+
+func (o *slowObserver) Update(event EventWrapper[PlaylistCreatedEvent]) error {
+	<-o.release
+	return nil
+}
+
+func testEvent(playlistId string) EventWrapper[PlaylistCreatedEvent] {
+	return NewEventWrapper(PlaylistCreatedEvent{Playlist: CreatedPlaylist{Id: playlistId}})
+}
+
+func TestNotifierUpdatesAllObservers(t *testing.T) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	observers := []*FakeObserver[PlaylistCreatedEvent]{
+		NewFakeObserver[PlaylistCreatedEvent](), NewFakeObserver[PlaylistCreatedEvent](),
+	}
+	for _, observer := range observers {
+		notifier.AddObserver(observer)
+	}
+	event := testEvent("some_id")
+
+	notifier.Notify(event)
+
+	for _, observer := range observers {
+		assert.Equal(t, []EventWrapper[PlaylistCreatedEvent]{event}, observer.GetEvents())
+	}
+}
+
+func TestNotifierDoesNotUpdateRemovedObservers(t *testing.T) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	observer := NewFakeObserver[PlaylistCreatedEvent]()
+	notifier.AddObserver(observer)
+	notifier.RemoveObserver(observer)
+
+	notifier.Notify(testEvent("some_id"))
+
+	assert.Empty(t, observer.GetEvents())
+}
+
+func TestNotifierUpdatesObserversAfterFailedOne(t *testing.T) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	failingObserver := NewFakeObserver[PlaylistCreatedEvent]()
+	failingObserver.SetError(errors.New("test error"))
+	observer := NewFakeObserver[PlaylistCreatedEvent]()
+	notifier.AddObserver(failingObserver)
+	notifier.AddObserver(observer)
+
+	err := notifier.Dispatch(testEvent("some_id"))
+
+	assert.ErrorContains(t, err, "test error")
+	assert.Len(t, observer.GetEvents(), 1)
+	assert.Equal(t, NotifierMetrics{Notifications: 1, ObserverErrors: 1}, notifier.Metrics())
+}
+
+func TestNotifierCountsObserverErrorsReportedLater(t *testing.T) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	notifier.AddObserver(NewFakeObserver[PlaylistCreatedEvent]())
+	event := testEvent("some_id")
+	notifier.Notify(event)
+
+	notifier.ReportObserverError(event, errors.New("test error"))
+
+	assert.Equal(t, NotifierMetrics{Notifications: 1, ObserverErrors: 1}, notifier.Metrics())
+}
+
+func TestNotifierTimesOutSlowObservers(t *testing.T) {
+	notifier := NewBaseNotifier[PlaylistCreatedEvent]()
+	notifier.SetObserverTimeout(10 * time.Millisecond)
+	slow := newSlowObserver()
+	defer close(slow.release)
+	observer := NewFakeObserver[PlaylistCreatedEvent]()
+	notifier.AddObserver(slow)
+	notifier.AddObserver(observer)
+
+	err := notifier.Dispatch(testEvent("some_id"))
+
+	assert.ErrorContains(t, err, "timed out")
+	assert.Len(t, observer.GetEvents(), 1)
+	assert.Equal(t, NotifierMetrics{Notifications: 1, ObserverErrors: 1, ObserverTimeouts: 1}, notifier.Metrics())
+}
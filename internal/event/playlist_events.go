@@ -8,12 +8,45 @@ const (
 	PLAYLIST_CREATED_PARTIAL_ERRORS PlaylistCreationStatus = "partial_error"
 )
 
+type ArtistCreationStatus string
+type ArtistErrorCategory string
+
+const (
+	ARTIST_CREATED_OK             ArtistCreationStatus = "ok"
+	ARTIST_CREATED_PARTIAL_ERRORS ArtistCreationStatus = "partial_error"
+	ARTIST_CREATION_FAILED        ArtistCreationStatus = "failed"
+)
+
+const (
+	ARTIST_ERROR_SETLIST_NOT_FOUND ArtistErrorCategory = "setlist_not_found"
+	ARTIST_ERROR_NO_SONGS_FOUND    ArtistErrorCategory = "no_songs_found"
+	ARTIST_ERROR_CANCELLED         ArtistErrorCategory = "cancelled"
+)
+
 const (
-	PLAYLIST_TYPE_SPOTIFY PlaylistType = "spotify"
+	PLAYLIST_TYPE_SPOTIFY       PlaylistType = "spotify"
+	PLAYLIST_TYPE_APPLE_MUSIC   PlaylistType = "apple_music"
+	PLAYLIST_TYPE_YOUTUBE_MUSIC PlaylistType = "youtube_music"
 )
 
+type CreatedPlaylistTrack struct {
+	SetlistTitle string   `json:"setlistTitle"`
+	Id           string   `json:"id"`
+	Uri          string   `json:"uri"`
+	Name         string   `json:"name"`
+	Artists      []string `json:"artists"`
+	Isrc         string   `json:"isrc,omitempty"`
+}
+
 type CreatedPlaylistArtist struct {
-	Name string `json:"name"`
+	Name            string                 `json:"name"`
+	Tracks          []CreatedPlaylistTrack `json:"tracks"`
+	Status          ArtistCreationStatus   `json:"status"`
+	SetlistUrl      string                 `json:"setlistUrl,omitempty"`
+	RequestedSongs  int                    `json:"requestedSongs"`
+	MatchedSongs    int                    `json:"matchedSongs"`
+	UnmatchedTitles []string               `json:"unmatchedTitles"`
+	ErrorCategory   ArtistErrorCategory    `json:"errorCategory,omitempty"`
 }
 
 type CreatedPlaylist struct {
@@ -31,3 +64,100 @@ type PlaylistCreatedEvent struct {
 func (e PlaylistCreatedEvent) Type() EventType {
 	return PlaylistCreated
 }
+
+func (e PlaylistCreatedEvent) Subject() string {
+	return e.Playlist.Id
+}
+
+// Changes undone after the creation of a playlist failed
+type PlaylistCleanup struct {
+	PlaylistDeleted bool     `json:"playlistDeleted"`
+	SongsRemoved    int      `json:"songsRemoved"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+type FailedPlaylist struct {
+	Id   string       `json:"id"`
+	Name string       `json:"name"`
+	Type PlaylistType `json:"type"`
+}
+
+// Artist whose songs could not be added to the playlist
+type FailedPlaylistArtist struct {
+	Name       string `json:"name"`
+	SetlistUrl string `json:"setlistUrl,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// Playlists failing before being created have no id, and nothing to clean up
+type PlaylistCreationFailedEvent struct {
+	Playlist FailedPlaylist         `json:"playlist"`
+	Reason   string                 `json:"reason"`
+	Artists  []FailedPlaylistArtist `json:"artists"`
+	Cleanup  PlaylistCleanup        `json:"cleanup"`
+}
+
+func (e PlaylistCreationFailedEvent) Type() EventType {
+	return PlaylistCreationFailed
+}
+
+func (e PlaylistCreationFailedEvent) Subject() string {
+	return e.Playlist.Id
+}
+
+type RemovedPlaylistTrack struct {
+	Id   string `json:"id"`
+	Uri  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+type UpdatedPlaylist struct {
+	Id   string       `json:"id"`
+	Type PlaylistType `json:"type"`
+}
+
+// Changes applied to a playlist after refreshing the setlists of its artists
+type PlaylistUpdatedEvent struct {
+	Playlist      UpdatedPlaylist        `json:"playlist"`
+	AddedTracks   []CreatedPlaylistTrack `json:"addedTracks"`
+	RemovedTracks []RemovedPlaylistTrack `json:"removedTracks"`
+}
+
+func (e PlaylistUpdatedEvent) Type() EventType {
+	return PlaylistUpdated
+}
+
+func (e PlaylistUpdatedEvent) Subject() string {
+	return e.Playlist.Id
+}
+
+type SetlistNotFoundEvent struct {
+	Artist       string       `json:"artist"`
+	Reason       string       `json:"reason"`
+	PlaylistType PlaylistType `json:"playlistType"`
+}
+
+func (e SetlistNotFoundEvent) Type() EventType {
+	return SetlistNotFound
+}
+
+func (e SetlistNotFoundEvent) Subject() string {
+	return e.Artist
+}
+
+// Song of a setlist that could not be found in the streaming service of the playlist
+type SongNotFoundEvent struct {
+	Artist       string       `json:"artist"`
+	SetlistTitle string       `json:"setlistTitle"`
+	SetlistUrl   string       `json:"setlistUrl"`
+	Reason       string       `json:"reason"`
+	PlaylistType PlaylistType `json:"playlistType"`
+}
+
+func (e SongNotFoundEvent) Type() EventType {
+	return SongNotFound
+}
+
+func (e SongNotFoundEvent) Subject() string {
+	return e.Artist
+}
@@ -12,19 +12,30 @@ const (
 )
 
 type PublishEventObserver[T Event] struct {
-	publisher  messaging.Publisher
-	topic      string
-	timeout    time.Duration
-	serializer serialization.Serializer[EventWrapper[T]]
+	publisher            messaging.Publisher
+	topic                string
+	timeout              time.Duration
+	synchronous          bool
+	encoding             Encoding
+	source               string
+	serializer           serialization.Serializer[EventWrapper[T]]
+	cloudEventSerializer serialization.Serializer[CloudEvent[T]]
+	dataSerializer       serialization.Serializer[T]
+	onError              func(EventWrapper[T], error)
 }
 
 func NewPublishEventObserver[T Event](publisher messaging.Publisher, topic string) PublishEventObserver[T] {
 	serializer := serialization.NewJsonSerializer[EventWrapper[T]]()
+	cloudEventSerializer := serialization.NewJsonSerializer[CloudEvent[T]]()
+	dataSerializer := serialization.NewJsonSerializer[T]()
 	return PublishEventObserver[T]{
-		publisher:  publisher,
-		topic:      topic,
-		serializer: &serializer,
-		timeout:    defaultTimeout,
+		publisher:            publisher,
+		topic:                topic,
+		serializer:           &serializer,
+		cloudEventSerializer: &cloudEventSerializer,
+		dataSerializer:       &dataSerializer,
+		timeout:              defaultTimeout,
+		encoding:             WrapperEncoding,
 	}
 }
 
@@ -33,17 +44,65 @@ func (p *PublishEventObserver[T]) WithTimeout(timeout time.Duration) *PublishEve
 	return p
 }
 
+// Waits for the publish confirmation so errors are returned. Meant for notifiers already running in the background
+func (p *PublishEventObserver[T]) WithSynchronousPublish() *PublishEventObserver[T] {
+	p.synchronous = true
+	return p
+}
+
+// Receives the errors of the events published in the background, since Update cannot return them
+func (p *PublishEventObserver[T]) WithErrorHandler(handler func(EventWrapper[T], error)) *PublishEventObserver[T] {
+	p.onError = handler
+	return p
+}
+
+// Publishes the events as CloudEvents from the given source, instead of using the event wrapper
+func (p *PublishEventObserver[T]) WithEncoding(encoding Encoding, source string) *PublishEventObserver[T] {
+	p.encoding = encoding
+	p.source = source
+	return p
+}
+
 func (o PublishEventObserver[T]) Update(playlistEvent EventWrapper[T]) error {
-	eventBytes, err := o.serializer.Serialize(playlistEvent)
+	eventBytes, attributes, err := o.encode(playlistEvent)
 	if err != nil {
 		return err
 	}
 
+	if o.synchronous {
+		return o.publish(eventBytes, attributes)
+	}
+
 	// Run in the background so we do not wait for publish confirmation
 	go func() {
-		timeoutCtx, ctxCancel := context.WithDeadline(context.Background(), time.Now().Add(o.timeout))
-		defer ctxCancel()
-		o.publisher.Publish(timeoutCtx, o.topic, eventBytes)
+		if err := o.publish(eventBytes, attributes); err != nil && o.onError != nil {
+			o.onError(playlistEvent, err)
+		}
 	}()
 	return nil
 }
+
+func (o PublishEventObserver[T]) encode(playlistEvent EventWrapper[T]) ([]byte, map[string]string, error) {
+	switch o.encoding {
+	case CloudEventsStructuredEncoding:
+		eventBytes, err := o.cloudEventSerializer.Serialize(NewCloudEvent(playlistEvent, o.source))
+		// The content type attribute is optional in structured mode, since the message is self-contained
+		if _, ok := o.publisher.(messaging.AttributesPublisher); !ok {
+			return eventBytes, nil, err
+		}
+		return eventBytes, StructuredAttributes(), err
+	case CloudEventsBinaryEncoding:
+		cloudEvent := NewCloudEvent(playlistEvent, o.source)
+		eventBytes, err := o.dataSerializer.Serialize(cloudEvent.Data)
+		return eventBytes, cloudEvent.BinaryAttributes(), err
+	default:
+		eventBytes, err := o.serializer.Serialize(playlistEvent)
+		return eventBytes, nil, err
+	}
+}
+
+func (o PublishEventObserver[T]) publish(eventBytes []byte, attributes map[string]string) error {
+	timeoutCtx, ctxCancel := context.WithDeadline(context.Background(), time.Now().Add(o.timeout))
+	defer ctxCancel()
+	return messaging.PublishWithAttributes(timeoutCtx, o.publisher, o.topic, eventBytes, attributes)
+}
@@ -0,0 +1,179 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	publishTopic  = "some_topic"
+	publishSource = "/festwrap"
+)
+
+type fakePublisher struct {
+	topic      string
+	message    []byte
+	attributes map[string]string
+	err        error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	p.topic = topic
+	p.message = message
+	return p.err
+}
+
+type fakeAttributesPublisher struct {
+	fakePublisher
+}
+
+func (p *fakeAttributesPublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	p.attributes = attributes
+	return p.Publish(ctx, topic, message)
+}
+
+func publishedEvent() EventWrapper[PlaylistCreatedEvent] {
+	return EventWrapper[PlaylistCreatedEvent]{
+		EventID:       "some_event",
+		Timestamp:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC).UnixMilli(),
+		EventType:     PlaylistCreated,
+		SchemaVersion: "1.0",
+		Event:         PlaylistCreatedEvent{Playlist: CreatedPlaylist{Id: "some_playlist"}},
+	}
+}
+
+func setupPublishObserver(
+	publisher *fakeAttributesPublisher,
+	encoding Encoding,
+) PublishEventObserver[PlaylistCreatedEvent] {
+	observer := NewPublishEventObserver[PlaylistCreatedEvent](publisher, publishTopic)
+	observer.WithSynchronousPublish().WithEncoding(encoding, publishSource)
+	return observer
+}
+
+func TestPublishEventObserverPublishesWrapper(t *testing.T) {
+	publisher := &fakeAttributesPublisher{}
+	observer := setupPublishObserver(publisher, WrapperEncoding)
+
+	err := observer.Update(publishedEvent())
+
+	expected, _ := json.Marshal(publishedEvent())
+	assert.Nil(t, err)
+	assert.Equal(t, publishTopic, publisher.topic)
+	assert.JSONEq(t, string(expected), string(publisher.message))
+	assert.Nil(t, publisher.attributes)
+}
+
+func TestPublishEventObserverReportsBackgroundPublishErrors(t *testing.T) {
+	publisher := &fakeAttributesPublisher{fakePublisher: fakePublisher{err: errors.New("publish error")}}
+	observer := NewPublishEventObserver[PlaylistCreatedEvent](publisher, publishTopic)
+	reported := make(chan error, 1)
+	observer.WithErrorHandler(func(_ EventWrapper[PlaylistCreatedEvent], err error) { reported <- err })
+
+	err := observer.Update(publishedEvent())
+
+	assert.Nil(t, err)
+	assert.ErrorContains(t, <-reported, "publish error")
+}
+
+func TestPublishEventObserverPublishesStructuredCloudEvents(t *testing.T) {
+	publisher := &fakeAttributesPublisher{}
+	observer := setupPublishObserver(publisher, CloudEventsStructuredEncoding)
+
+	err := observer.Update(publishedEvent())
+
+	var actual map[string]any
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(publisher.message, &actual))
+	assert.Equal(t, "1.0", actual["specversion"])
+	assert.Equal(t, "some_event", actual["id"])
+	assert.Equal(t, publishSource, actual["source"])
+	assert.Equal(t, "playlist_created", actual["type"])
+	assert.Equal(t, "some_playlist", actual["subject"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", actual["time"])
+	assert.Equal(t, "application/json", actual["datacontenttype"])
+	assert.Equal(t, SchemaVersion(PlaylistCreated), actual["schemaversion"])
+	assert.Contains(t, actual, "data")
+	assert.Equal(t, map[string]string{"content-type": "application/cloudevents+json"}, publisher.attributes)
+}
+
+func TestPublishEventObserverPublishesBinaryCloudEvents(t *testing.T) {
+	publisher := &fakeAttributesPublisher{}
+	observer := setupPublishObserver(publisher, CloudEventsBinaryEncoding)
+
+	err := observer.Update(publishedEvent())
+
+	expectedData, _ := json.Marshal(publishedEvent().Event)
+	expectedAttributes := map[string]string{
+		"ce-specversion":   "1.0",
+		"ce-id":            "some_event",
+		"ce-source":        publishSource,
+		"ce-type":          "playlist_created",
+		"ce-subject":       "some_playlist",
+		"ce-time":          "2026-03-01T12:00:00Z",
+		"ce-schemaversion": SchemaVersion(PlaylistCreated),
+		"content-type":     "application/json",
+	}
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(expectedData), string(publisher.message))
+	assert.Equal(t, expectedAttributes, publisher.attributes)
+}
+
+func TestPublishEventObserverFailsBinaryCloudEventsWithoutAttributes(t *testing.T) {
+	publisher := &fakePublisher{}
+	observer := NewPublishEventObserver[PlaylistCreatedEvent](publisher, publishTopic)
+	observer.WithSynchronousPublish().WithEncoding(CloudEventsBinaryEncoding, publishSource)
+
+	err := observer.Update(publishedEvent())
+
+	assert.NotNil(t, err)
+	assert.Nil(t, publisher.message)
+}
+
+func TestParseTopicEncodings(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		expected    map[string]Encoding
+		expectedErr bool
+	}{
+		"empty": {
+			value:    "",
+			expected: map[string]Encoding{},
+		},
+		"several topics": {
+			value: "created=cloudevents_binary, failed = cloudevents_structured,updated=wrapper",
+			expected: map[string]Encoding{
+				"created": CloudEventsBinaryEncoding,
+				"failed":  CloudEventsStructuredEncoding,
+				"updated": WrapperEncoding,
+			},
+		},
+		"missing encoding": {
+			value:       "created",
+			expectedErr: true,
+		},
+		"unsupported encoding": {
+			value:       "created=avro",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseTopicEncodings(test.value)
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
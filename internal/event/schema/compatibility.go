@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Lists the changes from the previous schema that would break its consumers:
+// properties removed or no longer required, and types or formats changed
+func BreakingChanges(previous *Schema, current *Schema) []string {
+	return breakingChanges(previous, current, "$")
+}
+
+func breakingChanges(previous *Schema, current *Schema, path string) []string {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	if previous.Type != current.Type {
+		return []string{fmt.Sprintf("%s: type changed from %q to %q", path, previous.Type, current.Type)}
+	}
+	changes := []string{}
+	if previous.Format != current.Format {
+		changes = append(changes, fmt.Sprintf("%s: format changed from %q to %q", path, previous.Format, current.Format))
+	}
+
+	for name, previousProperty := range previous.Properties {
+		propertyPath := path + "." + name
+		currentProperty, ok := current.Properties[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: removed", propertyPath))
+			continue
+		}
+		if slices.Contains(previous.Required, name) && !slices.Contains(current.Required, name) {
+			changes = append(changes, fmt.Sprintf("%s: no longer required", propertyPath))
+		}
+		changes = append(changes, breakingChanges(previousProperty, currentProperty, propertyPath)...)
+	}
+
+	changes = append(changes, breakingChanges(previous.Items, current.Items, path+"[]")...)
+	changes = append(changes, breakingChanges(previous.AdditionalProperties, current.AdditionalProperties, path+"{}")...)
+	slices.Sort(changes)
+	return changes
+}
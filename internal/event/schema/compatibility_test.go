@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func previousSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":        {Type: "string"},
+			"createdAt": {Type: "string", Format: "date-time"},
+			"songs":     {Type: "array", Items: &Schema{Type: "string"}},
+			"reason":    {Type: "string"},
+		},
+		Required: []string{"id", "createdAt", "songs"},
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	tests := map[string]struct {
+		current  func(*Schema)
+		expected []string
+	}{
+		"no changes": {
+			current:  func(*Schema) {},
+			expected: []string{},
+		},
+		"new property": {
+			current: func(s *Schema) {
+				s.Properties["name"] = &Schema{Type: "string"}
+				s.Required = append(s.Required, "name")
+			},
+			expected: []string{},
+		},
+		"optional property becomes required": {
+			current:  func(s *Schema) { s.Required = append(s.Required, "reason") },
+			expected: []string{},
+		},
+		"removed property": {
+			current:  func(s *Schema) { delete(s.Properties, "reason") },
+			expected: []string{"$.reason: removed"},
+		},
+		"required property becomes optional": {
+			current:  func(s *Schema) { s.Required = []string{"createdAt", "songs"} },
+			expected: []string{"$.id: no longer required"},
+		},
+		"changed type": {
+			current:  func(s *Schema) { s.Properties["id"] = &Schema{Type: "integer"} },
+			expected: []string{`$.id: type changed from "string" to "integer"`},
+		},
+		"changed format": {
+			current:  func(s *Schema) { s.Properties["createdAt"] = &Schema{Type: "string"} },
+			expected: []string{`$.createdAt: format changed from "date-time" to ""`},
+		},
+		"changed items": {
+			current:  func(s *Schema) { s.Properties["songs"].Items = &Schema{Type: "object"} },
+			expected: []string{`$.songs[]: type changed from "string" to "object"`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			current := previousSchema()
+			test.current(current)
+
+			actual := BreakingChanges(previousSchema(), current)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"festwrap/internal/event"
+)
+
+// Events published by the app, whose payloads are the contract with the consumers
+var registeredEvents = []event.Event{
+	event.PlaylistCreatedEvent{},
+	event.PlaylistCreationFailedEvent{},
+	event.PlaylistUpdatedEvent{},
+	event.SetlistNotFoundEvent{},
+	event.SongNotFoundEvent{},
+}
+
+func EventSchemas() []*Schema {
+	schemas := make([]*Schema, len(registeredEvents))
+	for i, registeredEvent := range registeredEvents {
+		schemas[i] = EventSchema(registeredEvent)
+	}
+	return schemas
+}
+
+func EventSchema(payload event.Event) *Schema {
+	eventType := payload.Type()
+	version := event.SchemaVersion(eventType)
+	schema := Generate(reflect.TypeOf(payload))
+	schema.Dialect = jsonSchemaDialect
+	schema.Id = fmt.Sprintf("https://festwrap/schemas/events/%s/%s", eventType, version)
+	schema.Title = string(eventType)
+	schema.Version = version
+	return schema
+}
+
+// Writes the schema of each event as <event type>.json in the directory. Nothing is written when a schema has
+// breaking changes without a new major version, so they cannot be written over the compatibility check
+func WriteSchemas(dir string) error {
+	var errs []error
+	for _, schema := range EventSchemas() {
+		committed, _, err := readCommittedSchema(dir, schema)
+		if err == nil && committed != nil {
+			err = checkCompatibility(committed, schema)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("could not create schemas directory: %v", err)
+	}
+	for _, schema := range EventSchemas() {
+		schemaBytes, err := encodeSchema(schema)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(schemaPath(dir, schema), schemaBytes, 0o644); err != nil {
+			return fmt.Errorf("could not write schema of %s: %v", schema.Title, err)
+		}
+	}
+	return nil
+}
+
+// Compares the event structs with the schemas written in the directory. Breaking changes need a new major
+// version, and any change needs the schemas to be written again
+func CheckSchemas(dir string) error {
+	var errs []error
+	for _, schema := range EventSchemas() {
+		if err := checkSchema(dir, schema); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkSchema(dir string, schema *Schema) error {
+	committed, committedBytes, err := readCommittedSchema(dir, schema)
+	if err != nil {
+		return err
+	} else if committed == nil {
+		return fmt.Errorf("schema of %s is missing, write the schemas again", schema.Title)
+	}
+
+	if err = checkCompatibility(committed, schema); err != nil {
+		return err
+	}
+
+	schemaBytes, err := encodeSchema(schema)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(committedBytes, schemaBytes) {
+		return fmt.Errorf("schema of %s is outdated, write the schemas again", schema.Title)
+	}
+	return nil
+}
+
+// Returns no schema when it has not been written yet
+func readCommittedSchema(dir string, schema *Schema) (*Schema, []byte, error) {
+	committedBytes, err := os.ReadFile(schemaPath(dir, schema))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("could not read schema of %s: %v", schema.Title, err)
+	}
+
+	var committed Schema
+	if err = json.Unmarshal(committedBytes, &committed); err != nil {
+		return nil, nil, fmt.Errorf("could not read schema of %s: %v", schema.Title, err)
+	}
+	return &committed, committedBytes, nil
+}
+
+func checkCompatibility(committed *Schema, schema *Schema) error {
+	committedMajor, err := majorVersion(committed.Version)
+	if err != nil {
+		return fmt.Errorf("invalid committed version of %s: %v", schema.Title, err)
+	}
+	currentMajor, err := majorVersion(schema.Version)
+	if err != nil {
+		return fmt.Errorf("invalid version of %s: %v", schema.Title, err)
+	}
+	if currentMajor < committedMajor {
+		return fmt.Errorf("version of %s went back from %s to %s", schema.Title, committed.Version, schema.Version)
+	}
+
+	if changes := BreakingChanges(committed, schema); len(changes) > 0 && currentMajor == committedMajor {
+		return fmt.Errorf(
+			"breaking changes in %s need a new major version than %s: %s",
+			schema.Title, committed.Version, strings.Join(changes, ", "),
+		)
+	}
+	return nil
+}
+
+func encodeSchema(schema *Schema) ([]byte, error) {
+	schemaBytes, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode schema of %s: %v", schema.Title, err)
+	}
+	return append(schemaBytes, '\n'), nil
+}
+
+func schemaPath(dir string, schema *Schema) string {
+	return filepath.Join(dir, schema.Title+".json")
+}
+
+func majorVersion(version string) (int, error) {
+	major, _, _ := strings.Cut(version, ".")
+	return strconv.Atoi(major)
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"festwrap/internal/event"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const committedSchemasDir = "../../../schemas/events"
+
+func writeCommittedSchema(t *testing.T, dir string, schema *Schema) {
+	t.Helper()
+
+	schemaBytes, err := encodeSchema(schema)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, schema.Title+".json"), schemaBytes, 0o644))
+}
+
+func TestRegisteredEventsHaveSchemaVersion(t *testing.T) {
+	for _, registeredEvent := range registeredEvents {
+		assert.NotEmpty(t, event.SchemaVersion(registeredEvent.Type()), registeredEvent.Type())
+	}
+}
+
+func TestEventSchemaSetsMetadata(t *testing.T) {
+	actual := EventSchema(event.SongNotFoundEvent{})
+
+	assert.Equal(t, jsonSchemaDialect, actual.Dialect)
+	assert.Equal(t, "https://festwrap/schemas/events/song_not_found/1.0", actual.Id)
+	assert.Equal(t, "song_not_found", actual.Title)
+	assert.Equal(t, "1.0", actual.Version)
+}
+
+func TestCommittedSchemasAreUpToDate(t *testing.T) {
+	assert.Nil(t, CheckSchemas(committedSchemasDir))
+}
+
+func TestWrittenSchemasPassCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Nil(t, WriteSchemas(dir))
+	assert.Nil(t, CheckSchemas(dir))
+}
+
+func TestWriteSchemasRefusesBreakingChangeWithoutNewVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	committed := EventSchema(event.SongNotFoundEvent{})
+	committed.Properties["removedField"] = &Schema{Type: "string"}
+	writeCommittedSchema(t, dir, committed)
+
+	err := WriteSchemas(dir)
+
+	assert.ErrorContains(t, err, "breaking changes in song_not_found need a new major version than 1.0")
+	assert.ErrorContains(t, CheckSchemas(dir), "breaking changes in song_not_found")
+}
+
+func TestWriteSchemasAcceptsBreakingChangeWithNewMajorVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	committed := EventSchema(event.SongNotFoundEvent{})
+	committed.Version = "0.9"
+	committed.Properties["removedField"] = &Schema{Type: "string"}
+	writeCommittedSchema(t, dir, committed)
+
+	assert.Nil(t, WriteSchemas(dir))
+	assert.Nil(t, CheckSchemas(dir))
+}
+
+func TestCheckSchemasFailsOnMissingSchema(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "playlist_updated.json")))
+
+	err := CheckSchemas(dir)
+
+	assert.ErrorContains(t, err, "schema of playlist_updated is missing")
+}
+
+func TestCheckSchemasFailsOnBreakingChangeWithoutNewVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	committed := EventSchema(event.SongNotFoundEvent{})
+	committed.Properties["removedField"] = &Schema{Type: "string"}
+	writeCommittedSchema(t, dir, committed)
+
+	err := CheckSchemas(dir)
+
+	assert.ErrorContains(t, err, "breaking changes in song_not_found need a new major version than 1.0")
+	assert.ErrorContains(t, err, "$.removedField: removed")
+}
+
+func TestCheckSchemasAcceptsBreakingChangeWithNewMajorVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	committed := EventSchema(event.SongNotFoundEvent{})
+	committed.Version = "0.9"
+	committed.Properties["removedField"] = &Schema{Type: "string"}
+	writeCommittedSchema(t, dir, committed)
+
+	err := CheckSchemas(dir)
+
+	// The schema still has to be written again with the new version
+	assert.ErrorContains(t, err, "schema of song_not_found is outdated")
+	assert.NotContains(t, err.Error(), "breaking changes")
+}
+
+func TestCheckSchemasFailsOnOutdatedSchema(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	committed := EventSchema(event.SongNotFoundEvent{})
+	delete(committed.Properties, "reason")
+	writeCommittedSchema(t, dir, committed)
+
+	err := CheckSchemas(dir)
+
+	assert.ErrorContains(t, err, "schema of song_not_found is outdated")
+}
+
+func TestCheckSchemasFailsOnInvalidSchema(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteSchemas(dir))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "song_not_found.json"), []byte("{"), 0o644))
+
+	err := CheckSchemas(dir)
+
+	assert.ErrorContains(t, err, "could not read schema of song_not_found")
+}
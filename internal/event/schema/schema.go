@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSON Schema document, limited to the keywords needed to describe the event payloads
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Id                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Version              string             `json:"version,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Describes the JSON encoding of the type. Fields without omitempty are required, since they are always present
+func Generate(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Generate(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: Generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: Generate(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructFields(schema, t)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+func addStructFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// Embedded structs without a name have their fields promoted even if unexported, as encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = Generate(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
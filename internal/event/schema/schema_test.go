@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEmbedded struct {
+	Source string `json:"source"`
+}
+
+type testPayload struct {
+	testEmbedded
+	Name       string            `json:"name"`
+	Count      int               `json:"count"`
+	Score      float64           `json:"score,omitempty"`
+	Enabled    bool              `json:"enabled"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Tags       []string          `json:"tags,omitempty"`
+	Labels     map[string]string `json:"labels,omitzero"`
+	Parent     *testEmbedded     `json:"parent,omitempty"`
+	Ignored    string            `json:"-"`
+	unexported string
+	Untagged   string
+}
+
+func TestGenerateDescribesStructFields(t *testing.T) {
+	actual := Generate(reflect.TypeFor[testPayload]())
+
+	expected := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"source":    {Type: "string"},
+			"name":      {Type: "string"},
+			"count":     {Type: "integer"},
+			"score":     {Type: "number"},
+			"enabled":   {Type: "boolean"},
+			"createdAt": {Type: "string", Format: "date-time"},
+			"tags":      {Type: "array", Items: &Schema{Type: "string"}},
+			"labels":    {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"parent": {
+				Type:       "object",
+				Properties: map[string]*Schema{"source": {Type: "string"}},
+				Required:   []string{"source"},
+			},
+			"Untagged": {Type: "string"},
+		},
+		Required: []string{"source", "name", "count", "enabled", "createdAt", "Untagged"},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestGenerateDescribesByteSlicesAsStrings(t *testing.T) {
+	actual := Generate(reflect.TypeFor[[]byte]())
+
+	assert.Equal(t, &Schema{Type: "string"}, actual)
+}
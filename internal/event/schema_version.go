@@ -0,0 +1,14 @@
+package event
+
+// Version of the payload of each event type, to be increased when the payload changes
+var schemaVersions = map[EventType]string{
+	PlaylistCreated:        "1.0",
+	PlaylistCreationFailed: "1.0",
+	PlaylistUpdated:        "1.0",
+	SetlistNotFound:        "1.0",
+	SongNotFound:           "1.0",
+}
+
+func SchemaVersion(eventType EventType) string {
+	return schemaVersions[eventType]
+}
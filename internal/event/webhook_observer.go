@@ -0,0 +1,436 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	httpclient "festwrap/internal/http/client"
+	"festwrap/internal/logging"
+	"festwrap/internal/serialization"
+)
+
+const (
+	WebhookSignatureHeader = "X-Festwrap-Signature"
+	WebhookTimestampHeader = "X-Festwrap-Timestamp"
+	WebhookEventIdHeader   = "X-Festwrap-Event-Id"
+	WebhookEventTypeHeader = "X-Festwrap-Event-Type"
+	maxWebhookDeliveries   = 100
+)
+
+// Endpoint receiving the events of the given types, or all of them when no types are given
+type WebhookEndpoint struct {
+	Url        string      `json:"url"`
+	Secret     string      `json:"secret"`
+	EventTypes []EventType `json:"eventTypes,omitempty"`
+}
+
+func (e WebhookEndpoint) accepts(eventType EventType) bool {
+	return len(e.EventTypes) == 0 || slices.Contains(e.EventTypes, eventType)
+}
+
+// Reads the endpoints from a JSON file holding a list of them
+func ReadWebhookEndpoints(path string) ([]WebhookEndpoint, error) {
+	endpointsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read webhooks file %s: %v", path, err)
+	}
+
+	var endpoints []WebhookEndpoint
+	if err = json.Unmarshal(endpointsBytes, &endpoints); err != nil {
+		return nil, fmt.Errorf("could not parse webhooks file %s: %v", path, err)
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Url == "" || endpoint.Secret == "" {
+			return nil, fmt.Errorf("webhooks need an url and a secret, found %q", endpoint.Url)
+		}
+		for _, eventType := range endpoint.EventTypes {
+			if _, ok := schemaVersions[eventType]; !ok {
+				return nil, fmt.Errorf("unknown event type %s for webhook %s", eventType, endpoint.Url)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// Hex encoded HMAC-SHA256 of the timestamp and the body joined by a dot. Signing the timestamp lets receivers
+// reject old requests being replayed
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Attempt to deliver an event to an endpoint. Failed attempts have no status code if the request was not answered
+type WebhookDelivery struct {
+	Url        string
+	EventId    string
+	EventType  EventType
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	Time       time.Time
+}
+
+func (d WebhookDelivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+// Event waiting in the queue of the dispatcher to be delivered
+type webhookEvent struct {
+	eventId   string
+	eventType EventType
+	body      []byte
+}
+
+type WebhookMetrics struct {
+	Deliveries        []WebhookDelivery
+	DisabledEndpoints []string
+	QueuedEvents      int64
+	DroppedEvents     int64
+}
+
+type webhookEndpointState struct {
+	endpoint            WebhookEndpoint
+	consecutiveFailures int
+	disabled            bool
+}
+
+// Delivers events to the endpoints, retrying failed requests with exponential backoff. Endpoints failing
+// several deliveries in a row are disabled until the app restarts. Queued events are delivered by a fixed number
+// of background workers. Safe for concurrent use
+type WebhookDispatcher struct {
+	client         httpclient.HTTPClient
+	logger         logging.Logger
+	mutex          sync.Mutex
+	endpoints      []*webhookEndpointState
+	deliveries     []WebhookDelivery
+	queue          chan webhookEvent
+	queueMutex     sync.RWMutex
+	stopped        bool
+	stopOnce       sync.Once
+	numWorkers     int
+	workers        sync.WaitGroup
+	droppedEvents  atomic.Int64
+	maxAttempts    int
+	disableAfter   int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	timeout        time.Duration
+	now            func() time.Time
+}
+
+func NewWebhookDispatcher(
+	client httpclient.HTTPClient,
+	endpoints []WebhookEndpoint,
+	logger logging.Logger,
+) *WebhookDispatcher {
+	endpointStates := make([]*webhookEndpointState, len(endpoints))
+	for i, endpoint := range endpoints {
+		endpointStates[i] = &webhookEndpointState{endpoint: endpoint}
+	}
+	return &WebhookDispatcher{
+		client:         client,
+		logger:         logger,
+		endpoints:      endpointStates,
+		deliveries:     []WebhookDelivery{},
+		queue:          make(chan webhookEvent, 100),
+		numWorkers:     4,
+		maxAttempts:    3,
+		disableAfter:   5,
+		initialBackoff: time.Second,
+		maxBackoff:     30 * time.Second,
+		timeout:        defaultTimeout,
+		now:            time.Now,
+	}
+}
+
+// Starts the workers delivering the queued events
+func (d *WebhookDispatcher) Start() {
+	for range d.numWorkers {
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			for event := range d.queue {
+				if err := d.Deliver(event.eventId, event.eventType, event.body); err != nil {
+					d.logger.Error(err.Error())
+				}
+			}
+		}()
+	}
+}
+
+// Delivers the events still queued before returning. Events queued afterwards are dropped
+func (d *WebhookDispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		d.queueMutex.Lock()
+		d.stopped = true
+		close(d.queue)
+		d.queueMutex.Unlock()
+		d.workers.Wait()
+	})
+}
+
+// Queues the event to be delivered in the background, or returns an error if it is dropped because the queue is
+// full or the dispatcher is stopped
+func (d *WebhookDispatcher) Enqueue(eventId string, eventType EventType, body []byte) error {
+	d.queueMutex.RLock()
+	defer d.queueMutex.RUnlock()
+
+	if d.stopped {
+		d.droppedEvents.Add(1)
+		return fmt.Errorf("dropped webhooks of %s event %s: dispatcher is stopped", eventType, eventId)
+	}
+	select {
+	case d.queue <- webhookEvent{eventId: eventId, eventType: eventType, body: body}:
+		return nil
+	default:
+		d.droppedEvents.Add(1)
+		return fmt.Errorf("dropped webhooks of %s event %s: queue is full", eventType, eventId)
+	}
+}
+
+// Sends the event to the enabled endpoints accepting its type at the same time, and returns their errors joined
+func (d *WebhookDispatcher) Deliver(eventId string, eventType EventType, body []byte) error {
+	d.mutex.Lock()
+	var endpoints []*webhookEndpointState
+	for _, state := range d.endpoints {
+		if !state.disabled && state.endpoint.accepts(eventType) {
+			endpoints = append(endpoints, state)
+		}
+	}
+	d.mutex.Unlock()
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, state := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.deliverTo(state, eventId, eventType, body)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (d *WebhookDispatcher) deliverTo(
+	state *webhookEndpointState,
+	eventId string,
+	eventType EventType,
+	body []byte,
+) error {
+	var err error
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		var retryable bool
+		retryable, err = d.send(state.endpoint, eventId, eventType, body, attempt)
+		if err == nil {
+			d.mutex.Lock()
+			state.consecutiveFailures = 0
+			d.mutex.Unlock()
+			return nil
+		}
+		if !retryable || attempt == d.maxAttempts {
+			break
+		}
+
+		backoff := d.backoff(attempt)
+		d.logger.Warn(fmt.Sprintf(
+			"could not deliver %s event %s to webhook %s, retrying in %v: %v",
+			eventType, eventId, state.endpoint.Url, backoff, err,
+		))
+		time.Sleep(backoff)
+	}
+
+	d.mutex.Lock()
+	state.consecutiveFailures += 1
+	if state.consecutiveFailures >= d.disableAfter && !state.disabled {
+		state.disabled = true
+		d.logger.Error(fmt.Sprintf(
+			"disabling webhook %s after %d failed deliveries in a row", state.endpoint.Url, state.consecutiveFailures,
+		))
+	}
+	d.mutex.Unlock()
+	return fmt.Errorf("could not deliver %s event %s to webhook %s: %v", eventType, eventId, state.endpoint.Url, err)
+}
+
+// Requests without answer, server errors and rate limits are worth retrying, unlike the rest of client errors
+func (d *WebhookDispatcher) send(
+	endpoint WebhookEndpoint,
+	eventId string,
+	eventType EventType,
+	body []byte,
+	attempt int,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	start := d.now()
+	delivery := WebhookDelivery{Url: endpoint.Url, EventId: eventId, EventType: eventType, Attempt: attempt, Time: start}
+	retryable, err := d.post(ctx, endpoint, body, &delivery)
+	delivery.Duration = d.now().Sub(start)
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	d.record(delivery)
+	return retryable, err
+}
+
+// Sets the status code of the delivery when the request is answered
+func (d *WebhookDispatcher) post(
+	ctx context.Context,
+	endpoint WebhookEndpoint,
+	body []byte,
+	delivery *WebhookDelivery,
+) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("could not create request: %v", err)
+	}
+	timestamp := delivery.Time.Unix()
+	request.Header.Set("Content-Type", jsonContentType)
+	request.Header.Set(WebhookEventIdHeader, delivery.EventId)
+	request.Header.Set(WebhookEventTypeHeader, string(delivery.EventType))
+	request.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	response, err := d.client.Send(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	delivery.StatusCode = response.StatusCode
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return true, nil
+	}
+	retryable := response.StatusCode >= 500 ||
+		response.StatusCode == http.StatusRequestTimeout ||
+		response.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("unexpected status code %d", response.StatusCode)
+}
+
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	backoff := d.initialBackoff
+	for range attempt - 1 {
+		backoff *= 2
+		if backoff >= d.maxBackoff {
+			return d.maxBackoff
+		}
+	}
+	return backoff
+}
+
+func (d *WebhookDispatcher) record(delivery WebhookDelivery) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > maxWebhookDeliveries {
+		d.deliveries = slices.Clone(d.deliveries[len(d.deliveries)-maxWebhookDeliveries:])
+	}
+}
+
+// Most recent delivery attempts, oldest first
+func (d *WebhookDispatcher) Deliveries() []WebhookDelivery {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.deliveries)
+}
+
+func (d *WebhookDispatcher) DisabledEndpoints() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	disabled := []string{}
+	for _, state := range d.endpoints {
+		if state.disabled {
+			disabled = append(disabled, state.endpoint.Url)
+		}
+	}
+	return disabled
+}
+
+func (d *WebhookDispatcher) Metrics() WebhookMetrics {
+	return WebhookMetrics{
+		Deliveries:        d.Deliveries(),
+		DisabledEndpoints: d.DisabledEndpoints(),
+		QueuedEvents:      int64(len(d.queue)),
+		DroppedEvents:     d.droppedEvents.Load(),
+	}
+}
+
+// Events are always sent at least once
+func (d *WebhookDispatcher) SetMaxAttempts(attempts int) {
+	d.maxAttempts = max(attempts, 1)
+}
+
+// Sets how many events can wait to be delivered and how many are delivered at the same time. Must be called
+// before starting the dispatcher
+func (d *WebhookDispatcher) SetQueue(capacity int, workers int) {
+	d.queue = make(chan webhookEvent, max(capacity, 0))
+	d.numWorkers = max(workers, 1)
+}
+
+// Number of deliveries failing in a row, after all their attempts, before the endpoint is disabled
+func (d *WebhookDispatcher) SetDisableAfter(failures int) {
+	d.disableAfter = failures
+}
+
+// Sets the wait before the first retry, which doubles on each attempt up to the maximum
+func (d *WebhookDispatcher) SetBackoff(initialBackoff time.Duration, maxBackoff time.Duration) {
+	d.initialBackoff = initialBackoff
+	d.maxBackoff = maxBackoff
+}
+
+func (d *WebhookDispatcher) SetTimeout(timeout time.Duration) {
+	d.timeout = timeout
+}
+
+// Posts the event wrappers to the webhooks of the dispatcher, which can be shared by the observers of each event type
+type WebhookObserver[T Event] struct {
+	dispatcher  *WebhookDispatcher
+	synchronous bool
+	serializer  serialization.Serializer[EventWrapper[T]]
+}
+
+func NewWebhookObserver[T Event](dispatcher *WebhookDispatcher) WebhookObserver[T] {
+	serializer := serialization.NewJsonSerializer[EventWrapper[T]]()
+	return WebhookObserver[T]{dispatcher: dispatcher, serializer: &serializer}
+}
+
+// Waits for the deliveries, including their retries, so errors are returned
+func (o *WebhookObserver[T]) WithSynchronousDelivery() *WebhookObserver[T] {
+	o.synchronous = true
+	return o
+}
+
+func (o WebhookObserver[T]) Update(event EventWrapper[T]) error {
+	eventBytes, err := o.serializer.Serialize(event)
+	if err != nil {
+		return err
+	}
+
+	if o.synchronous {
+		return o.dispatcher.Deliver(event.EventID, event.EventType, eventBytes)
+	}
+
+	// Retries can take long, so deliver in the background. Failures are logged and kept in the deliveries
+	return o.dispatcher.Enqueue(event.EventID, event.EventType, eventBytes)
+}
@@ -0,0 +1,306 @@
+package event
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	httpclient "festwrap/internal/http/client"
+	"festwrap/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const webhookSecret = "some_secret"
+
+type receivedWebhook struct {
+	headers http.Header
+	body    []byte
+}
+
+// Answers with the given status codes in order, repeating the last one
+type webhookServer struct {
+	mutex       sync.Mutex
+	statusCodes []int
+	received    []receivedWebhook
+	server      *httptest.Server
+}
+
+func newWebhookServer(t *testing.T, statusCodes ...int) *webhookServer {
+	t.Helper()
+
+	webhookServer := &webhookServer{statusCodes: statusCodes}
+	webhookServer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		webhookServer.mutex.Lock()
+		defer webhookServer.mutex.Unlock()
+		statusCode := webhookServer.statusCodes[min(len(webhookServer.received), len(webhookServer.statusCodes)-1)]
+		webhookServer.received = append(webhookServer.received, receivedWebhook{headers: r.Header, body: body})
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(webhookServer.server.Close)
+	return webhookServer
+}
+
+func (s *webhookServer) Received() []receivedWebhook {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.received
+}
+
+func (s *webhookServer) Endpoint(eventTypes ...EventType) WebhookEndpoint {
+	return WebhookEndpoint{Url: s.server.URL, Secret: webhookSecret, EventTypes: eventTypes}
+}
+
+func setupWebhookObserver(endpoints ...WebhookEndpoint) (WebhookObserver[PlaylistCreatedEvent], *WebhookDispatcher) {
+	client := httpclient.NewBaseHTTPClient(&http.Client{})
+	dispatcher := NewWebhookDispatcher(&client, endpoints, logging.NoopLogger{})
+	dispatcher.SetBackoff(time.Millisecond, time.Millisecond)
+	observer := NewWebhookObserver[PlaylistCreatedEvent](dispatcher)
+	observer.WithSynchronousDelivery()
+	return observer, dispatcher
+}
+
+func TestWebhookObserverPostsSignedEvent(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	observer, _ := setupWebhookObserver(server.Endpoint())
+
+	err := observer.Update(publishedEvent())
+
+	assert.Nil(t, err)
+	received := server.Received()
+	assert.Len(t, received, 1)
+	expectedBody := `{
+		"id": "some_event",
+		"timestamp": 1772366400000,
+		"type": "playlist_created",
+		"schemaVersion": "1.0",
+		"payload": {"playlist": {"id": "some_playlist", "name": "", "artists": null, "type": ""}, "status": ""}
+	}`
+	assert.JSONEq(t, expectedBody, string(received[0].body))
+	headers := received[0].headers
+	timestamp, err := strconv.ParseInt(headers.Get(WebhookTimestampHeader), 10, 64)
+	assert.Nil(t, err)
+	assert.Equal(t, SignWebhookPayload(webhookSecret, timestamp, received[0].body), headers.Get(WebhookSignatureHeader))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Equal(t, "some_event", headers.Get(WebhookEventIdHeader))
+	assert.Equal(t, "playlist_created", headers.Get(WebhookEventTypeHeader))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	actual := SignWebhookPayload("secret", 1700000000, []byte(`{"id":"some_event"}`))
+
+	expected := "sha256=7a825d59348e2675ce95748294aae83e701642fb90074daeb2e3f733c698037f"
+	assert.Equal(t, expected, actual)
+	assert.NotEqual(t, actual, SignWebhookPayload("other_secret", 1700000000, []byte(`{"id":"some_event"}`)))
+	assert.NotEqual(t, actual, SignWebhookPayload("secret", 1700000001, []byte(`{"id":"some_event"}`)))
+}
+
+func TestWebhookObserverFiltersByEventType(t *testing.T) {
+	acceptingServer := newWebhookServer(t, http.StatusOK)
+	filteringServer := newWebhookServer(t, http.StatusOK)
+	observer, _ := setupWebhookObserver(
+		acceptingServer.Endpoint(PlaylistCreated, PlaylistUpdated), filteringServer.Endpoint(PlaylistUpdated),
+	)
+
+	err := observer.Update(publishedEvent())
+
+	assert.Nil(t, err)
+	assert.Len(t, acceptingServer.Received(), 1)
+	assert.Empty(t, filteringServer.Received())
+}
+
+func TestWebhookObserverRetriesFailedDeliveries(t *testing.T) {
+	server := newWebhookServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent)
+	observer, dispatcher := setupWebhookObserver(server.Endpoint())
+
+	err := observer.Update(publishedEvent())
+
+	assert.Nil(t, err)
+	assert.Len(t, server.Received(), 3)
+	deliveries := dispatcher.Deliveries()
+	assert.Len(t, deliveries, 3)
+	expectedStatusCodes := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent}
+	for i, expectedStatusCode := range expectedStatusCodes {
+		assert.Equal(t, server.server.URL, deliveries[i].Url)
+		assert.Equal(t, "some_event", deliveries[i].EventId)
+		assert.Equal(t, PlaylistCreated, deliveries[i].EventType)
+		assert.Equal(t, i+1, deliveries[i].Attempt)
+		assert.Equal(t, expectedStatusCode, deliveries[i].StatusCode)
+	}
+	assert.False(t, deliveries[0].Succeeded())
+	assert.True(t, deliveries[2].Succeeded())
+}
+
+func TestWebhookObserverReturnsErrorAfterMaxAttempts(t *testing.T) {
+	server := newWebhookServer(t, http.StatusInternalServerError)
+	observer, dispatcher := setupWebhookObserver(server.Endpoint())
+	dispatcher.SetMaxAttempts(2)
+
+	err := observer.Update(publishedEvent())
+
+	assert.ErrorContains(t, err, "unexpected status code 500")
+	assert.Len(t, server.Received(), 2)
+}
+
+func TestWebhookObserverDoesNotRetryClientErrors(t *testing.T) {
+	server := newWebhookServer(t, http.StatusBadRequest)
+	observer, _ := setupWebhookObserver(server.Endpoint())
+
+	err := observer.Update(publishedEvent())
+
+	assert.ErrorContains(t, err, "unexpected status code 400")
+	assert.Len(t, server.Received(), 1)
+}
+
+func TestWebhookObserverRecordsUnansweredDeliveries(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	server.server.Close()
+	observer, dispatcher := setupWebhookObserver(server.Endpoint())
+	dispatcher.SetMaxAttempts(1)
+
+	err := observer.Update(publishedEvent())
+
+	assert.NotNil(t, err)
+	deliveries := dispatcher.Deliveries()
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, 0, deliveries[0].StatusCode)
+	assert.False(t, deliveries[0].Succeeded())
+}
+
+func TestWebhookDispatcherDisablesFailingEndpoints(t *testing.T) {
+	failingServer := newWebhookServer(t, http.StatusBadRequest)
+	healthyServer := newWebhookServer(t, http.StatusOK)
+	observer, dispatcher := setupWebhookObserver(failingServer.Endpoint(), healthyServer.Endpoint())
+	dispatcher.SetDisableAfter(2)
+
+	for range 3 {
+		_ = observer.Update(publishedEvent())
+	}
+
+	assert.Len(t, failingServer.Received(), 2)
+	assert.Len(t, healthyServer.Received(), 3)
+	assert.Equal(t, []string{failingServer.server.URL}, dispatcher.DisabledEndpoints())
+}
+
+func TestWebhookDispatcherSendsEventsAtLeastOnce(t *testing.T) {
+	server := newWebhookServer(t, http.StatusInternalServerError)
+	observer, dispatcher := setupWebhookObserver(server.Endpoint())
+	dispatcher.SetMaxAttempts(0)
+
+	_ = observer.Update(publishedEvent())
+
+	assert.Len(t, server.Received(), 1)
+}
+
+func TestWebhookObserverDeliversQueuedEventsBeforeStopping(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	client := httpclient.NewBaseHTTPClient(&http.Client{})
+	dispatcher := NewWebhookDispatcher(&client, []WebhookEndpoint{server.Endpoint()}, logging.NoopLogger{})
+	dispatcher.SetQueue(5, 2)
+	observer := NewWebhookObserver[PlaylistCreatedEvent](dispatcher)
+	dispatcher.Start()
+
+	for range 5 {
+		assert.Nil(t, observer.Update(publishedEvent()))
+	}
+	dispatcher.Stop()
+	dispatcher.Stop()
+
+	assert.Len(t, server.Received(), 5)
+	assert.Equal(t, int64(0), dispatcher.Metrics().QueuedEvents)
+}
+
+func TestWebhookObserverReturnsErrorWhenQueueIsFull(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	client := httpclient.NewBaseHTTPClient(&http.Client{})
+	dispatcher := NewWebhookDispatcher(&client, []WebhookEndpoint{server.Endpoint()}, logging.NoopLogger{})
+	dispatcher.SetQueue(1, 1)
+	observer := NewWebhookObserver[PlaylistCreatedEvent](dispatcher)
+
+	assert.Nil(t, observer.Update(publishedEvent()))
+	err := observer.Update(publishedEvent())
+
+	assert.ErrorContains(t, err, "queue is full")
+	expected := WebhookMetrics{
+		Deliveries:        []WebhookDelivery{},
+		DisabledEndpoints: []string{},
+		QueuedEvents:      1,
+		DroppedEvents:     1,
+	}
+	assert.Equal(t, expected, dispatcher.Metrics())
+}
+
+func TestWebhookObserverReturnsErrorWhenDispatcherIsStopped(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	client := httpclient.NewBaseHTTPClient(&http.Client{})
+	dispatcher := NewWebhookDispatcher(&client, []WebhookEndpoint{server.Endpoint()}, logging.NoopLogger{})
+	observer := NewWebhookObserver[PlaylistCreatedEvent](dispatcher)
+	dispatcher.Start()
+	dispatcher.Stop()
+
+	err := observer.Update(publishedEvent())
+
+	assert.ErrorContains(t, err, "dispatcher is stopped")
+	assert.Empty(t, server.Received())
+}
+
+func TestWebhookDispatcherKeepsRecentDeliveries(t *testing.T) {
+	server := newWebhookServer(t, http.StatusOK)
+	observer, dispatcher := setupWebhookObserver(server.Endpoint())
+
+	for range maxWebhookDeliveries + 5 {
+		_ = observer.Update(publishedEvent())
+	}
+
+	assert.Len(t, dispatcher.Deliveries(), maxWebhookDeliveries)
+}
+
+func TestReadWebhookEndpoints(t *testing.T) {
+	tests := map[string]struct {
+		content     string
+		expected    []WebhookEndpoint
+		expectedErr bool
+	}{
+		"valid endpoints": {
+			content: `[
+				{"url": "https://partner.com/hook", "secret": "secret", "eventTypes": ["playlist_created"]},
+				{"url": "https://other.com/hook", "secret": "other"}
+			]`,
+			expected: []WebhookEndpoint{
+				{Url: "https://partner.com/hook", Secret: "secret", EventTypes: []EventType{PlaylistCreated}},
+				{Url: "https://other.com/hook", Secret: "other"},
+			},
+		},
+		"invalid json": {
+			content:     `{`,
+			expectedErr: true,
+		},
+		"missing secret": {
+			content:     `[{"url": "https://partner.com/hook"}]`,
+			expectedErr: true,
+		},
+		"unknown event type": {
+			content:     `[{"url": "https://partner.com/hook", "secret": "secret", "eventTypes": ["playlist_deleted"]}]`,
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "webhooks.json")
+			assert.Nil(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			actual, err := ReadWebhookEndpoints(path)
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
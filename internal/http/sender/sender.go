@@ -7,8 +7,9 @@ type HTTPRequestSender interface {
 type Method string
 
 const (
-	GET  Method = "GET"
-	POST Method = "POST"
+	GET    Method = "GET"
+	POST   Method = "POST"
+	DELETE Method = "DELETE"
 )
 
 type HTTPRequestOptions struct {
@@ -0,0 +1,211 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Line written for each message. Messages which are not JSON documents are written as strings
+type messageLine struct {
+	Topic       string            `json:"topic"`
+	PublishedAt time.Time         `json:"publishedAt"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Message     json.RawMessage   `json:"message"`
+}
+
+func encodeMessageLine(topic string, message []byte, attributes map[string]string, now time.Time) ([]byte, error) {
+	line := messageLine{Topic: topic, PublishedAt: now.UTC(), Attributes: attributes, Message: message}
+	if !json.Valid(message) {
+		messageString, err := json.Marshal(string(message))
+		if err != nil {
+			return nil, err
+		}
+		line.Message = messageString
+	}
+
+	lineBytes, err := json.Marshal(line)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode message for topic %s: %v", topic, err)
+	}
+	return append(lineBytes, '\n'), nil
+}
+
+// Writes the messages as newline delimited JSON, such as to the standard output. Safe for concurrent use
+type WriterPublisher struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	now    func() time.Time
+}
+
+func NewWriterPublisher(writer io.Writer) *WriterPublisher {
+	return &WriterPublisher{writer: writer, now: time.Now}
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+func (p *WriterPublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	line, err := encodeMessageLine(topic, message, attributes, p.now())
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, err = p.writer.Write(line); err != nil {
+		return fmt.Errorf("could not write message for topic %s: %v", topic, err)
+	}
+	return nil
+}
+
+type topicFile struct {
+	file *os.File
+	size int64
+}
+
+// Appends the messages as newline delimited JSON to a file per topic. Files reaching the maximum size are
+// rotated, keeping the most recent ones numbered from 1 onwards. Safe for concurrent use
+type FilePublisher struct {
+	dir        string
+	maxSize    int64
+	maxBackups int
+	now        func() time.Time
+	mutex      sync.Mutex
+	files      map[string]*topicFile
+}
+
+func NewFilePublisher(dir string) (*FilePublisher, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create messages directory %s: %v", dir, err)
+	}
+	return &FilePublisher{
+		dir:        dir,
+		maxSize:    10 * 1024 * 1024,
+		maxBackups: 5,
+		now:        time.Now,
+		files:      map[string]*topicFile{},
+	}, nil
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+func (p *FilePublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	line, err := encodeMessageLine(topic, message, attributes, p.now())
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	file, err := p.topicFile(topic)
+	if err != nil {
+		return err
+	}
+	if file.size > 0 && file.size+int64(len(line)) > p.maxSize {
+		if file, err = p.rotate(topic); err != nil {
+			return err
+		}
+	}
+
+	written, err := file.file.Write(line)
+	file.size += int64(written)
+	if err != nil {
+		return fmt.Errorf("could not write message for topic %s: %v", topic, err)
+	}
+	return nil
+}
+
+func (p *FilePublisher) topicFile(topic string) (*topicFile, error) {
+	if file, ok := p.files[topic]; ok {
+		return file, nil
+	}
+
+	path := p.path(topic, 0)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open messages file %s: %v", path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not read messages file %s: %v", path, err)
+	}
+
+	p.files[topic] = &topicFile{file: file, size: info.Size()}
+	return p.files[topic], nil
+}
+
+// Shifts the numbered files by one, dropping the oldest, and starts a new file for the topic
+func (p *FilePublisher) rotate(topic string) (*topicFile, error) {
+	if err := p.files[topic].file.Close(); err != nil {
+		return nil, fmt.Errorf("could not close messages file of topic %s: %v", topic, err)
+	}
+	delete(p.files, topic)
+
+	if err := os.Remove(p.path(topic, p.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not remove oldest messages file of topic %s: %v", topic, err)
+	}
+	for backup := p.maxBackups - 1; backup >= 0; backup-- {
+		err := os.Rename(p.path(topic, backup), p.path(topic, backup+1))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not rotate messages file of topic %s: %v", topic, err)
+		}
+	}
+	return p.topicFile(topic)
+}
+
+// Path of the current file of the topic, or of the numbered backup. Separators in topics are replaced
+func (p *FilePublisher) path(topic string, backup int) string {
+	name := strings.NewReplacer("/", "_", string(os.PathSeparator), "_").Replace(topic)
+	if backup > 0 {
+		name = fmt.Sprintf("%s.%d", name, backup)
+	}
+	return filepath.Join(p.dir, name+".ndjson")
+}
+
+// Maximum size in bytes of each file, which is only exceeded by files holding a single larger message
+func (p *FilePublisher) SetMaxSize(bytes int64) {
+	p.maxSize = bytes
+}
+
+// Number of rotated files kept per topic. Rotated files are deleted when zero
+func (p *FilePublisher) SetMaxBackups(backups int) {
+	p.maxBackups = backups
+}
+
+func (p *FilePublisher) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for topic, file := range p.files {
+		file.file.Close()
+		delete(p.files, topic)
+	}
+}
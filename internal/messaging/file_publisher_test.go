@@ -0,0 +1,157 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fileTopic = "festwrap.playlists.created"
+
+func filePublisherTime() time.Time {
+	return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func setupFilePublisher(t *testing.T) (*FilePublisher, string) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "messages")
+	publisher, err := NewFilePublisher(dir)
+	assert.Nil(t, err)
+	publisher.now = filePublisherTime
+	t.Cleanup(publisher.Close)
+	return publisher, dir
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestFilePublisherAppendsMessagesPerTopic(t *testing.T) {
+	publisher, dir := setupFilePublisher(t)
+
+	assert.Nil(t, publisher.Publish(context.Background(), fileTopic, []byte(`{"id":"first"}`)))
+	assert.Nil(t, publisher.PublishWithAttributes(
+		context.Background(), fileTopic, []byte(`{"id":"second"}`), map[string]string{"ce-type": "playlist_created"},
+	))
+	assert.Nil(t, publisher.Publish(context.Background(), "other/topic", []byte("not json")))
+
+	lines := readLines(t, filepath.Join(dir, fileTopic+".ndjson"))
+	assert.Len(t, lines, 2)
+	assert.JSONEq(
+		t,
+		`{"topic": "festwrap.playlists.created", "publishedAt": "2026-03-01T12:00:00Z", "message": {"id": "first"}}`,
+		lines[0],
+	)
+	assert.JSONEq(
+		t,
+		`{
+			"topic": "festwrap.playlists.created",
+			"publishedAt": "2026-03-01T12:00:00Z",
+			"attributes": {"ce-type": "playlist_created"},
+			"message": {"id": "second"}
+		}`,
+		lines[1],
+	)
+	otherLines := readLines(t, filepath.Join(dir, "other_topic.ndjson"))
+	assert.Equal(
+		t, []string{`{"topic":"other/topic","publishedAt":"2026-03-01T12:00:00Z","message":"not json"}`}, otherLines,
+	)
+}
+
+func TestFilePublisherAppendsToExistingFile(t *testing.T) {
+	publisher, dir := setupFilePublisher(t)
+	assert.Nil(t, publisher.Publish(context.Background(), fileTopic, []byte(`{"id":"first"}`)))
+	publisher.Close()
+
+	reopened, err := NewFilePublisher(dir)
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assert.Nil(t, reopened.Publish(context.Background(), fileTopic, []byte(`{"id":"second"}`)))
+
+	assert.Len(t, readLines(t, filepath.Join(dir, fileTopic+".ndjson")), 2)
+}
+
+func TestFilePublisherRotatesFiles(t *testing.T) {
+	publisher, dir := setupFilePublisher(t)
+	line, _ := encodeMessageLine(fileTopic, []byte(`{"id":"0"}`), nil, filePublisherTime())
+	// Two messages fit in each file
+	publisher.SetMaxSize(int64(2 * len(line)))
+	publisher.SetMaxBackups(2)
+
+	for i := range 7 {
+		message := []byte(fmt.Sprintf(`{"id":"%d"}`, i))
+		assert.Nil(t, publisher.Publish(context.Background(), fileTopic, message))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Nil(t, err)
+	assert.ElementsMatch(
+		t,
+		[]string{
+			filepath.Join(dir, fileTopic+".ndjson"),
+			filepath.Join(dir, fileTopic+".1.ndjson"),
+			filepath.Join(dir, fileTopic+".2.ndjson"),
+		},
+		files,
+	)
+	assert.Len(t, readLines(t, filepath.Join(dir, fileTopic+".ndjson")), 1)
+	assert.Contains(t, readLines(t, filepath.Join(dir, fileTopic+".1.ndjson"))[0], `{"id":"4"}`)
+	assert.Contains(t, readLines(t, filepath.Join(dir, fileTopic+".2.ndjson"))[0], `{"id":"2"}`)
+}
+
+func TestFilePublisherDeletesRotatedFilesWithoutBackups(t *testing.T) {
+	publisher, dir := setupFilePublisher(t)
+	publisher.SetMaxSize(1)
+	publisher.SetMaxBackups(0)
+
+	assert.Nil(t, publisher.Publish(context.Background(), fileTopic, []byte(`{"id":"first"}`)))
+	assert.Nil(t, publisher.Publish(context.Background(), fileTopic, []byte(`{"id":"second"}`)))
+
+	lines := readLines(t, filepath.Join(dir, fileTopic+".ndjson"))
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `{"id":"second"}`)
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Len(t, files, 1)
+}
+
+func TestFilePublisherReturnsErrorOnCancelledContext(t *testing.T) {
+	publisher, dir := setupFilePublisher(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := publisher.Publish(ctx, fileTopic, []byte(`{}`))
+
+	assert.NotNil(t, err)
+	_, statErr := os.Stat(filepath.Join(dir, fileTopic+".ndjson"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestWriterPublisherWritesMessages(t *testing.T) {
+	var output bytes.Buffer
+	publisher := NewWriterPublisher(&output)
+	publisher.now = filePublisherTime
+
+	assert.Nil(t, publisher.Publish(context.Background(), fileTopic, []byte(`{"id":"first"}`)))
+	assert.Nil(t, publisher.PublishWithAttributes(
+		context.Background(), fileTopic, []byte(`{"id":"second"}`), map[string]string{"ce-type": "playlist_created"},
+	))
+
+	expected := `{"topic":"festwrap.playlists.created","publishedAt":"2026-03-01T12:00:00Z","message":{"id":"first"}}` +
+		"\n" +
+		`{"topic":"festwrap.playlists.created","publishedAt":"2026-03-01T12:00:00Z",` +
+		`"attributes":{"ce-type":"playlist_created"},"message":{"id":"second"}}` +
+		"\n"
+	assert.Equal(t, expected, output.String())
+}
@@ -0,0 +1,299 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"festwrap/internal/logging"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// Brokers acknowledging a message before it is considered delivered
+type KafkaAcks string
+
+const (
+	KafkaAllAcks    KafkaAcks = "all"
+	KafkaLeaderAck  KafkaAcks = "leader"
+	KafkaNoAcks     KafkaAcks = "none"
+	defaultClientId           = "festwrap"
+)
+
+// Time closing the publisher waits for the messages still being produced
+const kafkaCloseTimeout = 10 * time.Second
+
+// Security protocols named as in the Kafka client configuration
+const (
+	KafkaPlaintext     = "PLAINTEXT"
+	KafkaSSL           = "SSL"
+	KafkaSASLPlaintext = "SASL_PLAINTEXT"
+	KafkaSASLSSL       = "SASL_SSL"
+)
+
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLScramSha256 = "SCRAM-SHA-256"
+	KafkaSASLScramSha512 = "SCRAM-SHA-512"
+)
+
+type KafkaConfig struct {
+	Brokers          []string
+	ClientId         string
+	Acks             KafkaAcks
+	SecurityProtocol string
+	SASLMechanism    string
+	SASLUsername     string
+	SASLPassword     string
+	// Certificate authorities to trust, in PEM format. The system ones are used when empty
+	TLSCAFile string
+	// Dotted path of the message field used as partition key, per topic. Messages of topics
+	// without a path, or without the field, are spread across partitions
+	PartitionKeys map[string]string
+}
+
+// Outcome of a message sent to Kafka. Failed deliveries have no partition nor offset
+type KafkaDeliveryReport struct {
+	Topic     string
+	Key       string
+	Partition int32
+	Offset    int64
+	Err       error
+	Duration  time.Duration
+}
+
+type KafkaMetrics struct {
+	Delivered int64
+	Failed    int64
+}
+
+// Message attributes are sent as record headers. Safe for concurrent use
+type KafkaPublisher struct {
+	client          *kgo.Client
+	logger          logging.Logger
+	partitionKeys   map[string]string
+	deliveryHandler func(KafkaDeliveryReport)
+	delivered       atomic.Int64
+	failed          atomic.Int64
+}
+
+func NewKafkaPublisher(config KafkaConfig, logger logging.Logger) (*KafkaPublisher, error) {
+	options, err := kafkaClientOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kgo.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Kafka client: %v", err)
+	}
+	return &KafkaPublisher{client: client, logger: logger, partitionKeys: config.PartitionKeys}, nil
+}
+
+func kafkaClientOptions(config KafkaConfig) ([]kgo.Opt, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("no Kafka brokers provided")
+	}
+
+	clientId := config.ClientId
+	if clientId == "" {
+		clientId = defaultClientId
+	}
+	options := []kgo.Opt{kgo.SeedBrokers(config.Brokers...), kgo.ClientID(clientId)}
+
+	switch config.Acks {
+	case KafkaAllAcks, "":
+		options = append(options, kgo.RequiredAcks(kgo.AllISRAcks()))
+	case KafkaLeaderAck:
+		// Idempotent writes need all the in-sync replicas to acknowledge
+		options = append(options, kgo.RequiredAcks(kgo.LeaderAck()), kgo.DisableIdempotentWrite())
+	case KafkaNoAcks:
+		options = append(options, kgo.RequiredAcks(kgo.NoAck()), kgo.DisableIdempotentWrite())
+	default:
+		return nil, fmt.Errorf("unsupported Kafka acks %s", config.Acks)
+	}
+
+	var useTLS, useSASL bool
+	switch config.SecurityProtocol {
+	case KafkaPlaintext, "":
+	case KafkaSSL:
+		useTLS = true
+	case KafkaSASLPlaintext:
+		useSASL = true
+	case KafkaSASLSSL:
+		useTLS, useSASL = true, true
+	default:
+		return nil, fmt.Errorf("unsupported Kafka security protocol %s", config.SecurityProtocol)
+	}
+
+	if useTLS {
+		tlsConfig, err := kafkaTLSConfig(config.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, kgo.DialTLSConfig(tlsConfig))
+	}
+	if useSASL {
+		mechanism, err := kafkaSASLMechanism(config)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, kgo.SASL(mechanism))
+	}
+	return options, nil
+}
+
+func kafkaTLSConfig(caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read Kafka CA file %s: %v", caFile, err)
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("no certificates found in Kafka CA file %s", caFile)
+	}
+	return tlsConfig, nil
+}
+
+func kafkaSASLMechanism(config KafkaConfig) (sasl.Mechanism, error) {
+	switch config.SASLMechanism {
+	case KafkaSASLPlain, "":
+		return plain.Auth{User: config.SASLUsername, Pass: config.SASLPassword}.AsMechanism(), nil
+	case KafkaSASLScramSha256:
+		return scram.Auth{User: config.SASLUsername, Pass: config.SASLPassword}.AsSha256Mechanism(), nil
+	case KafkaSASLScramSha512:
+		return scram.Auth{User: config.SASLUsername, Pass: config.SASLPassword}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("unsupported Kafka SASL mechanism %s", config.SASLMechanism)
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+// Waits until the message is acknowledged by the brokers
+func (p *KafkaPublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	record := &kgo.Record{Topic: topic, Value: message, Key: p.partitionKey(topic, message)}
+	for key, value := range attributes {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+	}
+
+	start := time.Now()
+	err := p.client.ProduceSync(ctx, record).FirstErr()
+	report := KafkaDeliveryReport{Topic: topic, Key: string(record.Key), Err: err, Duration: time.Since(start)}
+	if err != nil {
+		p.failed.Add(1)
+		p.logger.Error(fmt.Sprintf("Failed to publish message to topic %s: %v", topic, err))
+	} else {
+		p.delivered.Add(1)
+		report.Partition = record.Partition
+		report.Offset = record.Offset
+	}
+
+	if p.deliveryHandler != nil {
+		p.deliveryHandler(report)
+	}
+	return err
+}
+
+func (p *KafkaPublisher) partitionKey(topic string, message []byte) []byte {
+	path, ok := p.partitionKeys[topic]
+	if !ok {
+		return nil
+	}
+
+	// Numbers are kept as written, instead of being formatted again as floats
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	for _, field := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[field]
+	}
+
+	switch key := value.(type) {
+	case string:
+		return []byte(key)
+	case json.Number:
+		return []byte(key.String())
+	default:
+		return nil
+	}
+}
+
+// Called with the report of each message, after it is delivered or fails
+func (p *KafkaPublisher) SetDeliveryHandler(handler func(KafkaDeliveryReport)) {
+	p.deliveryHandler = handler
+}
+
+func (p *KafkaPublisher) Metrics() KafkaMetrics {
+	return KafkaMetrics{Delivered: p.delivered.Load(), Failed: p.failed.Load()}
+}
+
+// Waits for the messages still being produced before closing the connections
+func (p *KafkaPublisher) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaCloseTimeout)
+	defer cancel()
+	if err := p.client.Flush(ctx); err != nil {
+		p.logger.Warn(fmt.Sprintf("could not produce pending Kafka messages before closing: %v", err))
+	}
+	p.client.Close()
+}
+
+// Parses comma separated host:port addresses, skipping empty ones
+func ParseBrokers(value string) []string {
+	brokers := []string{}
+	for _, broker := range strings.Split(value, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
+// Parses comma separated topic=path pairs, such as "playlist-created=payload.playlist.id"
+func ParsePartitionKeys(value string) (map[string]string, error) {
+	partitionKeys := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		topic, path, found := strings.Cut(pair, "=")
+		topic = strings.TrimSpace(topic)
+		path = strings.TrimSpace(path)
+		if !found || topic == "" || path == "" {
+			return nil, fmt.Errorf("invalid partition key %s, expected topic=path", pair)
+		}
+		partitionKeys[topic] = path
+	}
+	return partitionKeys, nil
+}
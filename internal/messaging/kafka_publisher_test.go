@@ -0,0 +1,310 @@
+package messaging
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"festwrap/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const kafkaTopic = "playlist-created"
+
+func newKafkaCluster(t *testing.T, options ...kfake.Opt) *kfake.Cluster {
+	t.Helper()
+
+	cluster, err := kfake.NewCluster(append(options, kfake.NumBrokers(1), kfake.SeedTopics(3, kafkaTopic))...)
+	assert.Nil(t, err)
+	t.Cleanup(cluster.Close)
+	return cluster
+}
+
+func newKafkaPublisher(t *testing.T, config KafkaConfig) *KafkaPublisher {
+	t.Helper()
+
+	publisher, err := NewKafkaPublisher(config, logging.NoopLogger{})
+	assert.Nil(t, err)
+	t.Cleanup(publisher.Close)
+	return publisher
+}
+
+func consumeKafkaRecords(t *testing.T, cluster *kfake.Cluster, expected int) []*kgo.Record {
+	t.Helper()
+
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(kafkaTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	assert.Nil(t, err)
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records := []*kgo.Record{}
+	for len(records) < expected && ctx.Err() == nil {
+		records = append(records, consumer.PollFetches(ctx).Records()...)
+	}
+	return records
+}
+
+func TestKafkaPublisherPublishesMessage(t *testing.T) {
+	cluster := newKafkaCluster(t)
+	publisher := newKafkaPublisher(t, KafkaConfig{
+		Brokers:       cluster.ListenAddrs(),
+		PartitionKeys: map[string]string{kafkaTopic: "payload.playlist.id"},
+	})
+	message := []byte(`{"id":"some_event","payload":{"playlist":{"id":"some_playlist"}}}`)
+
+	err := publisher.PublishWithAttributes(
+		context.Background(), kafkaTopic, message, map[string]string{"ce-type": "playlist_created"},
+	)
+
+	assert.Nil(t, err)
+	records := consumeKafkaRecords(t, cluster, 1)
+	assert.Len(t, records, 1)
+	assert.Equal(t, message, records[0].Value)
+	assert.Equal(t, []byte("some_playlist"), records[0].Key)
+	assert.Equal(t, []kgo.RecordHeader{{Key: "ce-type", Value: []byte("playlist_created")}}, records[0].Headers)
+}
+
+func TestKafkaPublisherReportsDeliveries(t *testing.T) {
+	cluster := newKafkaCluster(t)
+	publisher := newKafkaPublisher(t, KafkaConfig{
+		Brokers:       cluster.ListenAddrs(),
+		PartitionKeys: map[string]string{kafkaTopic: "id"},
+	})
+	reports := []KafkaDeliveryReport{}
+	publisher.SetDeliveryHandler(func(report KafkaDeliveryReport) {
+		reports = append(reports, report)
+	})
+
+	for range 2 {
+		err := publisher.Publish(context.Background(), kafkaTopic, []byte(`{"id":"some_playlist"}`))
+		assert.Nil(t, err)
+	}
+
+	assert.Len(t, reports, 2)
+	for i, report := range reports {
+		assert.Nil(t, report.Err)
+		assert.Equal(t, kafkaTopic, report.Topic)
+		assert.Equal(t, "some_playlist", report.Key)
+		assert.Equal(t, int64(i), report.Offset)
+	}
+	// Messages with the same key go to the same partition
+	assert.Equal(t, reports[0].Partition, reports[1].Partition)
+	assert.Equal(t, KafkaMetrics{Delivered: 2}, publisher.Metrics())
+}
+
+func TestKafkaPublisherReportsFailedDeliveries(t *testing.T) {
+	cluster := newKafkaCluster(t)
+	publisher := newKafkaPublisher(t, KafkaConfig{Brokers: cluster.ListenAddrs()})
+	var report KafkaDeliveryReport
+	publisher.SetDeliveryHandler(func(delivered KafkaDeliveryReport) {
+		report = delivered
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := publisher.Publish(ctx, kafkaTopic, []byte(`{}`))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, err, report.Err)
+	assert.Equal(t, KafkaMetrics{Failed: 1}, publisher.Metrics())
+}
+
+func TestKafkaPublisherPublishesWithAcks(t *testing.T) {
+	for _, acks := range []KafkaAcks{KafkaAllAcks, KafkaLeaderAck, KafkaNoAcks} {
+		t.Run(string(acks), func(t *testing.T) {
+			cluster := newKafkaCluster(t)
+			publisher := newKafkaPublisher(t, KafkaConfig{Brokers: cluster.ListenAddrs(), Acks: acks})
+
+			err := publisher.Publish(context.Background(), kafkaTopic, []byte(`{}`))
+
+			assert.Nil(t, err)
+			assert.Len(t, consumeKafkaRecords(t, cluster, 1), 1)
+		})
+	}
+}
+
+func TestKafkaPublisherAuthenticatesWithSASL(t *testing.T) {
+	tests := map[string]struct {
+		mechanism   string
+		password    string
+		expectedErr bool
+	}{
+		"plain": {
+			mechanism: KafkaSASLPlain,
+			password:  "some_password",
+		},
+		"scram sha 256": {
+			mechanism: KafkaSASLScramSha256,
+			password:  "some_password",
+		},
+		"wrong password": {
+			mechanism:   KafkaSASLPlain,
+			password:    "wrong_password",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cluster := newKafkaCluster(t, kfake.EnableSASL(), kfake.Superuser(test.mechanism, "festwrap", "some_password"))
+			publisher := newKafkaPublisher(t, KafkaConfig{
+				Brokers:          cluster.ListenAddrs(),
+				SecurityProtocol: KafkaSASLPlaintext,
+				SASLMechanism:    test.mechanism,
+				SASLUsername:     "festwrap",
+				SASLPassword:     test.password,
+			})
+			// The client keeps retrying failed authentications until the context is done
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			err := publisher.Publish(ctx, kafkaTopic, []byte(`{}`))
+
+			assert.Equal(t, test.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestNewKafkaPublisherReturnsErrorOnInvalidConfig(t *testing.T) {
+	tests := map[string]KafkaConfig{
+		"no brokers":           {},
+		"unsupported acks":     {Brokers: []string{"localhost:9092"}, Acks: "some"},
+		"unsupported protocol": {Brokers: []string{"localhost:9092"}, SecurityProtocol: "KERBEROS"},
+		"unsupported mechanism": {
+			Brokers:          []string{"localhost:9092"},
+			SecurityProtocol: KafkaSASLSSL,
+			SASLMechanism:    "GSSAPI",
+		},
+		"missing CA file": {
+			Brokers:          []string{"localhost:9092"},
+			SecurityProtocol: KafkaSSL,
+			TLSCAFile:        filepath.Join(t.TempDir(), "ca.pem"),
+		},
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewKafkaPublisher(config, logging.NoopLogger{})
+
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestKafkaPartitionKey(t *testing.T) {
+	tests := map[string]struct {
+		message  string
+		path     string
+		expected []byte
+	}{
+		"nested string": {
+			message:  `{"payload":{"playlist":{"id":"some_playlist"}}}`,
+			path:     "payload.playlist.id",
+			expected: []byte("some_playlist"),
+		},
+		"number": {
+			message:  `{"payload":{"count":1000000}}`,
+			path:     "payload.count",
+			expected: []byte("1000000"),
+		},
+		"missing field": {
+			message: `{"payload":{}}`,
+			path:    "payload.playlist.id",
+		},
+		"object field": {
+			message: `{"payload":{"playlist":{"id":"some_playlist"}}}`,
+			path:    "payload.playlist",
+		},
+		"invalid json": {
+			message: `{`,
+			path:    "id",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			publisher := KafkaPublisher{partitionKeys: map[string]string{kafkaTopic: test.path}}
+
+			actual := publisher.partitionKey(kafkaTopic, []byte(test.message))
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestKafkaPartitionKeyIsEmptyForTopicWithoutPath(t *testing.T) {
+	publisher := KafkaPublisher{partitionKeys: map[string]string{"other-topic": "id"}}
+
+	actual := publisher.partitionKey(kafkaTopic, []byte(`{"id":"some_playlist"}`))
+
+	assert.Nil(t, actual)
+}
+
+func TestParseBrokers(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected []string
+	}{
+		"empty": {
+			value:    "",
+			expected: []string{},
+		},
+		"several brokers": {
+			value:    "broker1:9092, broker2:9092",
+			expected: []string{"broker1:9092", "broker2:9092"},
+		},
+		"empty entries": {
+			value:    "broker1:9092,, ,",
+			expected: []string{"broker1:9092"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseBrokers(test.value))
+		})
+	}
+}
+
+func TestParsePartitionKeys(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		"empty": {
+			value:    "",
+			expected: map[string]string{},
+		},
+		"several topics": {
+			value:    "playlist-created=payload.playlist.id, playlist-updated = payload.playlist.id",
+			expected: map[string]string{"playlist-created": "payload.playlist.id", "playlist-updated": "payload.playlist.id"},
+		},
+		"missing path": {
+			value:       "playlist-created=",
+			expectedErr: true,
+		},
+		"missing separator": {
+			value:       "playlist-created",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParsePartitionKeys(test.value)
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"festwrap/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Stores the messages in the outbox instead of publishing them, so they are not lost if the publisher is down.
+// An outbox relay publishes them afterwards
+type OutboxPublisher struct {
+	store OutboxStore
+	now   func() time.Time
+}
+
+func NewOutboxPublisher(store OutboxStore) *OutboxPublisher {
+	return &OutboxPublisher{store: store, now: time.Now}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+func (p *OutboxPublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	now := p.now()
+	outboxMessage := OutboxMessage{
+		Id:            uuid.NewString(),
+		Topic:         topic,
+		Payload:       slices.Clone(message),
+		Attributes:    maps.Clone(attributes),
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	if err := p.store.Add(outboxMessage); err != nil {
+		return fmt.Errorf("could not add message to outbox: %v", err)
+	}
+	return nil
+}
+
+type OutboxMetrics struct {
+	Pending          int
+	DeadLettered     int
+	OldestPendingAge time.Duration
+	Published        int64
+	Retried          int64
+}
+
+// Publishes the messages in the outbox, retrying failures with exponential backoff until the maximum attempts,
+// after which they are moved to the dead letters
+type OutboxRelay struct {
+	store          OutboxStore
+	publisher      Publisher
+	logger         logging.Logger
+	pollInterval   time.Duration
+	batchSize      int
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	publishTimeout time.Duration
+	now            func() time.Time
+	published      atomic.Int64
+	retried        atomic.Int64
+	stop           chan struct{}
+	done           chan struct{}
+}
+
+func NewOutboxRelay(store OutboxStore, publisher Publisher, logger logging.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		store:          store,
+		publisher:      publisher,
+		logger:         logger,
+		pollInterval:   time.Second,
+		batchSize:      100,
+		maxAttempts:    10,
+		initialBackoff: time.Second,
+		maxBackoff:     5 * time.Minute,
+		publishTimeout: 10 * time.Second,
+		now:            time.Now,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+func (r *OutboxRelay) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+		for {
+			r.relayDue()
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Waits for the batch being published. Messages still pending are published after the next start
+func (r *OutboxRelay) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *OutboxRelay) relayDue() {
+	messages, err := r.store.Due(r.now(), r.batchSize)
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("could not read outbox messages: %v", err))
+		return
+	}
+
+	for _, message := range messages {
+		if err = r.relay(message); err != nil {
+			r.logger.Error(fmt.Sprintf("could not update outbox message %s: %v", message.Id, err))
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(message OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.publishTimeout)
+	defer cancel()
+
+	publishErr := PublishWithAttributes(ctx, r.publisher, message.Topic, message.Payload, message.Attributes)
+	if publishErr == nil {
+		r.published.Add(1)
+		return r.store.MarkDone(message.Id)
+	}
+
+	message.Attempts += 1
+	message.LastError = publishErr.Error()
+	if message.Attempts >= r.maxAttempts {
+		r.logger.Error(fmt.Sprintf(
+			"moving outbox message %s for topic %s to dead letters after %d attempts: %v",
+			message.Id, message.Topic, message.Attempts, publishErr,
+		))
+		return r.store.DeadLetter(message)
+	}
+
+	r.retried.Add(1)
+	message.NextAttemptAt = r.now().Add(r.backoff(message.Attempts))
+	r.logger.Warn(fmt.Sprintf(
+		"could not publish outbox message %s for topic %s, retrying at %v: %v",
+		message.Id, message.Topic, message.NextAttemptAt, publishErr,
+	))
+	return r.store.Retry(message)
+}
+
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	backoff := r.initialBackoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= r.maxBackoff {
+			return r.maxBackoff
+		}
+	}
+	return backoff
+}
+
+func (r *OutboxRelay) Metrics() (OutboxMetrics, error) {
+	backlog, err := r.store.Backlog()
+	if err != nil {
+		return OutboxMetrics{}, err
+	}
+
+	metrics := OutboxMetrics{
+		Pending:      backlog.Pending,
+		DeadLettered: backlog.DeadLettered,
+		Published:    r.published.Load(),
+		Retried:      r.retried.Load(),
+	}
+	if !backlog.OldestPendingAt.IsZero() {
+		metrics.OldestPendingAge = r.now().Sub(backlog.OldestPendingAt)
+	}
+	return metrics, nil
+}
+
+func (r *OutboxRelay) SetPollInterval(interval time.Duration) {
+	r.pollInterval = interval
+}
+
+func (r *OutboxRelay) SetMaxAttempts(attempts int) {
+	r.maxAttempts = attempts
+}
+
+// Sets the wait before the first retry, which doubles on each attempt up to the maximum
+func (r *OutboxRelay) SetBackoff(initialBackoff time.Duration, maxBackoff time.Duration) {
+	r.initialBackoff = initialBackoff
+	r.maxBackoff = maxBackoff
+}
@@ -0,0 +1,226 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message waiting in the outbox to be published
+type OutboxMessage struct {
+	Id            string            `json:"id"`
+	Topic         string            `json:"topic"`
+	Payload       []byte            `json:"payload"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"nextAttemptAt"`
+	LastError     string            `json:"lastError,omitempty"`
+}
+
+type OutboxBacklog struct {
+	Pending         int
+	DeadLettered    int
+	OldestPendingAt time.Time
+}
+
+type OutboxStore interface {
+	Add(message OutboxMessage) error
+	// Returns the pending messages whose next attempt is due, oldest first
+	Due(now time.Time, limit int) ([]OutboxMessage, error)
+	MarkDone(id string) error
+	// Keeps the message pending with its attempts, next attempt and last error updated
+	Retry(message OutboxMessage) error
+	DeadLetter(message OutboxMessage) error
+	Backlog() (OutboxBacklog, error)
+}
+
+const (
+	pendingDir    = "pending"
+	deadLetterDir = "dead_letter"
+)
+
+// Keeps each message in its own file, so they survive restarts. Pending messages are also indexed in memory
+type FileOutboxStore struct {
+	mutex        sync.Mutex
+	dir          string
+	pending      map[string]OutboxMessage
+	deadLettered int
+}
+
+// Loads the messages left pending by previous runs from the directory, which is created if missing
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	for _, subdir := range []string{pendingDir, deadLetterDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o755); err != nil {
+			return nil, fmt.Errorf("could not create outbox directory: %v", err)
+		}
+	}
+
+	store := &FileOutboxStore{dir: dir, pending: map[string]OutboxMessage{}}
+	pendingFiles, err := messageFiles(filepath.Join(dir, pendingDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range pendingFiles {
+		message, err := readMessage(path)
+		if err != nil {
+			return nil, err
+		}
+		store.pending[message.Id] = message
+	}
+
+	deadLetterFiles, err := messageFiles(filepath.Join(dir, deadLetterDir))
+	if err != nil {
+		return nil, err
+	}
+	store.deadLettered = len(deadLetterFiles)
+	return store, nil
+}
+
+func (s *FileOutboxStore) Add(message OutboxMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.writeMessage(pendingDir, message); err != nil {
+		return err
+	}
+	s.pending[message.Id] = message
+	return nil
+}
+
+func (s *FileOutboxStore) Due(now time.Time, limit int) ([]OutboxMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	due := []OutboxMessage{}
+	for _, message := range s.pending {
+		if !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+	slices.SortFunc(due, func(a, b OutboxMessage) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *FileOutboxStore) MarkDone(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.Remove(s.messagePath(pendingDir, id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove outbox message %s: %v", id, err)
+	}
+	delete(s.pending, id)
+	return nil
+}
+
+func (s *FileOutboxStore) Retry(message OutboxMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.pending[message.Id]; !ok {
+		return fmt.Errorf("outbox message %s is not pending", message.Id)
+	}
+	if err := s.writeMessage(pendingDir, message); err != nil {
+		return err
+	}
+	s.pending[message.Id] = message
+	return nil
+}
+
+func (s *FileOutboxStore) DeadLetter(message OutboxMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.writeMessage(deadLetterDir, message); err != nil {
+		return err
+	}
+	if err := os.Remove(s.messagePath(pendingDir, message.Id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove outbox message %s: %v", message.Id, err)
+	}
+	delete(s.pending, message.Id)
+	s.deadLettered += 1
+	return nil
+}
+
+func (s *FileOutboxStore) Backlog() (OutboxBacklog, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	backlog := OutboxBacklog{Pending: len(s.pending), DeadLettered: s.deadLettered}
+	for _, message := range s.pending {
+		if backlog.OldestPendingAt.IsZero() || message.CreatedAt.Before(backlog.OldestPendingAt) {
+			backlog.OldestPendingAt = message.CreatedAt
+		}
+	}
+	return backlog, nil
+}
+
+func (s *FileOutboxStore) messagePath(subdir string, id string) string {
+	return filepath.Join(s.dir, subdir, id+".json")
+}
+
+// Writes to a temporary file first, so a crash never leaves a message half written
+func (s *FileOutboxStore) writeMessage(subdir string, message OutboxMessage) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("could not serialize outbox message %s: %v", message.Id, err)
+	}
+
+	file, err := os.CreateTemp(filepath.Join(s.dir, subdir), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not store outbox message %s: %v", message.Id, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(messageBytes); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), s.messagePath(subdir, message.Id))
+	}
+	if err != nil {
+		return fmt.Errorf("could not store outbox message %s: %v", message.Id, err)
+	}
+	return nil
+}
+
+func messageFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read outbox directory %s: %v", dir, err)
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func readMessage(path string) (OutboxMessage, error) {
+	messageBytes, err := os.ReadFile(path)
+	if err != nil {
+		return OutboxMessage{}, fmt.Errorf("could not read outbox message %s: %v", path, err)
+	}
+
+	var message OutboxMessage
+	if err = json.Unmarshal(messageBytes, &message); err != nil {
+		return OutboxMessage{}, fmt.Errorf("could not deserialize outbox message %s: %v", path, err)
+	}
+	return message, nil
+}
@@ -0,0 +1,171 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"festwrap/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const outboxTopic = "some_topic"
+
+type publishedMessage struct {
+	topic      string
+	message    []byte
+	attributes map[string]string
+}
+
+// Fails the first publications, as many as configured
+type fakePublisher struct {
+	mutex     sync.Mutex
+	failures  int
+	published []publishedMessage
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+func (p *fakePublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.failures > 0 {
+		p.failures -= 1
+		return errors.New("publish test error")
+	}
+	p.published = append(p.published, publishedMessage{topic: topic, message: message, attributes: attributes})
+	return nil
+}
+
+func (p *fakePublisher) getPublished() []publishedMessage {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.published
+}
+
+func testNow() time.Time {
+	return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func setupOutbox(t *testing.T, failures int) (*OutboxPublisher, *OutboxRelay, *fakePublisher, *FileOutboxStore) {
+	t.Helper()
+
+	store, err := NewFileOutboxStore(t.TempDir())
+	assert.Nil(t, err)
+	publisher := &fakePublisher{failures: failures}
+	outbox := NewOutboxPublisher(store)
+	outbox.now = testNow
+	relay := NewOutboxRelay(store, publisher, logging.NoopLogger{})
+	relay.now = testNow
+	relay.SetMaxAttempts(3)
+	relay.SetBackoff(time.Second, 3*time.Second)
+	return outbox, relay, publisher, store
+}
+
+func TestOutboxRelayPublishesStoredMessages(t *testing.T) {
+	outbox, relay, publisher, _ := setupOutbox(t, 0)
+
+	assert.Nil(t, outbox.Publish(context.Background(), outboxTopic, []byte("first")))
+	assert.Nil(t, outbox.Publish(context.Background(), outboxTopic, []byte("second")))
+	assert.Empty(t, publisher.getPublished())
+	relay.relayDue()
+
+	expected := []publishedMessage{
+		{topic: outboxTopic, message: []byte("first")},
+		{topic: outboxTopic, message: []byte("second")},
+	}
+	metrics, err := relay.Metrics()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, expected, publisher.getPublished())
+	assert.Equal(t, OutboxMetrics{Published: 2}, metrics)
+}
+
+func TestOutboxRelayPublishesAttributes(t *testing.T) {
+	outbox, relay, publisher, _ := setupOutbox(t, 0)
+	attributes := map[string]string{"ce-type": "playlist_created"}
+
+	assert.Nil(t, outbox.PublishWithAttributes(context.Background(), outboxTopic, []byte("message"), attributes))
+	relay.relayDue()
+
+	expected := []publishedMessage{{topic: outboxTopic, message: []byte("message"), attributes: attributes}}
+	assert.Equal(t, expected, publisher.getPublished())
+}
+
+func TestOutboxRelayRetriesWithBackoff(t *testing.T) {
+	outbox, relay, publisher, store := setupOutbox(t, 2)
+	assert.Nil(t, outbox.Publish(context.Background(), outboxTopic, []byte("message")))
+
+	relay.relayDue()
+	relay.relayDue()
+	pending, _ := store.Due(testNow().Add(time.Hour), 10)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, testNow().Add(time.Second), pending[0].NextAttemptAt)
+	assert.Equal(t, "publish test error", pending[0].LastError)
+
+	relay.now = func() time.Time { return testNow().Add(time.Second) }
+	relay.relayDue()
+	pending, _ = store.Due(testNow().Add(time.Hour), 10)
+	assert.Equal(t, testNow().Add(3*time.Second), pending[0].NextAttemptAt)
+
+	relay.now = func() time.Time { return testNow().Add(3 * time.Second) }
+	relay.relayDue()
+	metrics, _ := relay.Metrics()
+	assert.Len(t, publisher.getPublished(), 1)
+	assert.Equal(t, OutboxMetrics{Published: 1, Retried: 2}, metrics)
+}
+
+func TestOutboxRelayMovesFailingMessagesToDeadLetters(t *testing.T) {
+	outbox, relay, publisher, store := setupOutbox(t, 3)
+	relay.SetBackoff(0, 0)
+	assert.Nil(t, outbox.Publish(context.Background(), outboxTopic, []byte("message")))
+
+	for range 4 {
+		relay.relayDue()
+	}
+
+	backlog, err := store.Backlog()
+	assert.Nil(t, err)
+	assert.Empty(t, publisher.getPublished())
+	assert.Equal(t, OutboxBacklog{DeadLettered: 1}, backlog)
+}
+
+func TestOutboxStoreKeepsPendingMessagesAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileOutboxStore(dir)
+	assert.Nil(t, err)
+	message := OutboxMessage{Id: "some_id", Topic: outboxTopic, Payload: []byte("message"), CreatedAt: testNow()}
+	assert.Nil(t, store.Add(message))
+	assert.Nil(t, store.DeadLetter(OutboxMessage{Id: "other_id", Topic: outboxTopic, CreatedAt: testNow()}))
+
+	reopened, err := NewFileOutboxStore(dir)
+	assert.Nil(t, err)
+	due, err := reopened.Due(testNow(), 10)
+	assert.Nil(t, err)
+	backlog, err := reopened.Backlog()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []OutboxMessage{message}, due)
+	assert.Equal(t, OutboxBacklog{Pending: 1, DeadLettered: 1, OldestPendingAt: testNow()}, backlog)
+}
+
+func TestOutboxRelayReportsBacklog(t *testing.T) {
+	outbox, relay, _, _ := setupOutbox(t, 0)
+	assert.Nil(t, outbox.Publish(context.Background(), outboxTopic, []byte("message")))
+	relay.now = func() time.Time { return testNow().Add(time.Minute) }
+
+	metrics, err := relay.Metrics()
+
+	assert.Nil(t, err)
+	assert.Equal(t, OutboxMetrics{Pending: 1, OldestPendingAge: time.Minute}, metrics)
+}
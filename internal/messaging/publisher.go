@@ -1,7 +1,35 @@
 package messaging
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type Publisher interface {
 	Publish(ctx context.Context, topic string, message []byte) error
 }
+
+// Publisher able to send metadata along with the message, such as Pub/Sub attributes
+type AttributesPublisher interface {
+	Publisher
+	PublishWithAttributes(ctx context.Context, topic string, message []byte, attributes map[string]string) error
+}
+
+// Fails if there are attributes the publisher cannot send, instead of silently dropping them
+func PublishWithAttributes(
+	ctx context.Context,
+	publisher Publisher,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
+	if len(attributes) == 0 {
+		return publisher.Publish(ctx, topic, message)
+	}
+
+	attributesPublisher, ok := publisher.(AttributesPublisher)
+	if !ok {
+		return fmt.Errorf("publisher %T does not support message attributes", publisher)
+	}
+	return attributesPublisher.PublishWithAttributes(ctx, topic, message, attributes)
+}
@@ -19,9 +19,19 @@ func NewPubsubPublisher(client *pubsub.Client, logger logging.Logger) PubsubPubl
 }
 
 func (p PubsubPublisher) Publish(ctx context.Context, topic string, message []byte) error {
+	return p.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+func (p PubsubPublisher) PublishWithAttributes(
+	ctx context.Context,
+	topic string,
+	message []byte,
+	attributes map[string]string,
+) error {
 	pubsubTopic := p.client.Topic(topic)
 	result := pubsubTopic.Publish(ctx, &pubsub.Message{
-		Data: message,
+		Data:       message,
+		Attributes: attributes,
 	})
 
 	_, err := result.Get(ctx)
@@ -0,0 +1,59 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Failure when adding the songs in the interval [Start, End) of the requested songs
+type ChunkError struct {
+	Start int
+	End   int
+	Err   error
+}
+
+func (e ChunkError) Error() string {
+	return fmt.Sprintf("could not add songs [%d, %d): %v", e.Start, e.End, e.Err)
+}
+
+func (e ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// Returned when some of the chunks used to add songs to a playlist failed
+type AddSongsError struct {
+	Chunks []ChunkError
+}
+
+func (e *AddSongsError) Error() string {
+	messages := make([]string, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		messages[i] = chunk.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *AddSongsError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		errs[i] = chunk
+	}
+	return errs
+}
+
+// Reports whether the song in the given position of the request failed to be added
+func (e *AddSongsError) IsFailed(index int) bool {
+	for _, chunk := range e.Chunks {
+		if index >= chunk.Start && index < chunk.End {
+			return true
+		}
+	}
+	return false
+}
+
+func AsAddSongsError(err error) (*AddSongsError, bool) {
+	var addSongsErr *AddSongsError
+	ok := errors.As(err, &addSongsErr)
+	return addSongsErr, ok
+}
@@ -0,0 +1,104 @@
+package applemusic
+
+import (
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+	songapplemusic "festwrap/internal/song/applemusic"
+)
+
+type appleMusicPlaylistAttributes struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"isPublic"`
+}
+
+type appleMusicCreatePlaylistRequest struct {
+	Attributes appleMusicPlaylistAttributes `json:"attributes"`
+}
+
+type appleMusicResource struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type appleMusicAddTracksRequest struct {
+	Data []appleMusicResource `json:"data"`
+}
+
+func newAppleMusicAddTracksRequest(songs []song.Song) appleMusicAddTracksRequest {
+	tracks := make([]appleMusicResource, len(songs))
+	for i, currentSong := range songs {
+		tracks[i] = appleMusicResource{Id: currentSong.Id, Type: "songs"}
+	}
+	return appleMusicAddTracksRequest{Data: tracks}
+}
+
+type appleMusicCreatePlaylistResponse struct {
+	Data []appleMusicResource `json:"data"`
+}
+
+type appleMusicDescription struct {
+	Standard string `json:"standard"`
+}
+
+type appleMusicLibraryPlaylistAttributes struct {
+	Name        string                `json:"name"`
+	Description appleMusicDescription `json:"description"`
+	IsPublic    bool                  `json:"isPublic"`
+	CanEdit     bool                  `json:"canEdit"`
+}
+
+type appleMusicLibraryPlaylist struct {
+	Id         string                              `json:"id"`
+	Attributes appleMusicLibraryPlaylistAttributes `json:"attributes"`
+}
+
+type appleMusicGetPlaylistResponse struct {
+	Data []appleMusicLibraryPlaylist `json:"data"`
+}
+
+// Library playlists can only be read by their owner, so the owner is the user performing the request
+func (p appleMusicLibraryPlaylist) toPlaylist(userId string) playlist.Playlist {
+	ownerId := ""
+	if p.Attributes.CanEdit {
+		ownerId = userId
+	}
+	return playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{
+			Name:        p.Attributes.Name,
+			Description: p.Attributes.Description.Standard,
+			IsPublic:    p.Attributes.IsPublic,
+		},
+		Id:      p.Id,
+		OwnerId: ownerId,
+	}
+}
+
+type appleMusicPlayParams struct {
+	CatalogId string `json:"catalogId"`
+}
+
+type appleMusicLibrarySongAttributes struct {
+	PlayParams *appleMusicPlayParams `json:"playParams"`
+}
+
+type appleMusicLibrarySong struct {
+	Attributes appleMusicLibrarySongAttributes `json:"attributes"`
+}
+
+type appleMusicPlaylistTracksResponse struct {
+	Data []appleMusicLibrarySong `json:"data"`
+	Next string                  `json:"next"`
+}
+
+func (r appleMusicPlaylistTracksResponse) getSongs() []song.Song {
+	songs := []song.Song{}
+	for _, librarySong := range r.Data {
+		// Songs uploaded by the user are not part of the catalog
+		playParams := librarySong.Attributes.PlayParams
+		if playParams != nil && playParams.CatalogId != "" {
+			songs = append(songs, song.Song{Id: playParams.CatalogId, Uri: songapplemusic.SongUri(playParams.CatalogId)})
+		}
+	}
+	return songs
+}
@@ -0,0 +1,255 @@
+package applemusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	types "festwrap/internal"
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/playlist"
+	"festwrap/internal/serialization"
+	"festwrap/internal/song"
+)
+
+const (
+	maxSongsPerRequest = 100
+	getSongsPageSize   = 100
+)
+
+// Playlist repository working on the Apple Music library of the user. Requests need both the developer
+// token and the music user token of the user
+type AppleMusicPlaylistRepository struct {
+	developerTokenKey          types.ContextKey
+	userTokenKey               types.ContextKey
+	userIdKey                  types.ContextKey
+	host                       string
+	httpSender                 httpsender.HTTPRequestSender
+	maxSongsPerRequest         int
+	playlistCreateSerializer   serialization.Serializer[appleMusicCreatePlaylistRequest]
+	playlistCreateDeserializer serialization.Deserializer[appleMusicCreatePlaylistResponse]
+	addTracksSerializer        serialization.Serializer[appleMusicAddTracksRequest]
+	getPlaylistDeserializer    serialization.Deserializer[appleMusicGetPlaylistResponse]
+	getSongsDeserializer       serialization.Deserializer[appleMusicPlaylistTracksResponse]
+}
+
+func NewAppleMusicPlaylistRepository(httpSender httpsender.HTTPRequestSender) AppleMusicPlaylistRepository {
+	playlistCreateSerializer := serialization.NewJsonSerializer[appleMusicCreatePlaylistRequest]()
+	addTracksSerializer := serialization.NewJsonSerializer[appleMusicAddTracksRequest]()
+	return AppleMusicPlaylistRepository{
+		developerTokenKey:          "apple_music_token",
+		userTokenKey:               "music_user_token",
+		userIdKey:                  "user_id",
+		host:                       "api.music.apple.com",
+		httpSender:                 httpSender,
+		maxSongsPerRequest:         maxSongsPerRequest,
+		playlistCreateSerializer:   &playlistCreateSerializer,
+		playlistCreateDeserializer: serialization.NewJsonDeserializer[appleMusicCreatePlaylistResponse](),
+		addTracksSerializer:        &addTracksSerializer,
+		getPlaylistDeserializer:    serialization.NewJsonDeserializer[appleMusicGetPlaylistResponse](),
+		getSongsDeserializer:       serialization.NewJsonDeserializer[appleMusicPlaylistTracksResponse](),
+	}
+}
+
+func (r *AppleMusicPlaylistRepository) GetPlaylist(ctx context.Context, playlistId string) (playlist.Playlist, error) {
+	headers, err := r.getHeaders(ctx)
+	if err != nil {
+		return playlist.Playlist{}, fmt.Errorf("could not get playlist: %v", err)
+	}
+
+	userId, ok := ctx.Value(r.userIdKey).(string)
+	if !ok {
+		return playlist.Playlist{}, errors.New("could not retrieve user id from context when getting playlist")
+	}
+
+	url := fmt.Sprintf("https://%s/v1/me/library/playlists/%s", r.host, playlistId)
+	response, err := r.httpSender.Send(r.httpOptions(url, httpsender.GET, 200, headers))
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
+	}
+
+	var parsedResponse appleMusicGetPlaylistResponse
+	err = r.getPlaylistDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
+	}
+
+	if len(parsedResponse.Data) == 0 {
+		return playlist.Playlist{}, fmt.Errorf("playlist %s not found", playlistId)
+	}
+
+	return parsedResponse.Data[0].toPlaylist(userId), nil
+}
+
+func (r *AppleMusicPlaylistRepository) GetSongs(ctx context.Context, playlistId string) ([]song.Song, error) {
+	headers, err := r.getHeaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get playlist songs: %v", err)
+	}
+
+	songs := []song.Song{}
+	for offset := 0; ; offset += getSongsPageSize {
+		queryParams := url.Values{}
+		queryParams.Set("limit", fmt.Sprint(getSongsPageSize))
+		queryParams.Set("offset", fmt.Sprint(offset))
+		url := fmt.Sprintf(
+			"https://%s/v1/me/library/playlists/%s/tracks?%s", r.host, playlistId, queryParams.Encode(),
+		)
+		response, err := r.httpSender.Send(r.httpOptions(url, httpsender.GET, 200, headers))
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		var parsedResponse appleMusicPlaylistTracksResponse
+		err = r.getSongsDeserializer.Deserialize(*response, &parsedResponse)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		songs = append(songs, parsedResponse.getSongs()...)
+		if parsedResponse.Next == "" {
+			break
+		}
+	}
+
+	return songs, nil
+}
+
+func (r *AppleMusicPlaylistRepository) CreatePlaylist(
+	ctx context.Context,
+	details playlist.PlaylistDetails,
+) (string, error) {
+	headers, err := r.getHeaders(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not create playlist: %v", err)
+	}
+
+	body, err := r.playlistCreateSerializer.Serialize(
+		appleMusicCreatePlaylistRequest{
+			Attributes: appleMusicPlaylistAttributes{
+				Name:        details.Name,
+				Description: details.Description,
+				IsPublic:    details.IsPublic,
+			},
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize playlist: %v", err.Error())
+	}
+
+	url := fmt.Sprintf("https://%s/v1/me/library/playlists", r.host)
+	options := r.httpOptions(url, httpsender.POST, 201, headers)
+	options.SetBody(body)
+	response, err := r.httpSender.Send(options)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	var parsedResponse appleMusicCreatePlaylistResponse
+	err = r.playlistCreateDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	if len(parsedResponse.Data) == 0 {
+		return "", errors.New("no playlist returned when creating playlist")
+	}
+
+	return parsedResponse.Data[0].Id, nil
+}
+
+// Apple Music does not version playlists, so no snapshot ids are returned
+func (r *AppleMusicPlaylistRepository) AddSongs(
+	ctx context.Context,
+	playlistId string,
+	songs []song.Song,
+) ([]string, error) {
+	if len(songs) == 0 {
+		return nil, errors.New("no songs provided")
+	}
+
+	headers, err := r.getHeaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not add songs: %v", err)
+	}
+
+	addSongsErr := &playlist.AddSongsError{}
+	for start := 0; start < len(songs); start += r.maxSongsPerRequest {
+		end := min(start+r.maxSongsPerRequest, len(songs))
+		if err := r.addSongsChunk(playlistId, songs[start:end], headers); err != nil {
+			addSongsErr.Chunks = append(addSongsErr.Chunks, playlist.ChunkError{Start: start, End: end, Err: err})
+		}
+	}
+
+	if len(addSongsErr.Chunks) > 0 {
+		return nil, addSongsErr
+	}
+	return nil, nil
+}
+
+func (r *AppleMusicPlaylistRepository) addSongsChunk(
+	playlistId string,
+	songs []song.Song,
+	headers map[string]string,
+) error {
+	body, err := r.addTracksSerializer.Serialize(newAppleMusicAddTracksRequest(songs))
+	if err != nil {
+		return fmt.Errorf("could not serialize songs: %v", err.Error())
+	}
+
+	url := fmt.Sprintf("https://%s/v1/me/library/playlists/%s/tracks", r.host, playlistId)
+	options := r.httpOptions(url, httpsender.POST, 204, headers)
+	options.SetBody(body)
+	_, err = r.httpSender.Send(options)
+	return err
+}
+
+func (r *AppleMusicPlaylistRepository) getHeaders(ctx context.Context) (map[string]string, error) {
+	developerToken, ok := ctx.Value(r.developerTokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve developer token from context")
+	}
+
+	userToken, ok := ctx.Value(r.userTokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve music user token from context")
+	}
+
+	return map[string]string{
+		"Authorization":    fmt.Sprintf("Bearer %s", developerToken),
+		"Music-User-Token": userToken,
+		"Content-Type":     "application/json",
+	}, nil
+}
+
+func (r *AppleMusicPlaylistRepository) httpOptions(
+	url string,
+	method httpsender.Method,
+	expectedStatusCode int,
+	headers map[string]string,
+) httpsender.HTTPRequestOptions {
+	options := httpsender.NewHTTPRequestOptions(url, method, expectedStatusCode)
+	options.SetHeaders(headers)
+	return options
+}
+
+func (r *AppleMusicPlaylistRepository) SetDeveloperTokenKey(key types.ContextKey) {
+	r.developerTokenKey = key
+}
+
+func (r *AppleMusicPlaylistRepository) SetUserTokenKey(key types.ContextKey) {
+	r.userTokenKey = key
+}
+
+func (r *AppleMusicPlaylistRepository) SetUserIdKey(key types.ContextKey) {
+	r.userIdKey = key
+}
+
+func (r *AppleMusicPlaylistRepository) SetHost(host string) {
+	r.host = host
+}
+
+func (r *AppleMusicPlaylistRepository) SetMaxSongsPerRequest(maxSongs int) {
+	r.maxSongsPerRequest = max(maxSongs, 1)
+}
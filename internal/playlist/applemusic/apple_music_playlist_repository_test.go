@@ -0,0 +1,197 @@
+package applemusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	types "festwrap/internal"
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+	"festwrap/internal/testtools/stubserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	developerToken    = "developer_token"
+	developerTokenKey = types.ContextKey("developer")
+	userToken         = "user_token"
+	userTokenKey      = types.ContextKey("music_user")
+	userId            = "some_user"
+	userIdKey         = types.ContextKey("user")
+	newPlaylistId     = "p.new"
+	existingId        = "p.existing"
+)
+
+// Minimal stub of the Apple Music library API, storing the tracks added to each playlist
+type libraryStub struct {
+	mutex         sync.Mutex
+	createdBodies []string
+	addedTracks   map[string][]string
+}
+
+func (s *libraryStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer developer_token" || r.Header.Get("Music-User-Token") != userToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/me/library/playlists":
+		s.createdBodies = append(s.createdBodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"data":[{"id":"%s","type":"library-playlists"}]}`, newPlaylistId)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tracks"):
+		var request appleMusicAddTracksRequest
+		json.Unmarshal(body, &request)
+		for _, track := range request.Data {
+			if track.Id == "unavailable" {
+				http.Error(w, "track not available", http.StatusInternalServerError)
+				return
+			}
+		}
+		for _, track := range request.Data {
+			s.addedTracks[r.URL.Path] = append(s.addedTracks[r.URL.Path], track.Id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/me/library/playlists/p.existing":
+		fmt.Fprint(w, `{"data":[{"id":"p.existing","type":"library-playlists","attributes":`+
+			`{"name":"Festival","description":{"standard":"Warm up"},"isPublic":true,"canEdit":true}}]}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/me/library/playlists/p.existing/tracks":
+		if r.URL.Query().Get("offset") == "0" {
+			fmt.Fprint(w, `{"next":"/v1/me/library/playlists/p.existing/tracks?offset=100","data":[`+
+				`{"id":"i.1","attributes":{"playParams":{"id":"i.1","kind":"song","catalogId":"1"}}},`+
+				`{"id":"i.2","attributes":{"playParams":{"id":"i.2","kind":"song"}}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"i.3","attributes":{"playParams":{"id":"i.3","kind":"song","catalogId":"3"}}}]}`)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func stubRepository(t *testing.T) (AppleMusicPlaylistRepository, *libraryStub) {
+	t.Helper()
+	stub := &libraryStub{addedTracks: map[string][]string{}}
+	server := httptest.NewTLSServer(stub)
+	t.Cleanup(server.Close)
+	repository := NewAppleMusicPlaylistRepository(stubserver.NewSender(server))
+	repository.SetHost(strings.TrimPrefix(server.URL, "https://"))
+	repository.SetDeveloperTokenKey(developerTokenKey)
+	repository.SetUserTokenKey(userTokenKey)
+	repository.SetUserIdKey(userIdKey)
+	return repository, stub
+}
+
+func testContext() context.Context {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, developerTokenKey, developerToken)
+	ctx = context.WithValue(ctx, userTokenKey, userToken)
+	ctx = context.WithValue(ctx, userIdKey, userId)
+	return ctx
+}
+
+func catalogSongs(ids ...string) []song.Song {
+	songs := make([]song.Song, len(ids))
+	for i, id := range ids {
+		songs[i] = song.Song{Id: id, Uri: "apple_music:song:" + id}
+	}
+	return songs
+}
+
+func TestCreatePlaylistCreatesLibraryPlaylist(t *testing.T) {
+	repository, stub := stubRepository(t)
+	details := playlist.PlaylistDetails{Name: "my-playlist", Description: "some playlist", IsPublic: true}
+
+	actual, err := repository.CreatePlaylist(testContext(), details)
+
+	assert.Nil(t, err)
+	assert.Equal(t, newPlaylistId, actual)
+	expectedBody := `{"attributes":{"name":"my-playlist","description":"some playlist","isPublic":true}}`
+	assert.Equal(t, []string{expectedBody}, stub.createdBodies)
+}
+
+func TestCreatePlaylistReturnsErrorWithoutUserToken(t *testing.T) {
+	repository, stub := stubRepository(t)
+	ctx := context.WithValue(context.Background(), developerTokenKey, developerToken)
+
+	_, err := repository.CreatePlaylist(ctx, playlist.PlaylistDetails{Name: "my-playlist"})
+
+	assert.NotNil(t, err)
+	assert.Empty(t, stub.createdBodies)
+}
+
+func TestAddSongsAddsCatalogSongsInChunks(t *testing.T) {
+	repository, stub := stubRepository(t)
+	repository.SetMaxSongsPerRequest(2)
+
+	snapshotIds, err := repository.AddSongs(testContext(), newPlaylistId, catalogSongs("1", "2", "3"))
+
+	assert.Nil(t, err)
+	assert.Empty(t, snapshotIds)
+	assert.Equal(t, []string{"1", "2", "3"}, stub.addedTracks["/v1/me/library/playlists/p.new/tracks"])
+}
+
+func TestAddSongsReturnsFailedChunks(t *testing.T) {
+	repository, stub := stubRepository(t)
+	repository.SetMaxSongsPerRequest(2)
+
+	_, err := repository.AddSongs(testContext(), newPlaylistId, catalogSongs("1", "2", "unavailable"))
+
+	addSongsErr, ok := playlist.AsAddSongsError(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(addSongsErr.Chunks))
+	assert.Equal(t, 2, addSongsErr.Chunks[0].Start)
+	assert.Equal(t, 3, addSongsErr.Chunks[0].End)
+	assert.Equal(t, []string{"1", "2"}, stub.addedTracks["/v1/me/library/playlists/p.new/tracks"])
+}
+
+func TestAddSongsReturnsErrorWhenNoSongsProvided(t *testing.T) {
+	repository, _ := stubRepository(t)
+
+	_, err := repository.AddSongs(testContext(), newPlaylistId, []song.Song{})
+
+	assert.NotNil(t, err)
+}
+
+func TestGetPlaylistReturnsLibraryPlaylist(t *testing.T) {
+	repository, _ := stubRepository(t)
+
+	actual, err := repository.GetPlaylist(testContext(), existingId)
+
+	expected := playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{Name: "Festival", Description: "Warm up", IsPublic: true},
+		Id:              existingId,
+		OwnerId:         userId,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestGetPlaylistReturnsErrorIfNotFound(t *testing.T) {
+	repository, _ := stubRepository(t)
+
+	_, err := repository.GetPlaylist(testContext(), "p.missing")
+
+	assert.NotNil(t, err)
+}
+
+func TestGetSongsReturnsCatalogSongsFromAllPages(t *testing.T) {
+	repository, _ := stubRepository(t)
+
+	actual, err := repository.GetSongs(testContext(), existingId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, catalogSongs("1", "3"), actual)
+}
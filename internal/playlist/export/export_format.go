@@ -0,0 +1,30 @@
+package export
+
+type Format string
+
+const (
+	M3U8Format Format = "m3u8"
+	XSPFFormat Format = "xspf"
+	JSPFFormat Format = "jspf"
+	CSVFormat  Format = "csv"
+)
+
+var contentTypes = map[Format]string{
+	M3U8Format: "audio/x-mpegurl; charset=utf-8",
+	XSPFFormat: "application/xspf+xml; charset=utf-8",
+	JSPFFormat: "application/json; charset=utf-8",
+	CSVFormat:  "text/csv; charset=utf-8",
+}
+
+func IsValidFormat(format Format) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
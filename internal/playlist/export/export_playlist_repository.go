@@ -0,0 +1,81 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+
+	"github.com/google/uuid"
+)
+
+// Keeps playlists in memory until they are written to a file, instead of storing them in a streaming service
+type ExportPlaylistRepository struct {
+	mutex     sync.Mutex
+	playlists map[string]*playlist.Playlist
+	songs     map[string][]song.Song
+}
+
+func NewExportPlaylistRepository() *ExportPlaylistRepository {
+	return &ExportPlaylistRepository{
+		playlists: map[string]*playlist.Playlist{},
+		songs:     map[string][]song.Song{},
+	}
+}
+
+func (r *ExportPlaylistRepository) GetPlaylist(ctx context.Context, playlistId string) (playlist.Playlist, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existingPlaylist, ok := r.playlists[playlistId]
+	if !ok {
+		return playlist.Playlist{}, fmt.Errorf("playlist %s not found", playlistId)
+	}
+	return *existingPlaylist, nil
+}
+
+func (r *ExportPlaylistRepository) GetSongs(ctx context.Context, playlistId string) ([]song.Song, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	songs, ok := r.songs[playlistId]
+	if !ok {
+		return nil, fmt.Errorf("playlist %s not found", playlistId)
+	}
+	return slices.Clone(songs), nil
+}
+
+func (r *ExportPlaylistRepository) CreatePlaylist(ctx context.Context, details playlist.PlaylistDetails) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	playlistId := uuid.NewString()
+	r.playlists[playlistId] = &playlist.Playlist{PlaylistDetails: details, Id: playlistId}
+	r.songs[playlistId] = []song.Song{}
+	return playlistId, nil
+}
+
+// Exported playlists have no snapshots, so no snapshot ids are returned
+func (r *ExportPlaylistRepository) AddSongs(ctx context.Context, playlistId string, songs []song.Song) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.playlists[playlistId]; !ok {
+		return nil, fmt.Errorf("playlist %s not found", playlistId)
+	}
+	r.songs[playlistId] = append(r.songs[playlistId], songs...)
+	return nil, nil
+}
+
+// Releases the playlist once it has been exported
+func (r *ExportPlaylistRepository) DeletePlaylist(ctx context.Context, playlistId string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.playlists, playlistId)
+	delete(r.songs, playlistId)
+	return nil
+}
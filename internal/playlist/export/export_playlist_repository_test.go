@@ -0,0 +1,68 @@
+package export
+
+import (
+	"context"
+	"testing"
+
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPlaylistDetails() playlist.PlaylistDetails {
+	return playlist.PlaylistDetails{Name: "My playlist", Description: "Some description", IsPublic: true}
+}
+
+func TestExportRepositoryKeepsCreatedPlaylist(t *testing.T) {
+	repository := NewExportPlaylistRepository()
+
+	playlistId, err := repository.CreatePlaylist(context.Background(), testPlaylistDetails())
+	assert.Nil(t, err)
+	actual, err := repository.GetPlaylist(context.Background(), playlistId)
+
+	expected := playlist.Playlist{PlaylistDetails: testPlaylistDetails(), Id: playlistId}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestExportRepositoryAppendsSongsInOrder(t *testing.T) {
+	repository := NewExportPlaylistRepository()
+	playlistId, _ := repository.CreatePlaylist(context.Background(), testPlaylistDetails())
+
+	snapshotIds, err := repository.AddSongs(
+		context.Background(), playlistId, []song.Song{song.NewSong("uri1"), song.NewSong("uri2")},
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, snapshotIds)
+	_, err = repository.AddSongs(context.Background(), playlistId, []song.Song{song.NewSong("uri3")})
+	assert.Nil(t, err)
+	actual, err := repository.GetSongs(context.Background(), playlistId)
+
+	expected := []song.Song{song.NewSong("uri1"), song.NewSong("uri2"), song.NewSong("uri3")}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestExportRepositoryReturnsErrorForUnknownPlaylist(t *testing.T) {
+	repository := NewExportPlaylistRepository()
+
+	_, getErr := repository.GetPlaylist(context.Background(), "unknown")
+	_, songsErr := repository.GetSongs(context.Background(), "unknown")
+	_, addErr := repository.AddSongs(context.Background(), "unknown", []song.Song{song.NewSong("uri1")})
+
+	assert.NotNil(t, getErr)
+	assert.NotNil(t, songsErr)
+	assert.NotNil(t, addErr)
+}
+
+func TestExportRepositoryDeletesPlaylist(t *testing.T) {
+	repository := NewExportPlaylistRepository()
+	playlistId, _ := repository.CreatePlaylist(context.Background(), testPlaylistDetails())
+
+	deleteErr := repository.DeletePlaylist(context.Background(), playlistId)
+	_, err := repository.GetPlaylist(context.Background(), playlistId)
+
+	assert.Nil(t, deleteErr)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Song of an exported playlist. Uri is empty when the setlist song could not be resolved
+type Entry struct {
+	Artist     string
+	Title      string
+	SetlistUrl string
+	Uri        string
+	DurationMs int
+}
+
+func (e Entry) IsResolved() bool {
+	return e.Uri != ""
+}
+
+type ExportedPlaylist struct {
+	Name        string
+	Description string
+	Entries     []Entry
+}
+
+func Write(w io.Writer, format Format, exported ExportedPlaylist) error {
+	switch format {
+	case M3U8Format:
+		return writeM3U8(w, exported)
+	case XSPFFormat:
+		return writeXSPF(w, exported)
+	case JSPFFormat:
+		return writeJSPF(w, exported)
+	case CSVFormat:
+		return writeCSV(w, exported)
+	default:
+		return fmt.Errorf("unsupported export format %s", format)
+	}
+}
+
+// Songs that could not be resolved have no location, so they are only written as comments
+func writeM3U8(w io.Writer, exported ExportedPlaylist) error {
+	var builder strings.Builder
+	builder.WriteString("#EXTM3U\n")
+	builder.WriteString(fmt.Sprintf("#PLAYLIST:%s\n", singleLine(exported.Name)))
+	for _, entry := range exported.Entries {
+		name := singleLine(fmt.Sprintf("%s - %s", entry.Artist, entry.Title))
+		if !entry.IsResolved() {
+			builder.WriteString(fmt.Sprintf("# Not found: %s (setlist: %s)\n", name, entry.SetlistUrl))
+			continue
+		}
+		duration := -1
+		if entry.DurationMs > 0 {
+			duration = entry.DurationMs / 1000
+		}
+		builder.WriteString(fmt.Sprintf("#EXTINF:%d,%s\n", duration, name))
+		builder.WriteString(fmt.Sprintf("# Setlist: %s\n", entry.SetlistUrl))
+		builder.WriteString(entry.Uri + "\n")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type xspfPlaylist struct {
+	XMLName    xml.Name      `xml:"playlist"`
+	Version    string        `xml:"version,attr"`
+	Namespace  string        `xml:"xmlns,attr"`
+	Title      string        `xml:"title,omitempty"`
+	Annotation string        `xml:"annotation,omitempty"`
+	TrackList  xspfTrackList `xml:"trackList"`
+}
+
+type xspfTrackList struct {
+	Tracks []xspfTrack `xml:"track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Title    string `xml:"title"`
+	Creator  string `xml:"creator"`
+	Info     string `xml:"info,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+func writeXSPF(w io.Writer, exported ExportedPlaylist) error {
+	document := xspfPlaylist{
+		Version:    "1",
+		Namespace:  "http://xspf.org/ns/0/",
+		Title:      exported.Name,
+		Annotation: exported.Description,
+	}
+	for _, entry := range exported.Entries {
+		document.TrackList.Tracks = append(document.TrackList.Tracks, xspfTrack{
+			Location: entry.Uri,
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Info:     entry.SetlistUrl,
+			Duration: entry.DurationMs,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jspfDocument struct {
+	Playlist jspfPlaylist `json:"playlist"`
+}
+
+type jspfPlaylist struct {
+	Title      string      `json:"title"`
+	Annotation string      `json:"annotation,omitempty"`
+	Tracks     []jspfTrack `json:"track"`
+}
+
+type jspfTrack struct {
+	Location []string `json:"location,omitempty"`
+	Title    string   `json:"title"`
+	Creator  string   `json:"creator"`
+	Info     string   `json:"info,omitempty"`
+	Duration int      `json:"duration,omitempty"`
+}
+
+func writeJSPF(w io.Writer, exported ExportedPlaylist) error {
+	document := jspfDocument{
+		Playlist: jspfPlaylist{Title: exported.Name, Annotation: exported.Description, Tracks: []jspfTrack{}},
+	}
+	for _, entry := range exported.Entries {
+		track := jspfTrack{
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Info:     entry.SetlistUrl,
+			Duration: entry.DurationMs,
+		}
+		if entry.IsResolved() {
+			track.Location = []string{entry.Uri}
+		}
+		document.Playlist.Tracks = append(document.Playlist.Tracks, track)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func writeCSV(w io.Writer, exported ExportedPlaylist) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"artist", "title", "setlist_url", "spotify_uri"}); err != nil {
+		return err
+	}
+	for _, entry := range exported.Entries {
+		if err := writer.Write([]string{entry.Artist, entry.Title, entry.SetlistUrl, entry.Uri}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Line breaks would start a new entry in line based formats
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
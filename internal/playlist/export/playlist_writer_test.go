@@ -0,0 +1,137 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportedPlaylist() ExportedPlaylist {
+	return ExportedPlaylist{
+		Name:        "My playlist",
+		Description: "Some description",
+		Entries: []Entry{
+			{
+				Artist:     "Alexisonfire",
+				Title:      "Accidents",
+				SetlistUrl: "https://alexisonfire",
+				Uri:        "spotify:track:1",
+				DurationMs: 201000,
+			},
+			{Artist: "AFI", Title: "Silver and cold", SetlistUrl: "https://afi"},
+		},
+	}
+}
+
+func TestWritePlaylist(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		format   Format
+		expected string
+	}{
+		"m3u8": {
+			format: M3U8Format,
+			expected: "#EXTM3U\n" +
+				"#PLAYLIST:My playlist\n" +
+				"#EXTINF:201,Alexisonfire - Accidents\n" +
+				"# Setlist: https://alexisonfire\n" +
+				"spotify:track:1\n" +
+				"# Not found: AFI - Silver and cold (setlist: https://afi)\n",
+		},
+		"xspf": {
+			format: XSPFFormat,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>My playlist</title>
+  <annotation>Some description</annotation>
+  <trackList>
+    <track>
+      <location>spotify:track:1</location>
+      <title>Accidents</title>
+      <creator>Alexisonfire</creator>
+      <info>https://alexisonfire</info>
+      <duration>201000</duration>
+    </track>
+    <track>
+      <title>Silver and cold</title>
+      <creator>AFI</creator>
+      <info>https://afi</info>
+    </track>
+  </trackList>
+</playlist>
+`,
+		},
+		"jspf": {
+			format: JSPFFormat,
+			expected: `{
+  "playlist": {
+    "title": "My playlist",
+    "annotation": "Some description",
+    "track": [
+      {
+        "location": [
+          "spotify:track:1"
+        ],
+        "title": "Accidents",
+        "creator": "Alexisonfire",
+        "info": "https://alexisonfire",
+        "duration": 201000
+      },
+      {
+        "title": "Silver and cold",
+        "creator": "AFI",
+        "info": "https://afi"
+      }
+    ]
+  }
+}
+`,
+		},
+		"csv": {
+			format: CSVFormat,
+			expected: "artist,title,setlist_url,spotify_uri\n" +
+				"Alexisonfire,Accidents,https://alexisonfire,spotify:track:1\n" +
+				"AFI,Silver and cold,https://afi,\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+			err := Write(&buffer, test.format, testExportedPlaylist())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, buffer.String())
+		})
+	}
+}
+
+func TestWriteM3U8KeepsEntriesInSingleLine(t *testing.T) {
+	exported := ExportedPlaylist{
+		Name:    "My\nplaylist",
+		Entries: []Entry{{Artist: "AFI", Title: "Silver\nand cold", SetlistUrl: "https://afi", Uri: "spotify:track:1"}},
+	}
+
+	var buffer bytes.Buffer
+	err := Write(&buffer, M3U8Format, exported)
+
+	expected := "#EXTM3U\n" +
+		"#PLAYLIST:My playlist\n" +
+		"#EXTINF:-1,AFI - Silver and cold\n" +
+		"# Setlist: https://afi\n" +
+		"spotify:track:1\n"
+	assert.Nil(t, err)
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestWriteReturnsErrorForUnsupportedFormat(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := Write(&buffer, Format("pls"), testExportedPlaylist())
+
+	assert.NotNil(t, err)
+}
@@ -16,11 +16,47 @@ func NewPlaylistRepositoryMock() PlaylistRepositoryMock {
 	return PlaylistRepositoryMock{}
 }
 
+func (s *PlaylistRepositoryMock) GetPlaylist(ctx context.Context, playlistId string) (playlist.Playlist, error) {
+	args := s.Called(ctx, playlistId)
+	return args.Get(0).(playlist.Playlist), args.Error(1)
+}
+
+func (s *PlaylistRepositoryMock) GetSongs(ctx context.Context, playlistId string) ([]song.Song, error) {
+	args := s.Called(ctx, playlistId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]song.Song), args.Error(1)
+}
+
 func (s *PlaylistRepositoryMock) CreatePlaylist(ctx context.Context, playlistInput playlist.PlaylistDetails) (string, error) {
 	args := s.Called(ctx, playlistInput)
 	return args.String(0), args.Error(1)
 }
 
-func (s *PlaylistRepositoryMock) AddSongs(ctx context.Context, playlistId string, songs []song.Song) error {
-	return s.Called(ctx, playlistId, songs).Error(0)
+func (s *PlaylistRepositoryMock) AddSongs(ctx context.Context, playlistId string, songs []song.Song) ([]string, error) {
+	args := s.Called(ctx, playlistId, songs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Repository mock which is also able to delete playlists and remove their songs
+type CleanablePlaylistRepositoryMock struct {
+	PlaylistRepositoryMock
+}
+
+func NewCleanablePlaylistRepositoryMock() CleanablePlaylistRepositoryMock {
+	return CleanablePlaylistRepositoryMock{}
+}
+
+func (s *CleanablePlaylistRepositoryMock) DeletePlaylist(ctx context.Context, playlistId string) error {
+	args := s.Called(ctx, playlistId)
+	return args.Error(0)
+}
+
+func (s *CleanablePlaylistRepositoryMock) RemoveSongs(ctx context.Context, playlistId string, songs []song.Song) error {
+	args := s.Called(ctx, playlistId, songs)
+	return args.Error(0)
 }
@@ -1,12 +1,14 @@
 package playlist
 
 type PlaylistDetails struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	IsPublic    bool   `json:"isPublic"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	IsPublic        bool   `json:"isPublic"`
+	IsCollaborative bool   `json:"isCollaborative"`
 }
 
 type Playlist struct {
 	PlaylistDetails
-	Id string `json:"id"`
+	Id      string `json:"id"`
+	OwnerId string `json:"ownerId"`
 }
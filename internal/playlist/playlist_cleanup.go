@@ -0,0 +1,17 @@
+package playlist
+
+import (
+	"context"
+	"festwrap/internal/song"
+)
+
+// Implemented by repositories able to delete playlists, so failed creations do not leave empty playlists behind
+type PlaylistDeleter interface {
+	// Services where playlists cannot be deleted unfollow them instead, removing them from the user library
+	DeletePlaylist(ctx context.Context, playlistId string) error
+}
+
+// Implemented by repositories able to remove songs from playlists
+type SongRemover interface {
+	RemoveSongs(ctx context.Context, playlistId string, songs []song.Song) error
+}
@@ -6,6 +6,10 @@ import (
 )
 
 type PlaylistRepository interface {
+	GetPlaylist(ctx context.Context, playlistId string) (Playlist, error)
+	GetSongs(ctx context.Context, playlistId string) ([]song.Song, error)
 	CreatePlaylist(ctx context.Context, playlist PlaylistDetails) (string, error)
-	AddSongs(ctx context.Context, playlistId string, songs []song.Song) error
+	// Appends the songs in order and returns the playlist snapshot ids produced by the operation, if the
+	// backend supports them. When only some of the songs could be added, the error is an *AddSongsError
+	AddSongs(ctx context.Context, playlistId string, songs []song.Song) ([]string, error)
 }
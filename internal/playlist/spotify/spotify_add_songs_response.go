@@ -0,0 +1,5 @@
+package spotify
+
+type spotifyAddSongsResponse struct {
+	SnapshotId string `json:"snapshot_id"`
+}
@@ -0,0 +1,27 @@
+package spotify
+
+import "festwrap/internal/playlist"
+
+type spotifyOwner struct {
+	Id string `json:"id"`
+}
+
+type spotifyGetPlaylistResponse struct {
+	Id          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	IsPublic    bool         `json:"public"`
+	Owner       spotifyOwner `json:"owner"`
+}
+
+func (r spotifyGetPlaylistResponse) toPlaylist() playlist.Playlist {
+	return playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{
+			Name:        r.Name,
+			Description: r.Description,
+			IsPublic:    r.IsPublic,
+		},
+		Id:      r.Id,
+		OwnerId: r.Owner.Id,
+	}
+}
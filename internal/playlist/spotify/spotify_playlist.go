@@ -1,7 +1,8 @@
 package spotify
 
 type spotifyPlaylist struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	IsPublic    bool   `json:"public"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	IsPublic        bool   `json:"public"`
+	IsCollaborative bool   `json:"collaborative"`
 }
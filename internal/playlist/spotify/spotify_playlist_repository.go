@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 
 	types "festwrap/internal"
 	httpsender "festwrap/internal/http/sender"
@@ -12,54 +13,150 @@ import (
 	"festwrap/internal/song"
 )
 
+const (
+	maxSongsPerRequest = 100
+	getSongsPageSize   = 100
+)
+
 type SpotifyPlaylistRepository struct {
 	songsSerializer            serialization.Serializer[spotifySongs]
+	removeSongsSerializer      serialization.Serializer[spotifyRemovedSongs]
+	addSongsDeserializer       serialization.Deserializer[spotifyAddSongsResponse]
+	getPlaylistDeserializer    serialization.Deserializer[spotifyGetPlaylistResponse]
+	getSongsDeserializer       serialization.Deserializer[spotifyPlaylistTracksResponse]
 	playlistCreateSerializer   serialization.Serializer[spotifyPlaylist]
 	playlistCreateDeserializer serialization.Deserializer[spotifyCreatePlaylistResponse]
 	userIdKey                  types.ContextKey
 	tokenKey                   types.ContextKey
 	host                       string
 	httpSender                 httpsender.HTTPRequestSender
+	maxSongsPerRequest         int
 }
 
 func NewSpotifyPlaylistRepository(httpSender httpsender.HTTPRequestSender) SpotifyPlaylistRepository {
 	songSerializer := serialization.NewJsonSerializer[spotifySongs]()
+	removeSongsSerializer := serialization.NewJsonSerializer[spotifyRemovedSongs]()
 	playlistCreateSerializer := serialization.NewJsonSerializer[spotifyPlaylist]()
 	playlistCreateDeserializer := serialization.NewJsonDeserializer[spotifyCreatePlaylistResponse]()
+	addSongsDeserializer := serialization.NewJsonDeserializer[spotifyAddSongsResponse]()
+	getPlaylistDeserializer := serialization.NewJsonDeserializer[spotifyGetPlaylistResponse]()
+	getSongsDeserializer := serialization.NewJsonDeserializer[spotifyPlaylistTracksResponse]()
 	return SpotifyPlaylistRepository{
 		tokenKey:                   "token",
 		userIdKey:                  "user_id",
 		host:                       "api.spotify.com",
 		httpSender:                 httpSender,
 		songsSerializer:            &songSerializer,
+		removeSongsSerializer:      &removeSongsSerializer,
 		playlistCreateSerializer:   &playlistCreateSerializer,
 		playlistCreateDeserializer: playlistCreateDeserializer,
+		addSongsDeserializer:       addSongsDeserializer,
+		getPlaylistDeserializer:    getPlaylistDeserializer,
+		getSongsDeserializer:       getSongsDeserializer,
+		maxSongsPerRequest:         maxSongsPerRequest,
+	}
+}
+
+func (r *SpotifyPlaylistRepository) GetPlaylist(ctx context.Context, playlistId string) (playlist.Playlist, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return playlist.Playlist{}, errors.New("could not retrieve token from context when getting playlist")
+	}
+
+	response, err := r.httpSender.Send(r.getPlaylistHttpOptions(playlistId, token))
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
+	}
+
+	var parsedResponse spotifyGetPlaylistResponse
+	err = r.getPlaylistDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
 	}
+
+	return parsedResponse.toPlaylist(), nil
 }
 
-func (r *SpotifyPlaylistRepository) AddSongs(ctx context.Context, playlistId string, songs []song.Song) error {
+func (r *SpotifyPlaylistRepository) GetSongs(ctx context.Context, playlistId string) ([]song.Song, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve token from context when getting playlist songs")
+	}
+
+	songs := []song.Song{}
+	for offset := 0; ; offset += getSongsPageSize {
+		response, err := r.httpSender.Send(r.getSongsHttpOptions(playlistId, offset, token))
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		var parsedResponse spotifyPlaylistTracksResponse
+		err = r.getSongsDeserializer.Deserialize(*response, &parsedResponse)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		songs = append(songs, parsedResponse.getSongs()...)
+		if parsedResponse.Next == nil || *parsedResponse.Next == "" {
+			break
+		}
+	}
+
+	return songs, nil
+}
+
+func (r *SpotifyPlaylistRepository) AddSongs(
+	ctx context.Context,
+	playlistId string,
+	songs []song.Song,
+) ([]string, error) {
 	if len(songs) == 0 {
-		return errors.New("no songs provided")
+		return nil, errors.New("no songs provided")
 	}
 
 	token, ok := ctx.Value(r.tokenKey).(string)
 	if !ok {
-		return errors.New("could not retrieve token from context while adding songs")
+		return nil, errors.New("could not retrieve token from context while adding songs")
 	}
 
+	// Spotify limits the number of songs per request, so we send them in ordered chunks
+	snapshotIds := []string{}
+	addSongsErr := &playlist.AddSongsError{}
+	for start := 0; start < len(songs); start += r.maxSongsPerRequest {
+		end := min(start+r.maxSongsPerRequest, len(songs))
+		snapshotId, err := r.addSongsChunk(playlistId, songs[start:end], token)
+		if err != nil {
+			addSongsErr.Chunks = append(addSongsErr.Chunks, playlist.ChunkError{Start: start, End: end, Err: err})
+			continue
+		}
+		snapshotIds = append(snapshotIds, snapshotId)
+	}
+
+	if len(addSongsErr.Chunks) > 0 {
+		return snapshotIds, addSongsErr
+	}
+	return snapshotIds, nil
+}
+
+func (r *SpotifyPlaylistRepository) addSongsChunk(playlistId string, songs []song.Song, token string) (string, error) {
 	body, err := r.songsSerializer.Serialize(NewSpotifySongs(songs))
 	if err != nil {
-		errorMsg := fmt.Sprintf("could not serialize songs: %v", err.Error())
-		return errors.New(errorMsg)
+		return "", fmt.Errorf("could not serialize songs: %v", err.Error())
 	}
 
 	httpOptions := r.addSongsHttpOptions(playlistId, body, token)
-	_, err = r.httpSender.Send(httpOptions)
+	response, err := r.httpSender.Send(httpOptions)
 	if err != nil {
-		return errors.New(err.Error())
+		return "", errors.New(err.Error())
 	}
 
-	return nil
+	var parsedResponse spotifyAddSongsResponse
+	err = r.addSongsDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	return parsedResponse.SnapshotId, nil
 }
 
 func (r *SpotifyPlaylistRepository) CreatePlaylist(ctx context.Context, playlist playlist.PlaylistDetails) (string, error) {
@@ -75,9 +172,10 @@ func (r *SpotifyPlaylistRepository) CreatePlaylist(ctx context.Context, playlist
 
 	body, err := r.playlistCreateSerializer.Serialize(
 		spotifyPlaylist{
-			Name:        playlist.Name,
-			Description: playlist.Description,
-			IsPublic:    playlist.IsPublic,
+			Name:            playlist.Name,
+			Description:     playlist.Description,
+			IsPublic:        playlist.IsPublic,
+			IsCollaborative: playlist.IsCollaborative,
 		},
 	)
 	if err != nil {
@@ -99,6 +197,45 @@ func (r *SpotifyPlaylistRepository) CreatePlaylist(ctx context.Context, playlist
 	return parsedResponse.Id, nil
 }
 
+// Spotify does not delete playlists, so the playlist is unfollowed to remove it from the user library
+func (r *SpotifyPlaylistRepository) DeletePlaylist(ctx context.Context, playlistId string) error {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return errors.New("could not retrieve token from context when deleting playlist")
+	}
+
+	_, err := r.httpSender.Send(r.unfollowPlaylistHttpOptions(playlistId, token))
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+func (r *SpotifyPlaylistRepository) RemoveSongs(ctx context.Context, playlistId string, songs []song.Song) error {
+	if len(songs) == 0 {
+		return errors.New("no songs provided")
+	}
+
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return errors.New("could not retrieve token from context while removing songs")
+	}
+
+	for start := 0; start < len(songs); start += r.maxSongsPerRequest {
+		end := min(start+r.maxSongsPerRequest, len(songs))
+		body, err := r.removeSongsSerializer.Serialize(newSpotifyRemovedSongs(songs[start:end]))
+		if err != nil {
+			return fmt.Errorf("could not serialize songs: %v", err.Error())
+		}
+
+		_, err = r.httpSender.Send(r.removeSongsHttpOptions(playlistId, body, token))
+		if err != nil {
+			return fmt.Errorf("could not remove songs [%d, %d): %v", start, end, err)
+		}
+	}
+	return nil
+}
+
 func (r *SpotifyPlaylistRepository) SetUserIdKey(key types.ContextKey) {
 	r.userIdKey = key
 }
@@ -107,10 +244,38 @@ func (r *SpotifyPlaylistRepository) SetTokenKey(key types.ContextKey) {
 	r.tokenKey = key
 }
 
+func (r *SpotifyPlaylistRepository) SetMaxSongsPerRequest(maxSongs int) {
+	r.maxSongsPerRequest = max(maxSongs, 1)
+}
+
 func (r *SpotifyPlaylistRepository) GetHTTPSender() httpsender.HTTPRequestSender {
 	return r.httpSender
 }
 
+func (r *SpotifyPlaylistRepository) getPlaylistHttpOptions(
+	playlistId string, token string,
+) httpsender.HTTPRequestOptions {
+	queryParams := url.Values{}
+	queryParams.Set("fields", "id,name,description,public,owner(id)")
+	url := fmt.Sprintf("https://%s/v1/playlists/%s?%s", r.host, playlistId, queryParams.Encode())
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	httpOptions.SetHeaders(r.getSpotifyBaseHeaders(token))
+	return httpOptions
+}
+
+func (r *SpotifyPlaylistRepository) getSongsHttpOptions(
+	playlistId string, offset int, token string,
+) httpsender.HTTPRequestOptions {
+	queryParams := url.Values{}
+	queryParams.Set("fields", "items(track(uri)),next")
+	queryParams.Set("limit", fmt.Sprint(getSongsPageSize))
+	queryParams.Set("offset", fmt.Sprint(offset))
+	url := fmt.Sprintf("https://%s/v1/playlists/%s/tracks?%s", r.host, playlistId, queryParams.Encode())
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	httpOptions.SetHeaders(r.getSpotifyBaseHeaders(token))
+	return httpOptions
+}
+
 func (r *SpotifyPlaylistRepository) addSongsHttpOptions(
 	playlistId string, body []byte, token string,
 ) httpsender.HTTPRequestOptions {
@@ -121,6 +286,25 @@ func (r *SpotifyPlaylistRepository) addSongsHttpOptions(
 	return httpOptions
 }
 
+func (r *SpotifyPlaylistRepository) removeSongsHttpOptions(
+	playlistId string, body []byte, token string,
+) httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf("https://%s/v1/playlists/%s/tracks", r.host, playlistId)
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.DELETE, 200)
+	httpOptions.SetBody(body)
+	httpOptions.SetHeaders(r.getSpotifyBaseHeaders(token))
+	return httpOptions
+}
+
+func (r *SpotifyPlaylistRepository) unfollowPlaylistHttpOptions(
+	playlistId string, token string,
+) httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf("https://%s/v1/playlists/%s/followers", r.host, playlistId)
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.DELETE, 200)
+	httpOptions.SetHeaders(r.getSpotifyBaseHeaders(token))
+	return httpOptions
+}
+
 func (r *SpotifyPlaylistRepository) createPlaylistOptions(
 	userId string, body []byte, token string,
 ) httpsender.HTTPRequestOptions {
@@ -8,6 +8,7 @@ import (
 
 	types "festwrap/internal"
 	httpsender "festwrap/internal/http/sender"
+	sendermocks "festwrap/internal/http/sender/mocks"
 	"festwrap/internal/playlist"
 	"festwrap/internal/serialization"
 	"festwrap/internal/song"
@@ -18,6 +19,7 @@ import (
 const (
 	addSongsPlaylistId = "testId"
 	createPlaylistId   = "someId"
+	snapshotId         = "someSnapshot"
 	token              = "abcdefg12345" // gitleaks:allow
 	tokenKey           = "token"
 	userId             = "qrRwLBFxQL9fknW8NzBn4JprRNgS"
@@ -44,6 +46,13 @@ func nonJsonResponseSender() *httpsender.FakeHTTPSender {
 	return &sender
 }
 
+func addSongsSender() *httpsender.FakeHTTPSender {
+	sender := httpsender.FakeHTTPSender{}
+	response := fmt.Appendf(nil, `{"snapshot_id":"%s"}`, snapshotId)
+	sender.SetResponse(&response)
+	return &sender
+}
+
 func createPlaylistSender() *httpsender.FakeHTTPSender {
 	sender := httpsender.FakeHTTPSender{}
 	response := fmt.Appendf(nil, `{"id":"%s"}`, createPlaylistId)
@@ -56,7 +65,9 @@ func songsToAdd() []song.Song {
 }
 
 func playlistToCreate() playlist.PlaylistDetails {
-	return playlist.PlaylistDetails{Name: "my-playlist", Description: "some playlist", IsPublic: false}
+	return playlist.PlaylistDetails{
+		Name: "my-playlist", Description: "some playlist", IsPublic: false, IsCollaborative: true,
+	}
 }
 
 func testContext() context.Context {
@@ -67,18 +78,29 @@ func testContext() context.Context {
 }
 
 func addSongsHttpOptions() httpsender.HTTPRequestOptions {
+	return addSongsHttpOptionsWithBody(`{"uris":["uri1","uri2"]}`)
+}
+
+func addSongsHttpOptionsWithBody(body string) httpsender.HTTPRequestOptions {
 	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", addSongsPlaylistId)
 	options := httpsender.NewHTTPRequestOptions(url, httpsender.POST, 201)
 	options.SetHeaders(authHeaders())
-	options.SetBody([]byte(`{"uris":["uri1","uri2"]}`))
+	options.SetBody([]byte(body))
 	return options
 }
 
+func snapshotResponse(snapshotId string) *[]byte {
+	response := fmt.Appendf(nil, `{"snapshot_id":"%s"}`, snapshotId)
+	return &response
+}
+
 func createPlaylistHttpOptions() httpsender.HTTPRequestOptions {
 	url := fmt.Sprintf("https://api.spotify.com/v1/users/%s/playlists", userId)
 	options := httpsender.NewHTTPRequestOptions(url, httpsender.POST, 201)
 	options.SetHeaders(authHeaders())
-	createPlaylistBody := []byte(`{"name":"my-playlist","description":"some playlist","public":false}`)
+	createPlaylistBody := []byte(
+		`{"name":"my-playlist","description":"some playlist","public":false,"collaborative":true}`,
+	)
 	options.SetBody(createPlaylistBody)
 	return options
 }
@@ -100,7 +122,7 @@ func spotifyPlaylistRepository(sender httpsender.HTTPRequestSender) SpotifyPlayl
 func TestAddSongsReturnsErrorWhenNoSongsProvided(t *testing.T) {
 	repository := spotifyPlaylistRepository(emptyResponseSender())
 
-	err := repository.AddSongs(testContext(), addSongsPlaylistId, []song.Song{})
+	_, err := repository.AddSongs(testContext(), addSongsPlaylistId, []song.Song{})
 
 	assert.NotNil(t, err)
 }
@@ -118,17 +140,77 @@ func TestAddSongsSendsRequestUsingProperOptions(t *testing.T) {
 func TestAddSongsReturnsErrorOnSendError(t *testing.T) {
 	repository := spotifyPlaylistRepository(errorSender())
 
-	err := repository.AddSongs(testContext(), addSongsPlaylistId, songsToAdd())
+	_, err := repository.AddSongs(testContext(), addSongsPlaylistId, songsToAdd())
 
 	assert.NotNil(t, err)
 }
 
-func TestAddSongsReturnsNoError(t *testing.T) {
-	repository := spotifyPlaylistRepository(emptyResponseSender())
+func TestAddSongsReturnsErrorIfSenderResponseIsNotJson(t *testing.T) {
+	repository := spotifyPlaylistRepository(nonJsonResponseSender())
+
+	_, err := repository.AddSongs(testContext(), addSongsPlaylistId, songsToAdd())
+
+	assert.NotNil(t, err)
+}
+
+func TestAddSongsReturnsSnapshotId(t *testing.T) {
+	repository := spotifyPlaylistRepository(addSongsSender())
 
-	err := repository.AddSongs(testContext(), addSongsPlaylistId, songsToAdd())
+	actual, err := repository.AddSongs(testContext(), addSongsPlaylistId, songsToAdd())
 
 	assert.Nil(t, err)
+	assert.Equal(t, []string{snapshotId}, actual)
+}
+
+func TestAddSongsSendsSongsInOrderedChunks(t *testing.T) {
+	sender := &sendermocks.HTTPSenderMock{}
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri1","uri2"]}`)).Return(snapshotResponse("first"), nil)
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri3","uri4"]}`)).Return(snapshotResponse("second"), nil)
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri5"]}`)).Return(snapshotResponse("third"), nil)
+	repository := spotifyPlaylistRepository(sender)
+	repository.SetMaxSongsPerRequest(2)
+	songs := []song.Song{
+		song.NewSong("uri1"), song.NewSong("uri2"), song.NewSong("uri3"), song.NewSong("uri4"), song.NewSong("uri5"),
+	}
+
+	actual, err := repository.AddSongs(testContext(), addSongsPlaylistId, songs)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, actual)
+	sender.AssertExpectations(t)
+}
+
+func TestAddSongsSendsOneSongPerRequestWithNonPositiveMaxSongs(t *testing.T) {
+	sender := &sendermocks.HTTPSenderMock{}
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri1"]}`)).Return(snapshotResponse("first"), nil)
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri2"]}`)).Return(snapshotResponse("second"), nil)
+	repository := spotifyPlaylistRepository(sender)
+	repository.SetMaxSongsPerRequest(0)
+	songs := []song.Song{song.NewSong("uri1"), song.NewSong("uri2")}
+
+	actual, err := repository.AddSongs(testContext(), addSongsPlaylistId, songs)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, actual)
+	sender.AssertExpectations(t)
+}
+
+func TestAddSongsReportsFailedChunks(t *testing.T) {
+	sender := &sendermocks.HTTPSenderMock{}
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri1","uri2"]}`)).Return(snapshotResponse("first"), nil)
+	sender.On("Send", addSongsHttpOptionsWithBody(`{"uris":["uri3"]}`)).Return(nil, errors.New("test send error"))
+	repository := spotifyPlaylistRepository(sender)
+	repository.SetMaxSongsPerRequest(2)
+	songs := []song.Song{song.NewSong("uri1"), song.NewSong("uri2"), song.NewSong("uri3")}
+
+	actual, err := repository.AddSongs(testContext(), addSongsPlaylistId, songs)
+
+	addSongsErr, ok := playlist.AsAddSongsError(err)
+	assert.True(t, ok)
+	assert.Len(t, addSongsErr.Chunks, 1)
+	assert.Equal(t, 2, addSongsErr.Chunks[0].Start)
+	assert.Equal(t, 3, addSongsErr.Chunks[0].End)
+	assert.Equal(t, []string{"first"}, actual)
 }
 
 func TestCreatePlaylistReturnsErrorOnPlaylistSerializationError(t *testing.T) {
@@ -202,11 +284,23 @@ func TestRepositoryMethodsReturnErrorWhenInvalidToken(t *testing.T) {
 			repository := spotifyPlaylistRepository(emptyResponseSender())
 			repository.SetTokenKey(test.repositoryTokenKey)
 
-			err := repository.AddSongs(ctx, addSongsPlaylistId, songsToAdd())
+			_, err := repository.AddSongs(ctx, addSongsPlaylistId, songsToAdd())
 			assert.NotNil(t, err)
 
 			_, err = repository.CreatePlaylist(ctx, playlistToCreate())
 			assert.NotNil(t, err)
+
+			_, err = repository.GetPlaylist(ctx, addSongsPlaylistId)
+			assert.NotNil(t, err)
+
+			_, err = repository.GetSongs(ctx, addSongsPlaylistId)
+			assert.NotNil(t, err)
+
+			err = repository.DeletePlaylist(ctx, addSongsPlaylistId)
+			assert.NotNil(t, err)
+
+			err = repository.RemoveSongs(ctx, addSongsPlaylistId, songsToAdd())
+			assert.NotNil(t, err)
 		})
 	}
 }
@@ -241,3 +335,177 @@ func TestRepositoryMethodsReturnErrorWhenInvalidUserId(t *testing.T) {
 		})
 	}
 }
+
+func getPlaylistHttpOptions() httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf(
+		"https://api.spotify.com/v1/playlists/%s?fields=id%%2Cname%%2Cdescription%%2Cpublic%%2Cowner%%28id%%29",
+		addSongsPlaylistId,
+	)
+	options := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	options.SetHeaders(authHeaders())
+	return options
+}
+
+func getSongsHttpOptions(offset int) httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf(
+		"https://api.spotify.com/v1/playlists/%s/tracks?fields=items%%28track%%28uri%%29%%29%%2Cnext&limit=100&offset=%d",
+		addSongsPlaylistId,
+		offset,
+	)
+	options := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	options.SetHeaders(authHeaders())
+	return options
+}
+
+func getPlaylistSender() *httpsender.FakeHTTPSender {
+	sender := httpsender.FakeHTTPSender{}
+	response := fmt.Appendf(
+		nil,
+		`{"id":"%s","name":"my-playlist","description":"some playlist","public":true,"owner":{"id":"%s"}}`,
+		addSongsPlaylistId,
+		userId,
+	)
+	sender.SetResponse(&response)
+	return &sender
+}
+
+func TestGetPlaylistSendsRequestWithOptions(t *testing.T) {
+	sender := getPlaylistSender()
+	repository := spotifyPlaylistRepository(sender)
+
+	repository.GetPlaylist(testContext(), addSongsPlaylistId)
+
+	assert.Equal(t, getPlaylistHttpOptions(), sender.GetSendArgs())
+}
+
+func TestGetPlaylistReturnsPlaylistWithOwner(t *testing.T) {
+	repository := spotifyPlaylistRepository(getPlaylistSender())
+
+	actual, err := repository.GetPlaylist(testContext(), addSongsPlaylistId)
+
+	expected := playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{Name: "my-playlist", Description: "some playlist", IsPublic: true},
+		Id:              addSongsPlaylistId,
+		OwnerId:         userId,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestGetPlaylistReturnsErrorOnInvalidResponse(t *testing.T) {
+	tests := map[string]struct {
+		sender *httpsender.FakeHTTPSender
+	}{
+		"send error": {
+			sender: errorSender(),
+		},
+		"non json response": {
+			sender: nonJsonResponseSender(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repository := spotifyPlaylistRepository(test.sender)
+
+			_, err := repository.GetPlaylist(testContext(), addSongsPlaylistId)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestGetSongsReturnsSongsFromAllPages(t *testing.T) {
+	sender := &sendermocks.HTTPSenderMock{}
+	firstPage := []byte(`{"items":[{"track":{"uri":"uri1"}},{"track":null}],"next":"https://next_page"}`)
+	secondPage := []byte(`{"items":[{"track":{"uri":"uri2"}}],"next":null}`)
+	sender.On("Send", getSongsHttpOptions(0)).Return(&firstPage, nil)
+	sender.On("Send", getSongsHttpOptions(100)).Return(&secondPage, nil)
+	repository := spotifyPlaylistRepository(sender)
+
+	actual, err := repository.GetSongs(testContext(), addSongsPlaylistId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, songsToAdd(), actual)
+	sender.AssertExpectations(t)
+}
+
+func TestGetSongsReturnsErrorOnSendError(t *testing.T) {
+	repository := spotifyPlaylistRepository(errorSender())
+
+	_, err := repository.GetSongs(testContext(), addSongsPlaylistId)
+
+	assert.NotNil(t, err)
+}
+
+func unfollowPlaylistHttpOptions() httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/followers", addSongsPlaylistId)
+	options := httpsender.NewHTTPRequestOptions(url, httpsender.DELETE, 200)
+	options.SetHeaders(authHeaders())
+	return options
+}
+
+func removeSongsHttpOptions(body string) httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", addSongsPlaylistId)
+	options := httpsender.NewHTTPRequestOptions(url, httpsender.DELETE, 200)
+	options.SetHeaders(authHeaders())
+	options.SetBody([]byte(body))
+	return options
+}
+
+func TestDeletePlaylistUnfollowsPlaylist(t *testing.T) {
+	sender := emptyResponseSender()
+	repository := spotifyPlaylistRepository(sender)
+
+	err := repository.DeletePlaylist(testContext(), addSongsPlaylistId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, unfollowPlaylistHttpOptions(), sender.GetSendArgs())
+}
+
+func TestDeletePlaylistReturnsErrorOnSendError(t *testing.T) {
+	repository := spotifyPlaylistRepository(errorSender())
+
+	err := repository.DeletePlaylist(testContext(), addSongsPlaylistId)
+
+	assert.NotNil(t, err)
+}
+
+func TestRemoveSongsSendsSongsInChunks(t *testing.T) {
+	sender := &sendermocks.HTTPSenderMock{}
+	emptyResponse := []byte("")
+	sender.On("Send", removeSongsHttpOptions(`{"tracks":[{"uri":"uri1"},{"uri":"uri2"}]}`)).Return(&emptyResponse, nil)
+	sender.On("Send", removeSongsHttpOptions(`{"tracks":[{"uri":"uri3"}]}`)).Return(&emptyResponse, nil)
+	repository := spotifyPlaylistRepository(sender)
+	repository.SetMaxSongsPerRequest(2)
+	songs := []song.Song{song.NewSong("uri1"), song.NewSong("uri2"), song.NewSong("uri3")}
+
+	err := repository.RemoveSongs(testContext(), addSongsPlaylistId, songs)
+
+	assert.Nil(t, err)
+	sender.AssertExpectations(t)
+}
+
+func TestRemoveSongsReturnsError(t *testing.T) {
+	tests := map[string]struct {
+		sender httpsender.HTTPRequestSender
+		songs  []song.Song
+	}{
+		"no songs provided": {
+			sender: emptyResponseSender(),
+			songs:  []song.Song{},
+		},
+		"send error": {
+			sender: errorSender(),
+			songs:  songsToAdd(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repository := spotifyPlaylistRepository(test.sender)
+
+			err := repository.RemoveSongs(testContext(), addSongsPlaylistId, test.songs)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package spotify
+
+import "festwrap/internal/song"
+
+type spotifyPlaylistTrack struct {
+	Uri string `json:"uri"`
+}
+
+type spotifyPlaylistItem struct {
+	Track *spotifyPlaylistTrack `json:"track"`
+}
+
+type spotifyPlaylistTracksResponse struct {
+	Items []spotifyPlaylistItem `json:"items"`
+	Next  *string               `json:"next"`
+}
+
+func (r spotifyPlaylistTracksResponse) getSongs() []song.Song {
+	songs := []song.Song{}
+	for _, item := range r.Items {
+		// Tracks removed from the catalog are returned as null
+		if item.Track != nil {
+			songs = append(songs, song.NewSong(item.Track.Uri))
+		}
+	}
+	return songs
+}
@@ -15,3 +15,19 @@ func NewSpotifySongs(songs []song.Song) spotifySongs {
 	}
 	return spotifySongs{Uris: songUris}
 }
+
+type spotifyRemovedSong struct {
+	Uri string `json:"uri"`
+}
+
+type spotifyRemovedSongs struct {
+	Tracks []spotifyRemovedSong `json:"tracks"`
+}
+
+func newSpotifyRemovedSongs(songs []song.Song) spotifyRemovedSongs {
+	tracks := make([]spotifyRemovedSong, len(songs))
+	for i, currentSong := range songs {
+		tracks[i] = spotifyRemovedSong{Uri: currentSong.GetUri()}
+	}
+	return spotifyRemovedSongs{Tracks: tracks}
+}
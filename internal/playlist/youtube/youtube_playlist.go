@@ -0,0 +1,92 @@
+package youtube
+
+import (
+	"festwrap/internal/playlist"
+	"festwrap/internal/song"
+	songyoutube "festwrap/internal/song/youtube"
+)
+
+const (
+	publicPrivacyStatus  = "public"
+	privatePrivacyStatus = "private"
+)
+
+type youTubePlaylistSnippet struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ChannelId   string `json:"channelId,omitempty"`
+}
+
+type youTubePlaylistStatus struct {
+	PrivacyStatus string `json:"privacyStatus"`
+}
+
+type youTubePlaylist struct {
+	Id      string                 `json:"id,omitempty"`
+	Snippet youTubePlaylistSnippet `json:"snippet"`
+	Status  youTubePlaylistStatus  `json:"status"`
+}
+
+func newYouTubePlaylist(details playlist.PlaylistDetails) youTubePlaylist {
+	privacyStatus := privatePrivacyStatus
+	if details.IsPublic {
+		privacyStatus = publicPrivacyStatus
+	}
+	return youTubePlaylist{
+		Snippet: youTubePlaylistSnippet{Title: details.Name, Description: details.Description},
+		Status:  youTubePlaylistStatus{PrivacyStatus: privacyStatus},
+	}
+}
+
+func (p youTubePlaylist) toPlaylist() playlist.Playlist {
+	return playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{
+			Name:        p.Snippet.Title,
+			Description: p.Snippet.Description,
+			IsPublic:    p.Status.PrivacyStatus == publicPrivacyStatus,
+		},
+		Id:      p.Id,
+		OwnerId: p.Snippet.ChannelId,
+	}
+}
+
+type youTubePlaylistsResponse struct {
+	Items []youTubePlaylist `json:"items"`
+}
+
+type youTubeResourceId struct {
+	Kind    string `json:"kind"`
+	VideoId string `json:"videoId"`
+}
+
+type youTubePlaylistItemSnippet struct {
+	PlaylistId string            `json:"playlistId"`
+	ResourceId youTubeResourceId `json:"resourceId"`
+}
+
+type youTubePlaylistItem struct {
+	Snippet youTubePlaylistItemSnippet `json:"snippet"`
+}
+
+func newYouTubePlaylistItem(playlistId string, videoId string) youTubePlaylistItem {
+	return youTubePlaylistItem{
+		Snippet: youTubePlaylistItemSnippet{
+			PlaylistId: playlistId,
+			ResourceId: youTubeResourceId{Kind: "youtube#video", VideoId: videoId},
+		},
+	}
+}
+
+type youTubePlaylistItemsResponse struct {
+	Items         []youTubePlaylistItem `json:"items"`
+	NextPageToken string                `json:"nextPageToken"`
+}
+
+func (r youTubePlaylistItemsResponse) getSongs() []song.Song {
+	songs := make([]song.Song, len(r.Items))
+	for i, item := range r.Items {
+		videoId := item.Snippet.ResourceId.VideoId
+		songs[i] = song.Song{Id: videoId, Uri: songyoutube.SongUri(videoId)}
+	}
+	return songs
+}
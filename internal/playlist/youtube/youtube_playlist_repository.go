@@ -0,0 +1,256 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	types "festwrap/internal"
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/playlist"
+	"festwrap/internal/quota"
+	"festwrap/internal/serialization"
+	"festwrap/internal/song"
+)
+
+// Units charged by the YouTube Data API for each operation
+const (
+	listQuotaCost   = 1
+	insertQuotaCost = 50
+	deleteQuotaCost = 50
+)
+
+const getSongsPageSize = 50
+
+type YouTubePlaylistRepository struct {
+	tokenKey                  types.ContextKey
+	host                      string
+	httpSender                httpsender.HTTPRequestSender
+	quota                     *quota.UnitsTracker
+	playlistSerializer        serialization.Serializer[youTubePlaylist]
+	playlistDeserializer      serialization.Deserializer[youTubePlaylist]
+	playlistsDeserializer     serialization.Deserializer[youTubePlaylistsResponse]
+	itemSerializer            serialization.Serializer[youTubePlaylistItem]
+	playlistItemsDeserializer serialization.Deserializer[youTubePlaylistItemsResponse]
+}
+
+func NewYouTubePlaylistRepository(
+	httpSender httpsender.HTTPRequestSender,
+	quotaTracker *quota.UnitsTracker,
+) YouTubePlaylistRepository {
+	playlistSerializer := serialization.NewJsonSerializer[youTubePlaylist]()
+	itemSerializer := serialization.NewJsonSerializer[youTubePlaylistItem]()
+	return YouTubePlaylistRepository{
+		tokenKey:                  "youtube_token",
+		host:                      "www.googleapis.com",
+		httpSender:                httpSender,
+		quota:                     quotaTracker,
+		playlistSerializer:        &playlistSerializer,
+		playlistDeserializer:      serialization.NewJsonDeserializer[youTubePlaylist](),
+		playlistsDeserializer:     serialization.NewJsonDeserializer[youTubePlaylistsResponse](),
+		itemSerializer:            &itemSerializer,
+		playlistItemsDeserializer: serialization.NewJsonDeserializer[youTubePlaylistItemsResponse](),
+	}
+}
+
+func (r *YouTubePlaylistRepository) GetPlaylist(ctx context.Context, playlistId string) (playlist.Playlist, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return playlist.Playlist{}, errors.New("could not retrieve token from context when getting playlist")
+	}
+
+	if err := r.quota.Reserve(listQuotaCost); err != nil {
+		return playlist.Playlist{}, fmt.Errorf("could not get playlist %s: %w", playlistId, err)
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("part", "snippet,status")
+	queryParams.Set("id", playlistId)
+	response, err := r.httpSender.Send(r.httpOptions("playlists", queryParams, httpsender.GET, token))
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
+	}
+
+	var parsedResponse youTubePlaylistsResponse
+	err = r.playlistsDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return playlist.Playlist{}, errors.New(err.Error())
+	}
+
+	if len(parsedResponse.Items) == 0 {
+		return playlist.Playlist{}, fmt.Errorf("playlist %s not found", playlistId)
+	}
+
+	return parsedResponse.Items[0].toPlaylist(), nil
+}
+
+func (r *YouTubePlaylistRepository) GetSongs(ctx context.Context, playlistId string) ([]song.Song, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve token from context when getting playlist songs")
+	}
+
+	songs := []song.Song{}
+	pageToken := ""
+	for {
+		if err := r.quota.Reserve(listQuotaCost); err != nil {
+			return nil, fmt.Errorf("could not get songs from playlist %s: %w", playlistId, err)
+		}
+
+		queryParams := url.Values{}
+		queryParams.Set("part", "snippet")
+		queryParams.Set("playlistId", playlistId)
+		queryParams.Set("maxResults", fmt.Sprint(getSongsPageSize))
+		if pageToken != "" {
+			queryParams.Set("pageToken", pageToken)
+		}
+		response, err := r.httpSender.Send(r.httpOptions("playlistItems", queryParams, httpsender.GET, token))
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		var parsedResponse youTubePlaylistItemsResponse
+		err = r.playlistItemsDeserializer.Deserialize(*response, &parsedResponse)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		songs = append(songs, parsedResponse.getSongs()...)
+		pageToken = parsedResponse.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return songs, nil
+}
+
+func (r *YouTubePlaylistRepository) CreatePlaylist(
+	ctx context.Context,
+	details playlist.PlaylistDetails,
+) (string, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return "", errors.New("could not retrieve token from context when creating playlist")
+	}
+
+	if err := r.quota.Reserve(insertQuotaCost); err != nil {
+		return "", fmt.Errorf("could not create playlist: %w", err)
+	}
+
+	body, err := r.playlistSerializer.Serialize(newYouTubePlaylist(details))
+	if err != nil {
+		return "", fmt.Errorf("could not serialize playlist: %v", err.Error())
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("part", "snippet,status")
+	options := r.httpOptions("playlists", queryParams, httpsender.POST, token)
+	options.SetBody(body)
+	response, err := r.httpSender.Send(options)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	var parsedResponse youTubePlaylist
+	err = r.playlistDeserializer.Deserialize(*response, &parsedResponse)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	return parsedResponse.Id, nil
+}
+
+// YouTube only inserts one video per request, so each song is reported as a separate chunk when failing.
+// Playlists are not versioned, so no snapshot ids are returned
+func (r *YouTubePlaylistRepository) AddSongs(
+	ctx context.Context,
+	playlistId string,
+	songs []song.Song,
+) ([]string, error) {
+	if len(songs) == 0 {
+		return nil, errors.New("no songs provided")
+	}
+
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return nil, errors.New("could not retrieve token from context while adding songs")
+	}
+
+	addSongsErr := &playlist.AddSongsError{}
+	for i, currentSong := range songs {
+		// Once the quota is spent, none of the remaining songs can be added
+		if err := r.quota.Reserve(insertQuotaCost); err != nil {
+			addSongsErr.Chunks = append(addSongsErr.Chunks, playlist.ChunkError{Start: i, End: len(songs), Err: err})
+			break
+		}
+
+		if err := r.addSong(playlistId, currentSong, token); err != nil {
+			addSongsErr.Chunks = append(addSongsErr.Chunks, playlist.ChunkError{Start: i, End: i + 1, Err: err})
+		}
+	}
+
+	if len(addSongsErr.Chunks) > 0 {
+		return nil, addSongsErr
+	}
+	return nil, nil
+}
+
+func (r *YouTubePlaylistRepository) DeletePlaylist(ctx context.Context, playlistId string) error {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return errors.New("could not retrieve token from context when deleting playlist")
+	}
+
+	if err := r.quota.Reserve(deleteQuotaCost); err != nil {
+		return fmt.Errorf("could not delete playlist %s: %w", playlistId, err)
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("id", playlistId)
+	url := fmt.Sprintf("https://%s/youtube/v3/playlists?%s", r.host, queryParams.Encode())
+	options := httpsender.NewHTTPRequestOptions(url, httpsender.DELETE, 204)
+	options.SetHeaders(map[string]string{"Authorization": fmt.Sprintf("Bearer %s", token)})
+	if _, err := r.httpSender.Send(options); err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+func (r *YouTubePlaylistRepository) addSong(playlistId string, currentSong song.Song, token string) error {
+	body, err := r.itemSerializer.Serialize(newYouTubePlaylistItem(playlistId, currentSong.Id))
+	if err != nil {
+		return fmt.Errorf("could not serialize song: %v", err.Error())
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("part", "snippet")
+	options := r.httpOptions("playlistItems", queryParams, httpsender.POST, token)
+	options.SetBody(body)
+	_, err = r.httpSender.Send(options)
+	return err
+}
+
+func (r *YouTubePlaylistRepository) httpOptions(
+	resource string,
+	queryParams url.Values,
+	method httpsender.Method,
+	token string,
+) httpsender.HTTPRequestOptions {
+	url := fmt.Sprintf("https://%s/youtube/v3/%s?%s", r.host, resource, queryParams.Encode())
+	options := httpsender.NewHTTPRequestOptions(url, method, 200)
+	options.SetHeaders(map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", token),
+		"Content-Type":  "application/json",
+	})
+	return options
+}
+
+func (r *YouTubePlaylistRepository) SetTokenKey(key types.ContextKey) {
+	r.tokenKey = key
+}
+
+func (r *YouTubePlaylistRepository) SetHost(host string) {
+	r.host = host
+}
@@ -0,0 +1,226 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	types "festwrap/internal"
+	"festwrap/internal/playlist"
+	"festwrap/internal/quota"
+	"festwrap/internal/song"
+	"festwrap/internal/testtools/stubserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	token         = "some_token"
+	tokenKey      = types.ContextKey("token")
+	newPlaylistId = "PLnew"
+	existingId    = "PLexisting"
+	channelId     = "UCowner"
+)
+
+// Minimal stub of the YouTube Data API, storing the videos inserted in each playlist
+type youTubeStub struct {
+	mutex         sync.Mutex
+	createdBodies []string
+	insertedItems map[string][]string
+	deletedIds    []string
+}
+
+func (s *youTubeStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer some_token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/youtube/v3/playlists":
+		s.createdBodies = append(s.createdBodies, string(body))
+		fmt.Fprintf(w, `{"kind":"youtube#playlist","id":"%s"}`, newPlaylistId)
+	case r.Method == http.MethodPost && r.URL.Path == "/youtube/v3/playlistItems":
+		var item youTubePlaylistItem
+		json.Unmarshal(body, &item)
+		if item.Snippet.ResourceId.VideoId == "unavailable" {
+			http.Error(w, "video not found", http.StatusNotFound)
+			return
+		}
+		playlistId := item.Snippet.PlaylistId
+		s.insertedItems[playlistId] = append(s.insertedItems[playlistId], item.Snippet.ResourceId.VideoId)
+		fmt.Fprint(w, `{"kind":"youtube#playlistItem"}`)
+	case r.Method == http.MethodDelete && r.URL.Path == "/youtube/v3/playlists" && query.Get("id") == existingId:
+		s.deletedIds = append(s.deletedIds, existingId)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/youtube/v3/playlists" && query.Get("id") == existingId:
+		fmt.Fprintf(w, `{"items":[{"id":"%s","snippet":{"title":"Festival","description":"Warm up",`+
+			`"channelId":"%s"},"status":{"privacyStatus":"public"}}]}`, existingId, channelId)
+	case r.Method == http.MethodGet && r.URL.Path == "/youtube/v3/playlists":
+		fmt.Fprint(w, `{"items":[]}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/youtube/v3/playlistItems":
+		if query.Get("pageToken") == "" {
+			fmt.Fprint(w, `{"nextPageToken":"page2","items":[`+
+				`{"snippet":{"resourceId":{"kind":"youtube#video","videoId":"v1"}}},`+
+				`{"snippet":{"resourceId":{"kind":"youtube#video","videoId":"v2"}}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":[{"snippet":{"resourceId":{"kind":"youtube#video","videoId":"v3"}}}]}`)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func stubRepository(t *testing.T, tracker *quota.UnitsTracker) (YouTubePlaylistRepository, *youTubeStub) {
+	t.Helper()
+	stub := &youTubeStub{insertedItems: map[string][]string{}}
+	server := httptest.NewTLSServer(stub)
+	t.Cleanup(server.Close)
+	repository := NewYouTubePlaylistRepository(stubserver.NewSender(server), tracker)
+	repository.SetHost(strings.TrimPrefix(server.URL, "https://"))
+	repository.SetTokenKey(tokenKey)
+	return repository, stub
+}
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), tokenKey, token)
+}
+
+func videos(ids ...string) []song.Song {
+	songs := make([]song.Song, len(ids))
+	for i, id := range ids {
+		songs[i] = song.Song{Id: id, Uri: "youtube:video:" + id}
+	}
+	return songs
+}
+
+func TestCreatePlaylistCreatesPlaylistWithPrivacyStatus(t *testing.T) {
+	repository, stub := stubRepository(t, quota.NewUnitsTracker(10000))
+	details := playlist.PlaylistDetails{Name: "my-playlist", Description: "some playlist", IsPublic: false}
+
+	actual, err := repository.CreatePlaylist(testContext(), details)
+
+	assert.Nil(t, err)
+	assert.Equal(t, newPlaylistId, actual)
+	expectedBody := `{"snippet":{"title":"my-playlist","description":"some playlist"},"status":{"privacyStatus":"private"}}`
+	assert.Equal(t, []string{expectedBody}, stub.createdBodies)
+}
+
+func TestCreatePlaylistReturnsErrorWhenQuotaExceeded(t *testing.T) {
+	repository, stub := stubRepository(t, quota.NewUnitsTracker(49))
+
+	_, err := repository.CreatePlaylist(testContext(), playlist.PlaylistDetails{Name: "my-playlist"})
+
+	assert.True(t, errors.Is(err, quota.ErrQuotaExceeded))
+	assert.Empty(t, stub.createdBodies)
+}
+
+func TestAddSongsInsertsVideosInOrder(t *testing.T) {
+	tracker := quota.NewUnitsTracker(10000)
+	repository, stub := stubRepository(t, tracker)
+
+	snapshotIds, err := repository.AddSongs(testContext(), newPlaylistId, videos("v1", "v2", "v3"))
+
+	assert.Nil(t, err)
+	assert.Empty(t, snapshotIds)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, stub.insertedItems[newPlaylistId])
+	assert.Equal(t, 150, tracker.Used())
+}
+
+func TestAddSongsReportsFailedVideos(t *testing.T) {
+	repository, stub := stubRepository(t, quota.NewUnitsTracker(10000))
+
+	_, err := repository.AddSongs(testContext(), newPlaylistId, videos("v1", "unavailable", "v3"))
+
+	addSongsErr, ok := playlist.AsAddSongsError(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(addSongsErr.Chunks))
+	assert.True(t, addSongsErr.IsFailed(1))
+	assert.Equal(t, []string{"v1", "v3"}, stub.insertedItems[newPlaylistId])
+}
+
+func TestAddSongsStopsWhenQuotaExceeded(t *testing.T) {
+	repository, stub := stubRepository(t, quota.NewUnitsTracker(100))
+
+	_, err := repository.AddSongs(testContext(), newPlaylistId, videos("v1", "v2", "v3"))
+
+	addSongsErr, ok := playlist.AsAddSongsError(err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"v1", "v2"}, stub.insertedItems[newPlaylistId])
+	assert.True(t, errors.Is(err, quota.ErrQuotaExceeded))
+	assert.Equal(t, 2, addSongsErr.Chunks[0].Start)
+	assert.Equal(t, 3, addSongsErr.Chunks[0].End)
+}
+
+func TestGetPlaylistReturnsPlaylistOwnedByChannel(t *testing.T) {
+	repository, _ := stubRepository(t, quota.NewUnitsTracker(10000))
+
+	actual, err := repository.GetPlaylist(testContext(), existingId)
+
+	expected := playlist.Playlist{
+		PlaylistDetails: playlist.PlaylistDetails{Name: "Festival", Description: "Warm up", IsPublic: true},
+		Id:              existingId,
+		OwnerId:         channelId,
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestGetPlaylistReturnsErrorIfNotFound(t *testing.T) {
+	repository, _ := stubRepository(t, quota.NewUnitsTracker(10000))
+
+	_, err := repository.GetPlaylist(testContext(), "PLmissing")
+
+	assert.NotNil(t, err)
+}
+
+func TestGetSongsReturnsVideosFromAllPages(t *testing.T) {
+	tracker := quota.NewUnitsTracker(10000)
+	repository, _ := stubRepository(t, tracker)
+
+	actual, err := repository.GetSongs(testContext(), existingId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, videos("v1", "v2", "v3"), actual)
+	assert.Equal(t, 2, tracker.Used())
+}
+
+func TestDeletePlaylistDeletesPlaylist(t *testing.T) {
+	tracker := quota.NewUnitsTracker(10000)
+	repository, stub := stubRepository(t, tracker)
+
+	err := repository.DeletePlaylist(testContext(), existingId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{existingId}, stub.deletedIds)
+	assert.Equal(t, 50, tracker.Used())
+}
+
+func TestDeletePlaylistReturnsErrorIfNotFound(t *testing.T) {
+	repository, _ := stubRepository(t, quota.NewUnitsTracker(10000))
+
+	err := repository.DeletePlaylist(testContext(), "unknown")
+
+	assert.NotNil(t, err)
+}
+
+func TestDeletePlaylistReturnsErrorWhenQuotaExceeded(t *testing.T) {
+	repository, stub := stubRepository(t, quota.NewUnitsTracker(49))
+
+	err := repository.DeletePlaylist(testContext(), existingId)
+
+	assert.True(t, errors.Is(err, quota.ErrQuotaExceeded))
+	assert.Empty(t, stub.deletedIds)
+}
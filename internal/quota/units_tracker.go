@@ -0,0 +1,68 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Keeps track of the units spent against an API with a daily quota, such as the YouTube Data API.
+// Units are reserved before performing each request, so we stop sending requests once the quota is spent
+type UnitsTracker struct {
+	mutex       sync.Mutex
+	dailyLimit  int
+	used        int
+	periodStart time.Time
+	location    *time.Location
+}
+
+func NewUnitsTracker(dailyLimit int) *UnitsTracker {
+	tracker := &UnitsTracker{dailyLimit: dailyLimit, location: time.UTC}
+	tracker.periodStart = tracker.startOfDay(time.Now())
+	return tracker
+}
+
+func (t *UnitsTracker) Reserve(units int) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.resetIfNewDay()
+	if t.used+units > t.dailyLimit {
+		return fmt.Errorf("%w: %d units requested, %d left", ErrQuotaExceeded, units, t.dailyLimit-t.used)
+	}
+	t.used += units
+	return nil
+}
+
+func (t *UnitsTracker) Used() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.resetIfNewDay()
+	return t.used
+}
+
+// Sets the location whose midnight resets the quota
+func (t *UnitsTracker) SetLocation(location *time.Location) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.location = location
+	t.periodStart = t.startOfDay(time.Now())
+}
+
+func (t *UnitsTracker) resetIfNewDay() {
+	today := t.startOfDay(time.Now())
+	if today.After(t.periodStart) {
+		t.periodStart = today
+		t.used = 0
+	}
+}
+
+func (t *UnitsTracker) startOfDay(instant time.Time) time.Time {
+	local := instant.In(t.location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, t.location)
+}
@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveConsumesUnits(t *testing.T) {
+	tracker := NewUnitsTracker(100)
+
+	err := tracker.Reserve(60)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 60, tracker.Used())
+}
+
+func TestReserveReturnsErrorWhenQuotaExceeded(t *testing.T) {
+	tracker := NewUnitsTracker(100)
+	tracker.Reserve(60)
+
+	err := tracker.Reserve(50)
+
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, 60, tracker.Used())
+}
+
+func TestReserveResetsUnitsOnNewDay(t *testing.T) {
+	tracker := NewUnitsTracker(100)
+	tracker.Reserve(100)
+	tracker.periodStart = tracker.periodStart.Add(-24 * time.Hour)
+
+	err := tracker.Reserve(50)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 50, tracker.Used())
+}
+
+func TestReserveIsSafeForConcurrentUse(t *testing.T) {
+	tracker := NewUnitsTracker(1000)
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Reserve(20)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1000, tracker.Used())
+}
@@ -0,0 +1,60 @@
+package applemusic
+
+import (
+	"festwrap/internal/song"
+	"fmt"
+)
+
+type appleMusicPreview struct {
+	Url string `json:"url"`
+}
+
+type appleMusicSongAttributes struct {
+	Name             string              `json:"name"`
+	ArtistName       string              `json:"artistName"`
+	AlbumName        string              `json:"albumName"`
+	DurationInMillis int                 `json:"durationInMillis"`
+	Isrc             string              `json:"isrc"`
+	ContentRating    string              `json:"contentRating"`
+	Previews         []appleMusicPreview `json:"previews"`
+}
+
+type appleMusicSong struct {
+	Id         string                   `json:"id"`
+	Attributes appleMusicSongAttributes `json:"attributes"`
+}
+
+func (s appleMusicSong) toSong() song.Song {
+	previewUrl := ""
+	if len(s.Attributes.Previews) > 0 {
+		previewUrl = s.Attributes.Previews[0].Url
+	}
+	return song.Song{
+		Id:         s.Id,
+		Uri:        SongUri(s.Id),
+		Name:       s.Attributes.Name,
+		Artists:    []string{s.Attributes.ArtistName},
+		Album:      s.Attributes.AlbumName,
+		DurationMs: s.Attributes.DurationInMillis,
+		Isrc:       s.Attributes.Isrc,
+		Explicit:   s.Attributes.ContentRating == "explicit",
+		PreviewUrl: previewUrl,
+	}
+}
+
+type appleMusicSongs struct {
+	Data []appleMusicSong `json:"data"`
+}
+
+type appleMusicSearchResults struct {
+	Songs appleMusicSongs `json:"songs"`
+}
+
+type appleMusicResponse struct {
+	Results appleMusicSearchResults `json:"results"`
+}
+
+// Apple Music has no song URIs, so we build them from the catalog identifier of the song
+func SongUri(catalogId string) string {
+	return fmt.Sprintf("apple_music:song:%s", catalogId)
+}
@@ -0,0 +1,85 @@
+package applemusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	types "festwrap/internal"
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/serialization"
+	"festwrap/internal/song"
+)
+
+type AppleMusicSongRepository struct {
+	developerTokenKey types.ContextKey
+	host              string
+	storefront        string
+	httpSender        httpsender.HTTPRequestSender
+	deserializer      serialization.Deserializer[appleMusicResponse]
+}
+
+func NewAppleMusicSongRepository(httpSender httpsender.HTTPRequestSender) *AppleMusicSongRepository {
+	return &AppleMusicSongRepository{
+		developerTokenKey: "apple_music_token",
+		host:              "api.music.apple.com",
+		storefront:        "us",
+		httpSender:        httpSender,
+		deserializer:      serialization.NewJsonDeserializer[appleMusicResponse](),
+	}
+}
+
+func (r *AppleMusicSongRepository) GetSong(ctx context.Context, artist string, title string) (song.Song, error) {
+	token, ok := ctx.Value(r.developerTokenKey).(string)
+	if !ok {
+		return song.Song{}, errors.New("could not retrieve developer token from context when retrieving song")
+	}
+
+	responseBody, err := r.httpSender.Send(r.createSongHttpOptions(artist, title, token))
+	if err != nil {
+		return song.Song{}, errors.New(err.Error())
+	}
+
+	var response appleMusicResponse
+	err = r.deserializer.Deserialize(*responseBody, &response)
+	if err != nil {
+		return song.Song{}, errors.New(err.Error())
+	}
+
+	if len(response.Results.Songs.Data) == 0 {
+		return song.Song{}, fmt.Errorf("no songs found for song %s (%s): %w", title, artist, song.ErrSongNotFound)
+	}
+
+	// We assume the first result is the most trusted one
+	return response.Results.Songs.Data[0].toSong(), nil
+}
+
+func (r *AppleMusicSongRepository) createSongHttpOptions(
+	artist string,
+	title string,
+	token string,
+) httpsender.HTTPRequestOptions {
+	queryParams := url.Values{}
+	queryParams.Set("term", fmt.Sprintf("%s %s", artist, title))
+	queryParams.Set("types", "songs")
+	queryParams.Set("limit", "1")
+	url := fmt.Sprintf("https://%s/v1/catalog/%s/search?%s", r.host, r.storefront, queryParams.Encode())
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	httpOptions.SetHeaders(
+		map[string]string{"Authorization": fmt.Sprintf("Bearer %s", token)},
+	)
+	return httpOptions
+}
+
+func (r *AppleMusicSongRepository) SetDeveloperTokenKey(key types.ContextKey) {
+	r.developerTokenKey = key
+}
+
+func (r *AppleMusicSongRepository) SetHost(host string) {
+	r.host = host
+}
+
+func (r *AppleMusicSongRepository) SetStorefront(storefront string) {
+	r.storefront = storefront
+}
@@ -0,0 +1,126 @@
+package applemusic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	types "festwrap/internal"
+	"festwrap/internal/song"
+	"festwrap/internal/testtools"
+	"festwrap/internal/testtools/stubserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	developerToken    = "some_token"
+	developerTokenKey = types.ContextKey("developer_token")
+	artist            = "Movements"
+	songTitle         = "Daylily"
+	storefront        = "es"
+)
+
+func searchSongResponseBody(t *testing.T) []byte {
+	return testtools.LoadTestDataOrError(
+		t,
+		filepath.Join(testtools.GetParentDir(t), "testdata", "apple_music_search_song_response.json"),
+	)
+}
+
+func noSongsSearchSongResponseBody(t *testing.T) []byte {
+	return testtools.LoadTestDataOrError(
+		t,
+		filepath.Join(testtools.GetParentDir(t), "testdata", "apple_music_no_songs_search_song_response.json"),
+	)
+}
+
+// Stub of the Apple Music catalog search, only answering requests with the expected parameters
+func searchStubServer(t *testing.T, responseBody []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method != http.MethodGet ||
+			r.URL.Path != "/v1/catalog/es/search" ||
+			query.Get("term") != "Movements Daylily" ||
+			query.Get("types") != "songs" ||
+			query.Get("limit") != "1" ||
+			r.Header.Get("Authorization") != "Bearer some_token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write(responseBody)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func stubRepository(server *httptest.Server) *AppleMusicSongRepository {
+	repository := NewAppleMusicSongRepository(stubserver.NewSender(server))
+	repository.SetHost(strings.TrimPrefix(server.URL, "https://"))
+	repository.SetStorefront(storefront)
+	repository.SetDeveloperTokenKey(developerTokenKey)
+	return repository
+}
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), developerTokenKey, developerToken)
+}
+
+func expectedSong() song.Song {
+	return song.Song{
+		Id:         "1253496339",
+		Uri:        "apple_music:song:1253496339",
+		Name:       "Daylily",
+		Artists:    []string{"Movements"},
+		Album:      "Feel Something",
+		DurationMs: 219213,
+		Isrc:       "USEP41719003",
+		Explicit:   true,
+		PreviewUrl: "https://audio-ssl.itunes.apple.com/itunes-assets/AudioPreview115/daylily.m4a",
+	}
+}
+
+func TestGetSongReturnsFirstSearchResult(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)))
+
+	actual, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedSong(), actual)
+}
+
+func TestGetSongReturnsErrorWhenNoSongsFound(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, noSongsSearchSongResponseBody(t)))
+
+	_, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.ErrorIs(t, err, song.ErrSongNotFound)
+}
+
+func TestGetSongReturnsErrorWithoutDeveloperToken(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)))
+
+	_, err := repository.GetSong(context.Background(), artist, songTitle)
+
+	assert.NotNil(t, err)
+}
+
+func TestGetSongReturnsErrorOnApiError(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)))
+
+	_, err := repository.GetSong(testContext(), "Another artist", songTitle)
+
+	assert.NotNil(t, err)
+}
+
+func TestGetSongReturnsErrorOnInvalidResponse(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, []byte("{some_invalid_json}")))
+
+	_, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.NotNil(t, err)
+}
@@ -1,13 +1,21 @@
 package song
 
 type Song struct {
-	uri string
+	Id         string   `json:"id,omitempty"`
+	Uri        string   `json:"uri"`
+	Name       string   `json:"name,omitempty"`
+	Artists    []string `json:"artists,omitempty"`
+	Album      string   `json:"album,omitempty"`
+	DurationMs int      `json:"durationMs,omitempty"`
+	Isrc       string   `json:"isrc,omitempty"`
+	Explicit   bool     `json:"explicit"`
+	PreviewUrl string   `json:"previewUrl,omitempty"`
 }
 
 func NewSong(uri string) Song {
-	return Song{uri: uri}
+	return Song{Uri: uri}
 }
 
 func (s *Song) GetUri() string {
-	return s.uri
+	return s.Uri
 }
@@ -1,6 +1,12 @@
 package song
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// Returned when the search succeeds but no song matches, unlike transient errors such as rate limits
+var ErrSongNotFound = errors.New("song not found")
 
 type SongRepository interface {
 	GetSong(ctx context.Context, artist string, title string) (Song, error)
@@ -1,7 +1,47 @@
 package spotify
 
+import "festwrap/internal/song"
+
+type spotifyArtist struct {
+	Name string `json:"name"`
+}
+
+type spotifyAlbum struct {
+	Name string `json:"name"`
+}
+
+type spotifyExternalIds struct {
+	Isrc string `json:"isrc"`
+}
+
 type spotifySong struct {
-	Uri string `json:"uri"`
+	Id          string             `json:"id"`
+	Uri         string             `json:"uri"`
+	Name        string             `json:"name"`
+	Artists     []spotifyArtist    `json:"artists"`
+	Album       spotifyAlbum       `json:"album"`
+	DurationMs  int                `json:"duration_ms"`
+	ExternalIds spotifyExternalIds `json:"external_ids"`
+	Explicit    bool               `json:"explicit"`
+	PreviewUrl  string             `json:"preview_url"`
+}
+
+func (s spotifySong) toSong() song.Song {
+	artists := make([]string, len(s.Artists))
+	for i, artist := range s.Artists {
+		artists[i] = artist.Name
+	}
+	return song.Song{
+		Id:         s.Id,
+		Uri:        s.Uri,
+		Name:       s.Name,
+		Artists:    artists,
+		Album:      s.Album.Name,
+		DurationMs: s.DurationMs,
+		Isrc:       s.ExternalIds.Isrc,
+		Explicit:   s.Explicit,
+		PreviewUrl: s.PreviewUrl,
+	}
 }
 
 type spotifyTracks struct {
@@ -47,12 +47,11 @@ func (r *SpotifySongRepository) GetSong(ctx context.Context, artist string, titl
 	}
 
 	if len(response.Tracks.Songs) == 0 {
-		return song.Song{}, fmt.Errorf("no songs found for song %s (%s)", title, artist)
+		return song.Song{}, fmt.Errorf("no songs found for song %s (%s): %w", title, artist, song.ErrSongNotFound)
 	}
 
 	// We assume the first result is the most trusted one
-	result := song.NewSong(response.Tracks.Songs[0].Uri)
-	return result, nil
+	return response.Tracks.Songs[0].toSong(), nil
 }
 
 func (r *SpotifySongRepository) SetDeserializer(deserializer serialization.Deserializer[spotifyResponse]) {
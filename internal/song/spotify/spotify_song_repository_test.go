@@ -95,7 +95,7 @@ func TestGetSongReturnsErrorIfNoSongsFound(t *testing.T) {
 
 	_, err := repository.GetSong(testContext(), artist, songTitle)
 
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, song.ErrSongNotFound)
 }
 
 func TestGetSongReturnsFirstSongFound(t *testing.T) {
@@ -103,7 +103,17 @@ func TestGetSongReturnsFirstSongFound(t *testing.T) {
 
 	actual, err := repository.GetSong(testContext(), artist, songTitle)
 
-	expected := song.NewSong("spotify:track:4rH1kFLYW0b28UNRyn7dK3")
+	expected := song.Song{
+		Id:         "4rH1kFLYW0b28UNRyn7dK3",
+		Uri:        "spotify:track:4rH1kFLYW0b28UNRyn7dK3",
+		Name:       "Goodbye",
+		Artists:    []string{"toe"},
+		Album:      "独演会 \"DOKU-EN-KAI\"",
+		DurationMs: 553125,
+		Isrc:       "USEZ62123304",
+		Explicit:   false,
+		PreviewUrl: "https://p.scdn.co/mp3-preview/e83f5f429e20558f87f46f62d786baf9f126ce42?cid=fb809489e467472ebf58f1e5f3b29cbd",
+	}
 	assert.Equal(t, expected, actual)
 	assert.Nil(t, err)
 }
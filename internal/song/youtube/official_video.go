@@ -0,0 +1,39 @@
+package youtube
+
+import (
+	"html"
+	"strings"
+)
+
+// Channels automatically generated for artists, holding their official audio tracks
+const topicSuffix = " - Topic"
+
+// Picks the search result most likely to be the official video of the song: first one uploaded by the
+// artist channels, then one titled as official and finally the first result
+func findOfficialVideo(artist string, items []youTubeSearchItem) youTubeSearchItem {
+	for _, item := range items {
+		if isArtistChannel(artist, html.UnescapeString(item.Snippet.ChannelTitle)) {
+			return item
+		}
+	}
+
+	for _, item := range items {
+		if strings.Contains(strings.ToLower(item.Snippet.Title), "official") {
+			return item
+		}
+	}
+
+	return items[0]
+}
+
+func isArtistChannel(artist string, channel string) bool {
+	normalizedArtist := normalizeChannel(artist)
+	normalizedChannel := normalizeChannel(channel)
+	return normalizedChannel == normalizedArtist ||
+		normalizedChannel == normalizeChannel(artist+topicSuffix) ||
+		normalizedChannel == normalizedArtist+"vevo"
+}
+
+func normalizeChannel(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "")
+}
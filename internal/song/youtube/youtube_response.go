@@ -0,0 +1,41 @@
+package youtube
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"festwrap/internal/song"
+)
+
+type youTubeVideoId struct {
+	VideoId string `json:"videoId"`
+}
+
+type youTubeSnippet struct {
+	Title        string `json:"title"`
+	ChannelTitle string `json:"channelTitle"`
+}
+
+type youTubeSearchItem struct {
+	Id      youTubeVideoId `json:"id"`
+	Snippet youTubeSnippet `json:"snippet"`
+}
+
+func (i youTubeSearchItem) toSong() song.Song {
+	return song.Song{
+		Id:      i.Id.VideoId,
+		Uri:     SongUri(i.Id.VideoId),
+		Name:    html.UnescapeString(i.Snippet.Title),
+		Artists: []string{strings.TrimSuffix(html.UnescapeString(i.Snippet.ChannelTitle), topicSuffix)},
+	}
+}
+
+type youTubeSearchResponse struct {
+	Items []youTubeSearchItem `json:"items"`
+}
+
+// YouTube has no song URIs, so we build them from the identifier of the video
+func SongUri(videoId string) string {
+	return fmt.Sprintf("youtube:video:%s", videoId)
+}
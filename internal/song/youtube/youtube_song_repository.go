@@ -0,0 +1,99 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	types "festwrap/internal"
+	httpsender "festwrap/internal/http/sender"
+	"festwrap/internal/quota"
+	"festwrap/internal/serialization"
+	"festwrap/internal/song"
+)
+
+const (
+	// Units charged by the YouTube Data API for each search
+	searchQuotaCost = 100
+	// Music category of YouTube videos
+	musicCategoryId = "10"
+)
+
+type YouTubeSongRepository struct {
+	tokenKey     types.ContextKey
+	host         string
+	maxResults   int
+	httpSender   httpsender.HTTPRequestSender
+	quota        *quota.UnitsTracker
+	deserializer serialization.Deserializer[youTubeSearchResponse]
+}
+
+func NewYouTubeSongRepository(
+	httpSender httpsender.HTTPRequestSender,
+	quotaTracker *quota.UnitsTracker,
+) *YouTubeSongRepository {
+	return &YouTubeSongRepository{
+		tokenKey:     "youtube_token",
+		host:         "www.googleapis.com",
+		maxResults:   5,
+		httpSender:   httpSender,
+		quota:        quotaTracker,
+		deserializer: serialization.NewJsonDeserializer[youTubeSearchResponse](),
+	}
+}
+
+func (r *YouTubeSongRepository) GetSong(ctx context.Context, artist string, title string) (song.Song, error) {
+	token, ok := ctx.Value(r.tokenKey).(string)
+	if !ok {
+		return song.Song{}, errors.New("could not retrieve token from context when retrieving song")
+	}
+
+	if err := r.quota.Reserve(searchQuotaCost); err != nil {
+		return song.Song{}, fmt.Errorf("could not search song %s (%s): %w", title, artist, err)
+	}
+
+	responseBody, err := r.httpSender.Send(r.createSongHttpOptions(artist, title, token))
+	if err != nil {
+		return song.Song{}, errors.New(err.Error())
+	}
+
+	var response youTubeSearchResponse
+	err = r.deserializer.Deserialize(*responseBody, &response)
+	if err != nil {
+		return song.Song{}, errors.New(err.Error())
+	}
+
+	if len(response.Items) == 0 {
+		return song.Song{}, fmt.Errorf("no videos found for song %s (%s): %w", title, artist, song.ErrSongNotFound)
+	}
+
+	return findOfficialVideo(artist, response.Items).toSong(), nil
+}
+
+func (r *YouTubeSongRepository) createSongHttpOptions(
+	artist string,
+	title string,
+	token string,
+) httpsender.HTTPRequestOptions {
+	queryParams := url.Values{}
+	queryParams.Set("part", "snippet")
+	queryParams.Set("type", "video")
+	queryParams.Set("videoCategoryId", musicCategoryId)
+	queryParams.Set("maxResults", fmt.Sprint(r.maxResults))
+	queryParams.Set("q", fmt.Sprintf("%s %s", artist, title))
+	url := fmt.Sprintf("https://%s/youtube/v3/search?%s", r.host, queryParams.Encode())
+	httpOptions := httpsender.NewHTTPRequestOptions(url, httpsender.GET, 200)
+	httpOptions.SetHeaders(
+		map[string]string{"Authorization": fmt.Sprintf("Bearer %s", token)},
+	)
+	return httpOptions
+}
+
+func (r *YouTubeSongRepository) SetTokenKey(key types.ContextKey) {
+	r.tokenKey = key
+}
+
+func (r *YouTubeSongRepository) SetHost(host string) {
+	r.host = host
+}
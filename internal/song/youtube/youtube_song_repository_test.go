@@ -0,0 +1,118 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	types "festwrap/internal"
+	"festwrap/internal/quota"
+	"festwrap/internal/song"
+	"festwrap/internal/testtools"
+	"festwrap/internal/testtools/stubserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	token     = "some_token"
+	tokenKey  = types.ContextKey("token")
+	artist    = "Movements"
+	songTitle = "Daylily"
+)
+
+func searchSongResponseBody(t *testing.T) []byte {
+	return testtools.LoadTestDataOrError(
+		t,
+		filepath.Join(testtools.GetParentDir(t), "testdata", "youtube_search_song_response.json"),
+	)
+}
+
+// Stub of the YouTube search, only answering requests with the expected parameters
+func searchStubServer(t *testing.T, responseBody []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/youtube/v3/search" ||
+			query.Get("q") != "Movements Daylily" ||
+			query.Get("type") != "video" ||
+			query.Get("videoCategoryId") != "10" ||
+			r.Header.Get("Authorization") != "Bearer some_token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write(responseBody)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func stubRepository(server *httptest.Server, tracker *quota.UnitsTracker) *YouTubeSongRepository {
+	repository := NewYouTubeSongRepository(stubserver.NewSender(server), tracker)
+	repository.SetHost(strings.TrimPrefix(server.URL, "https://"))
+	repository.SetTokenKey(tokenKey)
+	return repository
+}
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), tokenKey, token)
+}
+
+func TestGetSongReturnsVideoFromArtistChannel(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)), quota.NewUnitsTracker(10000))
+
+	actual, err := repository.GetSong(testContext(), artist, songTitle)
+
+	expected := song.Song{Id: "topic01", Uri: "youtube:video:topic01", Name: "Daylily", Artists: []string{"Movements"}}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestGetSongReturnsOfficialVideoIfNoArtistChannel(t *testing.T) {
+	responseBody := searchSongResponseBody(t)
+	responseBody = []byte(strings.ReplaceAll(string(responseBody), "Movements - Topic", "Another channel"))
+	repository := stubRepository(searchStubServer(t, responseBody), quota.NewUnitsTracker(10000))
+
+	actual, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fearless01", actual.Id)
+	assert.Equal(t, `Movements "Daylily" (Official Music Video)`, actual.Name)
+}
+
+func TestGetSongReturnsErrorWhenNoVideosFound(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, []byte(`{"items":[]}`)), quota.NewUnitsTracker(10000))
+
+	_, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.ErrorIs(t, err, song.ErrSongNotFound)
+}
+
+func TestGetSongConsumesSearchQuota(t *testing.T) {
+	tracker := quota.NewUnitsTracker(10000)
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)), tracker)
+
+	repository.GetSong(testContext(), artist, songTitle)
+
+	assert.Equal(t, 100, tracker.Used())
+}
+
+func TestGetSongReturnsErrorWhenQuotaExceeded(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)), quota.NewUnitsTracker(99))
+
+	_, err := repository.GetSong(testContext(), artist, songTitle)
+
+	assert.True(t, errors.Is(err, quota.ErrQuotaExceeded))
+}
+
+func TestGetSongReturnsErrorWithoutToken(t *testing.T) {
+	repository := stubRepository(searchStubServer(t, searchSongResponseBody(t)), quota.NewUnitsTracker(10000))
+
+	_, err := repository.GetSong(context.Background(), artist, songTitle)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,26 @@
+package str
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	bracketsRegex = regexp.MustCompile(`[(\[][^)\]]*[)\]]`)
+	suffixRegex   = regexp.MustCompile(`\s+-\s+.*$`)
+)
+
+// Normalizes a song title so that versions like "Song (Live)" or "Song - Remastered 2011" match "song"
+func NormalizeTitle(title string) string {
+	title = bracketsRegex.ReplaceAllString(title, "")
+	title = suffixRegex.ReplaceAllString(title, "")
+
+	var builder strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
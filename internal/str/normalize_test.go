@@ -0,0 +1,44 @@
+package str
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]struct {
+		title    string
+		expected string
+	}{
+		"lowercases title": {
+			title:    "Silver And Cold",
+			expected: "silverandcold",
+		},
+		"removes punctuation": {
+			title:    "Don't Stop Believin'",
+			expected: "dontstopbelievin",
+		},
+		"removes bracketed content": {
+			title:    "Crisis (Live) [2023 Remaster]",
+			expected: "crisis",
+		},
+		"removes dash suffix": {
+			title:    "Accidents - Remastered 2011",
+			expected: "accidents",
+		},
+		"keeps non ascii letters": {
+			title:    "Ñandú",
+			expected: "ñandú",
+		},
+		"empty title": {
+			title:    "",
+			expected: "",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeTitle(test.title))
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package stubserver
+
+import (
+	"net/http/httptest"
+
+	httpclient "festwrap/internal/http/client"
+	httpsender "festwrap/internal/http/sender"
+)
+
+// Sends requests to a local stub server, so repositories can be tested against a fake API. Kept apart from
+// testtools because the sender tests use it
+func NewSender(server *httptest.Server) *httpsender.BaseHTTPRequestSender {
+	client := httpclient.NewBaseHTTPClient(server.Client())
+	sender := httpsender.NewBaseHTTPRequestSender(&client)
+	return &sender
+}